@@ -0,0 +1,42 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package armscan
+
+import (
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/checkov"
+	"github.com/spf13/cobra"
+)
+
+func Command() *cobra.Command {
+	c := tools.CreateCommand(&checkov.Tool{
+		Framework: "arm,bicep",
+	})
+	c.Use = "arm-scan"
+	c.Aliases = []string{"bicep-scan"}
+	c.Short = "Scan Azure Resource Manager and Bicep templates"
+	c.Long = `Scan Azure Resource Manager (ARM) JSON templates and Bicep files.
+
+Bicep files are scanned directly, so findings refer to lines in the
+.bicep source rather than to compiled ARM JSON.
+
+Use the sub-commands to explicitly choose a scanner to use.`
+	c.AddCommand(
+		tools.CreateCommand(&checkov.Tool{
+			Framework: "arm,bicep",
+		}),
+	)
+	return c
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 
 	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/api"
 	"github.com/soluble-ai/soluble-cli/pkg/config"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/options"
@@ -33,6 +34,7 @@ func Command() *cobra.Command {
 	c.AddCommand(printTokenCmd())
 	c.AddCommand(setAccessTokenCmd())
 	c.AddCommand(setOrgCommand())
+	c.AddCommand(checkCommand())
 	return c
 }
 
@@ -141,3 +143,38 @@ func setOrgCommand() *cobra.Command {
 	_ = c.MarkFlagRequired("organization")
 	return c
 }
+
+func checkCommand() *cobra.Command {
+	opts := options.PrintClientOpts{}
+	c := &cobra.Command{
+		Use:   "check",
+		Short: "Check that the CLI can connect and authenticate to Soluble",
+		Long: `Check that the CLI can connect and authenticate to Soluble, using the same
+configuration that uploading results uses.  Exits with a non-zero exit code on failure.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := opts.GetAPIClientConfig()
+			result := jnode.NewObjectNode().
+				Put("apiServer", cfg.APIServer).
+				Put("organization", opts.GetOrganization()).
+				Put("authenticated", false)
+			if cfg.APIToken == "" {
+				opts.PrintResult(result)
+				return fmt.Errorf("not authenticated, use login to authenticate")
+			}
+			profile, err := opts.GetAPIClient().Get("/api/v1/users/profile")
+			if err != nil {
+				result.Put("error", api.DescribeError(err))
+				opts.PrintResult(result)
+				log.Errorf("Could not connect to {primary:%s} - {danger:%s}", cfg.APIServer, api.DescribeError(err))
+				return err
+			}
+			result.Put("authenticated", true).
+				Put("email", profile.Path("email").AsText())
+			opts.PrintResult(result)
+			return nil
+		},
+	}
+	opts.Register(c)
+	return c
+}
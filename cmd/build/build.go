@@ -16,11 +16,14 @@ package build
 
 import (
 	"fmt"
+	"os"
+	"os/exec"
 	"strings"
 
 	"github.com/soluble-ai/go-jnode"
 	"github.com/soluble-ai/soluble-cli/pkg/assessments"
 	"github.com/soluble-ai/soluble-cli/pkg/exit"
+	"github.com/soluble-ai/soluble-cli/pkg/inventory"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/options"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
@@ -30,25 +33,93 @@ import (
 type BuildOpts struct {
 	options.PrintClientOpts
 	FailThresholds []string
+	Environment    string
 
 	parsedFailThresholds map[string]int
 }
 
+// CI variables that hold the branch being built, in order of preference.
+// CI systems usually check out a detached HEAD so git can't tell us.
+var branchEnv = []string{
+	"GITHUB_HEAD_REF", "GITHUB_REF_NAME", "CI_MERGE_REQUEST_SOURCE_BRANCH_NAME",
+	"CI_COMMIT_REF_NAME", "CIRCLE_BRANCH", "BUILDKITE_BRANCH",
+}
+
 func (opts *BuildOpts) Register(c *cobra.Command) {
 	opts.PrintClientOpts.Register(c)
 	flags := c.Flags()
 	flags.StringSliceVar(&opts.FailThresholds, "fail", nil, "")
+	flags.StringVar(&opts.Environment, "environment", "", "Use the fail thresholds of this `environment` from the repository config.  By default the environment is selected by matching the branch against each environment's branches.")
 }
 
 func (opts *BuildOpts) validate() error {
-	parsedFailThresholds, err := assessments.ParseFailThresholds(opts.FailThresholds)
+	thresholds := opts.FailThresholds
+	if len(thresholds) == 0 {
+		root, _ := inventory.FindRepoRoot(".")
+		var err error
+		thresholds, err = opts.getEnvironmentFailThresholds(tools.ReadRepoConfigFile(root), func() string {
+			return getBranch(os.Getenv, getGitBranch)
+		})
+		if err != nil {
+			return err
+		}
+	} else if opts.Environment != "" {
+		log.Warnf("Ignoring the thresholds of environment {warning:%s} because {info:--fail} was given", opts.Environment)
+	}
+	parsedFailThresholds, err := assessments.ParseFailThresholds(thresholds)
 	if err != nil {
 		return err
 	}
 	opts.parsedFailThresholds = parsedFailThresholds
+	if len(thresholds) > 0 {
+		log.Infof("Failing on {info:%s} findings", strings.Join(thresholds, ","))
+	}
 	return nil
 }
 
+func (opts *BuildOpts) getEnvironmentFailThresholds(config *tools.Config, getBranch func() string) ([]string, error) {
+	environment := opts.Environment
+	if environment == "" {
+		if !config.HasEnvironments() {
+			return nil, nil
+		}
+		branch := getBranch()
+		environment = config.FindEnvironment(branch)
+		if environment == "" {
+			return nil, nil
+		}
+		log.Infof("Using environment {info:%s} for branch {info:%s}", environment, branch)
+	} else if !config.HasEnvironment(environment) {
+		return nil, fmt.Errorf("the environment %s is not defined in the repository config", environment)
+	}
+	thresholds := config.GetEnvironmentFailThresholds(environment)
+	if thresholds == nil {
+		log.Warnf("Environment {warning:%s} has no fail thresholds", environment)
+	}
+	return thresholds, nil
+}
+
+func getBranch(getenv func(string) string, getGitBranch func() string) string {
+	for _, k := range branchEnv {
+		if v := getenv(k); v != "" {
+			return v
+		}
+	}
+	if branch := getGitBranch(); branch != "HEAD" {
+		return branch
+	}
+	return ""
+}
+
+func getGitBranch() string {
+	out, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		log.Debugf("Could not determine the git branch: %s", err)
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
 func (opts *BuildOpts) getAssessments() (assessments.Assessments, error) {
 	as, err := assessments.FindCIEnvAssessments(opts.GetAPIClient())
 	if err != nil {
@@ -92,7 +163,14 @@ order.
 # Fail if 1 or more high or critical severity findings in this build:
 soluble build report --fail high=1
 # Or shorter:
-soluble build report --fail high`,
+soluble build report --fail high
+
+# Use the thresholds of the prod environment in .lacework/config.yml:
+#   environments:
+#     prod:
+#       branches: [main]
+#       fail: [high]
+soluble build report --environment prod`,
 		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := opts.validate(); err != nil {
@@ -105,7 +183,7 @@ soluble build report --fail high`,
 			findings := jnode.NewArrayNode()
 			for _, assessment := range assessments {
 				if assessment.Failed {
-					exit.Code = 2
+					exit.Code = exit.FailureCode
 					a := assessment
 					exit.AddFunc(func() {
 						log.Errorf("{warning:%s} has {danger:%d %s findings}",
@@ -0,0 +1,69 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetEnvironmentFailThresholds(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "config.yml")
+	assert.NoError(os.WriteFile(path, []byte(`environments:
+  prod:
+    branches: [main]
+    fail: [high]
+  dev:
+    fail: [critical]
+`), 0600))
+	config, err := tools.LoadConfigFile(path)
+	assert.NoError(err)
+	branch := func(env map[string]string, gitBranch string) func() string {
+		return func() string {
+			return getBranch(func(k string) string { return env[k] }, func() string { return gitBranch })
+		}
+	}
+	opts := &BuildOpts{}
+	thresholds := func(config *tools.Config, getBranch func() string) []string {
+		thresholds, err := opts.getEnvironmentFailThresholds(config, getBranch)
+		assert.NoError(err)
+		return thresholds
+	}
+	assert.Equal([]string{"high"}, thresholds(config, branch(map[string]string{
+		"GITHUB_REF_NAME": "main",
+	}, "HEAD")))
+	assert.Equal([]string{"high"}, thresholds(config, branch(nil, "main")))
+	assert.Nil(thresholds(config, branch(nil, "HEAD")))
+	opts.Environment = "dev"
+	assert.Equal([]string{"critical"}, thresholds(config, func() string {
+		assert.Fail("the branch isn't needed with an explicit environment")
+		return ""
+	}))
+	opts.Environment = "qa"
+	_, err = opts.getEnvironmentFailThresholds(config, branch(nil, "main"))
+	assert.Error(err)
+	_, err = opts.getEnvironmentFailThresholds(tools.ReadRepoConfigFile(t.TempDir()), branch(nil, "main"))
+	assert.Error(err)
+	opts.Environment = ""
+	assert.Nil(thresholds(tools.ReadRepoConfigFile(t.TempDir()), func() string {
+		assert.Fail("the branch isn't needed without environments")
+		return ""
+	}))
+}
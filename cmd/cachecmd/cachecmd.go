@@ -0,0 +1,68 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cachecmd
+
+import (
+	"fmt"
+
+	"github.com/soluble-ai/soluble-cli/pkg/download"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/spf13/cobra"
+)
+
+func Command() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage locally cached tools and policies",
+	}
+	c.AddCommand(clearCommand())
+	return c
+}
+
+func clearCommand() *cobra.Command {
+	var tool string
+	c := &cobra.Command{
+		Use:   "clear",
+		Short: "Remove downloaded tools and policies",
+		Long: `Remove downloaded tools and policies.
+
+They will be downloaded again the next time they're needed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m := download.NewManager()
+			size, err := m.Clear(tool)
+			if err != nil {
+				return err
+			}
+			log.Infof("Freed {primary:%s}", formatSize(size))
+			return nil
+		},
+	}
+	c.Flags().StringVar(&tool, "tool", "", "Only remove the downloads of this `tool`")
+	return c
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
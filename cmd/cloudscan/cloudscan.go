@@ -15,6 +15,7 @@
 package cloudscan
 
 import (
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/cloudsploit"
 	"github.com/spf13/cobra"
 )
@@ -26,6 +27,11 @@ func Command() *cobra.Command {
 		Args:   cobra.NoArgs,
 		Hidden: true,
 	}
-	c.AddCommand(cloudsploit.Command())
+	cs := &cloudsploit.Tool{}
+	c.AddCommand(tools.CreateCommand(cs))
+	// findings are cloud resources, not files
+	cs.Columns = []string{
+		"severity", "title", "filePath", "tool.region", "tool.category",
+	}
 	return c
 }
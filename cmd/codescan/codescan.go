@@ -18,8 +18,10 @@ import (
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/bandit"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/brakeman"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/compose"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/gosec"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/hadolint"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/iampolicy"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/semgrep"
 	"github.com/spf13/cobra"
 )
@@ -36,6 +38,8 @@ func Command() *cobra.Command {
 		tools.CreateCommand(&brakeman.Tool{}),
 		tools.CreateCommand(&gosec.Tool{}),
 		tools.CreateCommand(&hadolint.Tool{}),
+		tools.CreateCommand(&compose.Tool{}),
+		tools.CreateCommand(&iampolicy.Tool{}),
 	)
 	return c
 }
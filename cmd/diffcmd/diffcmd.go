@@ -0,0 +1,124 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diffcmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/options"
+	"github.com/soluble-ai/soluble-cli/pkg/print"
+	"github.com/spf13/cobra"
+)
+
+func Command() *cobra.Command {
+	opts := options.PrintClientOpts{
+		PrintOpts: options.PrintOpts{
+			Path:                []string{"added"},
+			DefaultOutputFormat: "table",
+			Columns: []string{
+				"severity", "title", "file", "line",
+			},
+			WideColumns: []string{
+				"sid", "partialFingerprint",
+			},
+		},
+	}
+	var baseBranch, tool string
+	opts.SetFormatter("title", print.TruncateFormatter(70, false))
+	opts.SetColumnFunction("file", func(n *jnode.Node) interface{} {
+		if repoPath := n.Path("repoPath").AsText(); repoPath != "" {
+			return repoPath
+		}
+		return n.Path("filePath").AsText()
+	})
+	c := &cobra.Command{
+		Use:   "diff old-findings.json new-findings.json",
+		Short: "Compare the findings of two scans",
+		Long: `Compare the failed findings of two scans and display the findings that were added.
+
+The files are the output of a scan with --format json, e.g. soluble terraform-scan --format json > findings.json.
+Findings are matched by rule, file, and the partial fingerprint of their line, so findings
+that only moved are unchanged.  Use --format json to display the added, removed, and
+unchanged findings.
+
+With --base-branch only the new findings file is given, and the old findings are those of
+the last --tool scan of the base branch of the current git repository that was uploaded.`,
+		Example: "soluble diff --base-branch main --tool checkov findings.json",
+		Args:    cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				older assessments.Findings
+				err   error
+			)
+			if baseBranch != "" {
+				if len(args) != 1 || tool == "" {
+					return fmt.Errorf("--base-branch requires --tool and only the new findings file")
+				}
+				older, err = assessments.FindLatestFindings(opts.GetAPIClient(), ".", tool, baseBranch)
+			} else {
+				if len(args) != 2 {
+					return fmt.Errorf("the old and new findings files are required")
+				}
+				older, err = assessments.ReadFindingsFile(args[0])
+			}
+			if err != nil {
+				return err
+			}
+			newer, err := assessments.ReadFindingsFile(args[len(args)-1])
+			if err != nil {
+				return err
+			}
+			diff := assessments.DiffFindings(older, newer)
+			log.Infof("Findings {danger:added=%d} {info:removed=%d} unchanged=%d",
+				len(diff.Added), len(diff.Removed), len(diff.Unchanged))
+			n, err := toNode(diff)
+			if err != nil {
+				return err
+			}
+			opts.PrintResult(n)
+			return nil
+		},
+	}
+	opts.Register(c)
+	flags := c.Flags()
+	flags.StringVar(&baseBranch, "base-branch", "", "Compare against the last uploaded scan of `branch` instead of an old findings file")
+	flags.StringVar(&tool, "tool", "", "The `name` of the tool e.g. checkov whose scan of --base-branch to compare against")
+	return c
+}
+
+func toNode(diff *assessments.FindingsDiff) (*jnode.Node, error) {
+	for _, fs := range []assessments.Findings{diff.Added, diff.Removed, diff.Unchanged} {
+		for _, f := range fs {
+			f.Severity = f.GetSeverity()
+		}
+	}
+	d, err := json.Marshal(diff)
+	if err != nil {
+		return nil, err
+	}
+	n, err := jnode.FromJSON(d)
+	if err != nil {
+		return nil, err
+	}
+	n.PutObject("summary").
+		Put("added", len(diff.Added)).
+		Put("removed", len(diff.Removed)).
+		Put("unchanged", len(diff.Unchanged))
+	return n, nil
+}
@@ -32,7 +32,7 @@ func listCommand() *cobra.Command {
 			"Name", "Version", "Dir", "LatestCheckTs+",
 		},
 		WideColumns: []string{
-			"URL", "InstallTime",
+			"URL", "InstallTime", "ETag",
 		},
 	}
 	c := &cobra.Command{
@@ -0,0 +1,67 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package explaincmd
+
+import (
+	"fmt"
+
+	"github.com/soluble-ai/soluble-cli/pkg/options"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/terrascan"
+	"github.com/spf13/cobra"
+)
+
+const explainTemplate = `{{ .rule_id }}{{ with .title }} - {{ . }}{{ end }}
+{{ with .severity }}Severity: {{ . }}
+{{ end }}{{ with .category }}Category: {{ . }}
+{{ end }}{{ with .resource_type }}Resource: {{ . }}
+{{ end }}{{ with .rationale }}
+Rationale:
+  {{ . }}
+{{ end }}{{ with .remediation }}
+Remediation:
+  {{ . }}
+{{ end }}`
+
+func Command() *cobra.Command {
+	opts := &options.PrintOpts{}
+	c := &cobra.Command{
+		Use:   "explain tool rule-id",
+		Short: "Describe a rule that a tool reported a finding for",
+		Long: `Describe a rule, including its rationale and how to remediate it.
+
+The rules are looked up in the locally installed terrascan policies,
+including the organization's custom policies.  Only terrascan rules are
+supported; the findings of other tools link to their documentation in
+tool.help_url.`,
+		Example: "soluble explain terrascan AWS.S3Bucket.DS.High.1043",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tool, ruleID := args[0], args[1]
+			if tool != "terrascan" {
+				return fmt.Errorf("explain only supports terrascan rules, not %s", tool)
+			}
+			rule := terrascan.FindRule(ruleID)
+			if rule == nil {
+				return fmt.Errorf("could not find the terrascan rule %s, run terrascan first to install its policies", ruleID)
+			}
+			opts.PrintResult(rule)
+			return nil
+		},
+	}
+	opts.Register(c)
+	// registering the flags resets the template
+	opts.Template = explainTemplate
+	return c
+}
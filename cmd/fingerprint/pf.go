@@ -19,7 +19,7 @@ func Command() *cobra.Command {
 		Short:  "Display partial fingerprints and diffs",
 		Hidden: true,
 	}
-	c.AddCommand(showCommand(), diffCommand())
+	c.AddCommand(showCommand(), diffCommand(), refreshCommand())
 	return c
 }
 
@@ -0,0 +1,85 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fingerprint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/spf13/cobra"
+)
+
+func refreshCommand() *cobra.Command {
+	var (
+		dir    string
+		output string
+	)
+	c := &cobra.Command{
+		Use:   "refresh findings.json",
+		Short: "Recompute fingerprints for the findings from a prior run",
+		Long: `Recompute fingerprints for the findings from a prior run.
+
+The findings are read from a findings.json file and the files they refer
+to are fingerprinted in --directory, which should be the directory the
+original scan was run in.  The updated fingerprints are written
+to --output.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := refreshFingerprints(args[0], dir)
+			if err != nil {
+				return err
+			}
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			if err := tools.WriteFingerprints(f, tools.Results{result}); err != nil {
+				_ = f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			log.Infof("Wrote {primary:%d} fingerprints to {info:%s}", len(result.FileFingerprints), output)
+			return nil
+		},
+	}
+	flags := c.Flags()
+	flags.StringVarP(&dir, "directory", "d", "", "The `directory` the findings are relative to")
+	flags.StringVarP(&output, "output", "o", "fingerprints.json", "Write the fingerprints to `file`")
+	_ = c.MarkFlagRequired("directory")
+	return c
+}
+
+func refreshFingerprints(findingsPath, dir string) (*tools.Result, error) {
+	d, err := os.ReadFile(findingsPath)
+	if err != nil {
+		return nil, err
+	}
+	var findings assessments.Findings
+	if err := json.Unmarshal(d, &findings); err != nil {
+		return nil, fmt.Errorf("could not read findings from %s: %w", findingsPath, err)
+	}
+	result := &tools.Result{
+		Directory: dir,
+		Findings:  findings,
+	}
+	result.UpdateFileFingerprints()
+	return result, nil
+}
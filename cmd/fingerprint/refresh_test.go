@@ -0,0 +1,43 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fingerprint
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshFingerprints(t *testing.T) {
+	assert := assert.New(t)
+	result, err := refreshFingerprints("testdata/findings.json", "testdata")
+	if !assert.NoError(err) {
+		return
+	}
+	if assert.Len(result.FileFingerprints, 2) {
+		assert.Equal(1, result.FileFingerprints[0].Line)
+		assert.Equal(3, result.FileFingerprints[1].Line)
+		assert.NotEmpty(result.FileFingerprints[1].PartialFingerprint)
+		assert.NotEqual("stale", result.FileFingerprints[1].PartialFingerprint)
+	}
+	buf := &bytes.Buffer{}
+	assert.NoError(tools.WriteFingerprints(buf, tools.Results{result}))
+	n, err := jnode.FromJSON(buf.Bytes())
+	assert.NoError(err)
+	assert.Equal("main.tf", n.Get(0).Path("filePath").AsText())
+}
@@ -0,0 +1,4 @@
+resource "aws_s3_bucket" "b" {
+  bucket = "my-bucket"
+  acl    = "public-read"
+}
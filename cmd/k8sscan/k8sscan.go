@@ -17,18 +17,17 @@ package k8sscan
 import (
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/checkov"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/polaris"
 	"github.com/spf13/cobra"
 )
 
 func Command() *cobra.Command {
-	c := tools.CreateCommand(&checkov.Tool{
-		Framework: "kubernetes",
-	})
-	c.Use = "kubernetes-scan"
-	c.Short = "Scan kubernetes manifests"
-	c.Aliases = []string{"k8s-scan"}
-	c.AddCommand(tools.CreateCommand(&checkov.Tool{
-		Framework: "kubernetes",
-	}))
+	c := tools.CreateCommand(&checkov.Kubernetes{})
+	c.AddCommand(
+		tools.CreateCommand(&checkov.Tool{
+			Framework: "kubernetes",
+		}),
+		tools.CreateCommand(&polaris.Tool{}),
+	)
 	return c
 }
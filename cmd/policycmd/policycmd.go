@@ -0,0 +1,93 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policycmd
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/options"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/spf13/cobra"
+)
+
+func Command() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "policy",
+		Short: "Manage the organization's custom policies",
+	}
+	c.AddCommand(listCommand())
+	return c
+}
+
+func listCommand() *cobra.Command {
+	var tool string
+	opts := options.PrintClientOpts{
+		PrintOpts: options.PrintOpts{
+			Path:    []string{"data"},
+			Columns: []string{"file", "size"},
+		},
+	}
+	c := &cobra.Command{
+		Use:   "list",
+		Short: "Fetch and list the custom policy bundle of a tool",
+		Long: `Fetch and list the contents of the organization's custom policy bundle for a tool.
+
+The bundle is cached locally, and is only downloaded again when the
+version (ETag) on the server changes.`,
+		Example: "soluble policy list --tool terrascan",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := tools.InstallCustomPolicies(opts.GetAPIClient(), tool)
+			if err != nil {
+				return err
+			}
+			version := d.ETag
+			if version == "" {
+				version = "(unknown)"
+			}
+			log.Infof("The {primary:%s} policy bundle is version {info:%s} fetched at {info:%s}",
+				tool, version, d.InstallTime.Format(time.RFC3339))
+			n := jnode.NewObjectNode()
+			n.Put("version", d.ETag)
+			data := n.PutArray("data")
+			err = filepath.Walk(d.Dir, func(path string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				if !info.IsDir() {
+					rel, _ := filepath.Rel(d.Dir, path)
+					data.AppendObject().Put("file", filepath.ToSlash(rel)).Put("size", info.Size())
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if data.Size() == 0 {
+				log.Infof("{primary:%s} has no custom policies", tool)
+			}
+			opts.PrintResult(n)
+			return nil
+		},
+	}
+	opts.Register(c)
+	c.Flags().StringVar(&tool, "tool", "", "The `tool` whose policies to list, e.g. terrascan or checkov")
+	_ = c.MarkFlagRequired("tool")
+	return c
+}
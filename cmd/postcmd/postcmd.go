@@ -36,6 +36,9 @@ func Command() *cobra.Command {
 		Short: "Send data to soluble",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.ValidateOrganization(); err != nil {
+				return err
+			}
 			result, err := opts.GetAPIClient().XCPPost(opts.GetOrganization(), module, files, values,
 				xcp.WithCIEnv(""))
 			if err != nil {
@@ -18,16 +18,20 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/soluble-ai/soluble-cli/cmd/armscan"
 	"github.com/soluble-ai/soluble-cli/cmd/auth"
 	"github.com/soluble-ai/soluble-cli/cmd/aws"
 	"github.com/soluble-ai/soluble-cli/cmd/build"
+	"github.com/soluble-ai/soluble-cli/cmd/cachecmd"
 	"github.com/soluble-ai/soluble-cli/cmd/cdkscan"
 	"github.com/soluble-ai/soluble-cli/cmd/cfnscan"
 	"github.com/soluble-ai/soluble-cli/cmd/cloudscan"
 	"github.com/soluble-ai/soluble-cli/cmd/codescan"
 	configcmd "github.com/soluble-ai/soluble-cli/cmd/config"
 	"github.com/soluble-ai/soluble-cli/cmd/depscan"
+	"github.com/soluble-ai/soluble-cli/cmd/diffcmd"
 	"github.com/soluble-ai/soluble-cli/cmd/downloadcmd"
+	"github.com/soluble-ai/soluble-cli/cmd/explaincmd"
 	"github.com/soluble-ai/soluble-cli/cmd/fingerprint"
 	"github.com/soluble-ai/soluble-cli/cmd/helmscan"
 	"github.com/soluble-ai/soluble-cli/cmd/imagescan"
@@ -35,11 +39,13 @@ import (
 	"github.com/soluble-ai/soluble-cli/cmd/k8sscan"
 	"github.com/soluble-ai/soluble-cli/cmd/logincmd"
 	modelcmd "github.com/soluble-ai/soluble-cli/cmd/model"
+	"github.com/soluble-ai/soluble-cli/cmd/policycmd"
 	"github.com/soluble-ai/soluble-cli/cmd/postcmd"
 	"github.com/soluble-ai/soluble-cli/cmd/query"
 	"github.com/soluble-ai/soluble-cli/cmd/secretsscan"
 	"github.com/soluble-ai/soluble-cli/cmd/tfplan"
 	"github.com/soluble-ai/soluble-cli/cmd/tfscan"
+	"github.com/soluble-ai/soluble-cli/cmd/toolscmd"
 	"github.com/soluble-ai/soluble-cli/cmd/version"
 	"github.com/soluble-ai/soluble-cli/pkg/blurb"
 	"github.com/soluble-ai/soluble-cli/pkg/config"
@@ -50,7 +56,11 @@ import (
 	"github.com/soluble-ai/soluble-cli/pkg/tools/autoscan"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/checkov"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/cloudmap"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/sbom"
+	"github.com/soluble-ai/soluble-cli/pkg/tracing"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
 	v "github.com/soluble-ai/soluble-cli/pkg/version"
+	"github.com/soluble-ai/soluble-cli/pkg/xcp"
 	"github.com/spf13/cobra"
 )
 
@@ -67,8 +77,15 @@ func Command() *cobra.Command {
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			log.Configure()
+			if err := log.Configure(); err != nil {
+				return err
+			}
 			log.Debugf("Loaded configuration from {primary:%s}", config.ConfigFile)
+			if config.CABundle != "" {
+				if _, err := util.LoadCertPool(config.CABundle); err != nil {
+					return fmt.Errorf("invalid --ca-bundle: %w", err)
+				}
+			}
 			if setProfile != "" {
 				config.SelectProfile(setProfile)
 				if err := config.Save(); err != nil {
@@ -78,9 +95,12 @@ func Command() *cobra.Command {
 			if profile != "" {
 				config.SelectProfile(profile)
 			}
+			tracing.Start(cmd.CommandPath())
 			return nil
 		},
 		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			tracing.Shutdown()
+			util.RemoveTempFiles()
 			if config.Config.GetAPIToken() == "" {
 				if cmd.Use != "version" {
 					blurb.SignupBlurb(nil, "Finding {primary:soluble} useful?", "")
@@ -99,6 +119,10 @@ func Command() *cobra.Command {
 	flags := rootCmd.PersistentFlags()
 	flags.StringVar(&profile, "profile", "", "Use this configuration profile (see 'config list-profiles')")
 	flags.StringVar(&setProfile, "set-profile", "", "Set the current profile to this (and save it.)")
+	flags.StringVar(&xcp.DefaultBranch, "default-branch", "", "The default `branch` of the repository to compare against.  By default it's found with git.")
+	flags.StringVar(&util.TempDir, "temp-dir", "", "Create temporary files (e.g. git clones) in a unique subdirectory of `dir`.  Defaults to $TMPDIR.")
+	flags.StringVar(&config.CABundle, "ca-bundle", "", "Trust the PEM encoded CA certificates in `file` for uploads and downloads.  Can also be set with SOLUBLE_CA_BUNDLE.")
+	flags.BoolVar(&config.InsecureSkipTLSVerify, "insecure-skip-tls-verify", false, "Disable TLS verification of uploads and downloads.  This is insecure and is only for testing against self-signed servers.")
 	log.AddFlags(flags)
 	flags.BoolVar(&blurb.Blurbed, "no-blurb", false, "Don't blurb about Soluble")
 
@@ -124,6 +148,9 @@ func addBuiltinCommands(rootCmd *cobra.Command) {
 		version.Command(),
 		query.Command(),
 		downloadcmd.Command(),
+		cachecmd.Command(),
+		policycmd.Command(),
+		explaincmd.Command(),
 		postcmd.Command(),
 		imagescan.Command(),
 		inventorycmd.Command(),
@@ -135,14 +162,18 @@ func addBuiltinCommands(rootCmd *cobra.Command) {
 		tfscan.Command(),
 		secretsscan.Command(),
 		cfnscan.Command(),
+		armscan.Command(),
 		tools.CreateCommand(&autoscan.Tool{}),
 		checkovCommand,
 		codescan.Command(),
 		cloudscan.Command(),
 		tools.CreateCommand(&cloudmap.Tool{}),
+		tools.CreateCommand(&sbom.Tool{}),
 		tfplan.Command(),
 		cdkscan.Command(),
 		fingerprint.Command(),
+		toolscmd.Command(),
+		diffcmd.Command(),
 	)
 }
 
@@ -0,0 +1,36 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/exit"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDockerUnavailableExitCode(t *testing.T) {
+	assert := assert.New(t)
+	// with an empty PATH the docker executable can't be found
+	t.Setenv("PATH", t.TempDir())
+	tool := NewTool(t, "code-scan", "hadolint", "-d", t.TempDir())
+	err := tool.Run()
+	assert.True(tools.IsDockerError(err))
+	assert.Equal(exit.DockerErrorCode, exit.CodeOf(err))
+	tool = NewTool(t, "code-scan", "hadolint", "-d", t.TempDir(), "--check")
+	err = tool.Run()
+	assert.Equal(exit.DockerErrorCode, exit.CodeOf(err))
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/soluble-ai/soluble-cli/pkg/tools/terrascan"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/tfscore"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/tfsec"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/tfversions"
 	"github.com/spf13/cobra"
 )
 
@@ -42,6 +43,7 @@ Scans terraform code with checkov.  Use a sub-command to explicitly choose a sca
 		tools.CreateCommand(&checkov.Tool{
 			Framework: "terraform",
 		}),
+		tools.CreateCommand(&tfversions.Tool{}),
 		scan,
 	)
 	return c
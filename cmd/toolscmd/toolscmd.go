@@ -0,0 +1,76 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package toolscmd
+
+import (
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/options"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/spf13/cobra"
+)
+
+func Command() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "tools",
+		Short: "Information about the tools the CLI can run",
+	}
+	c.AddCommand(listCommand())
+	return c
+}
+
+func listCommand() *cobra.Command {
+	opts := options.PrintOpts{
+		Path:                []string{"data"},
+		DefaultOutputFormat: "json",
+		Columns: []string{
+			"name", "command", "non_assessment", "requires",
+		},
+		WideColumns: []string{
+			"flags",
+		},
+	}
+	c := &cobra.Command{
+		Use:   "list",
+		Short: "List the available tools",
+		Long:  `List the available tools, including the command that runs each tool, its flags, and whether it requires docker, a download, or an installed program.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.PrintResult(listTools(tools.GetRegisteredTools()))
+			return nil
+		},
+	}
+	opts.Register(c)
+	return c
+}
+
+func listTools(registered []*tools.RegisteredTool) *jnode.Node {
+	n := jnode.NewObjectNode()
+	a := n.PutArray("data")
+	for _, rt := range registered {
+		e := a.AppendObject().
+			Put("name", rt.Tool.Name()).
+			Put("command", rt.Command.CommandPath()).
+			Put("non_assessment", rt.Tool.IsNonAssessment())
+		requires := e.PutArray("requires")
+		for _, r := range rt.Tool.Requirements() {
+			requires.Append(r)
+		}
+		flags := e.PutArray("flags")
+		for _, f := range rt.GetFlags() {
+			flags.Append(f)
+		}
+	}
+	return n
+}
@@ -0,0 +1,40 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package toolscmd
+
+import (
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/hadolint"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/polaris"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListTools(t *testing.T) {
+	assert := assert.New(t)
+	tools.CreateCommand(&hadolint.Tool{})
+	tools.CreateCommand(&polaris.Tool{})
+	n := listTools(tools.GetRegisteredTools())
+	assert.Equal(2, n.Path("data").Size())
+	h := n.Path("data").Get(0)
+	assert.Equal("hadolint", h.Path("name").AsText())
+	assert.Equal("docker", h.Path("requires").Get(0).AsText())
+	assert.False(h.Path("non_assessment").AsBool())
+	p := n.Path("data").Get(1)
+	assert.Equal("polaris", p.Path("name").AsText())
+	assert.Equal("download", p.Path("requires").Get(0).AsText())
+	assert.Contains(p.Path("flags").Unwrap(), "directory")
+}
@@ -21,6 +21,9 @@ import (
 	"github.com/soluble-ai/go-colorize"
 	"github.com/soluble-ai/soluble-cli/cmd/root"
 	_ "github.com/soluble-ai/soluble-cli/pkg/assessments/github"
+	"github.com/soluble-ai/soluble-cli/pkg/exit"
+	"github.com/soluble-ai/soluble-cli/pkg/tracing"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
 )
 
 func main() {
@@ -32,7 +35,9 @@ func main() {
 	}
 	cmd := root.Command()
 	if err := cmd.Execute(); err != nil {
+		tracing.Shutdown()
+		util.RemoveTempFiles()
 		colorize.Colorize("{danger:Error:} {warning:%s}\n", strings.TrimRight(err.Error(), "\n"))
-		os.Exit(1)
+		os.Exit(exit.CodeOf(err))
 	}
 }
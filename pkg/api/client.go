@@ -16,15 +16,18 @@ package api
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/soluble-ai/go-jnode"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/soluble-ai/soluble-cli/pkg/version"
 )
 
@@ -39,6 +42,7 @@ type Config struct {
 	APIPrefix        string
 	Debug            bool
 	TLSNoVerify      bool
+	CABundle         string
 	Timeout          time.Duration
 	RetryCount       int
 	RetryWaitSeconds float64
@@ -69,6 +73,18 @@ func (h httpError) Is(err error) bool {
 	}
 }
 
+// Returns the HTTP status code of a request that failed because the
+// server returned an error, or 0 if err isn't an HTTP error.
+func GetStatusCode(err error) int {
+	var h httpError
+	if !errors.As(err, &h) {
+		return 0
+	}
+	s := string(h)
+	code, _ := strconv.Atoi(s[strings.LastIndex(s, " ")+1:])
+	return code
+}
+
 func NewClient(config *Config) *Client {
 	c := &Client{
 		Client: resty.New(),
@@ -84,13 +100,28 @@ func NewClient(config *Config) *Client {
 	if log.Level == log.Debug {
 		c.Client.Debug = true
 	}
-	if config.TLSNoVerify {
-		log.Warnf("{warning:Disabling TLS verification of %s}", apiServer)
-		c.SetTLSClientConfig(&tls.Config{
-			InsecureSkipVerify: true,
-		})
+	if config.TLSNoVerify || config.CABundle != "" {
+		tlsConfig := &tls.Config{}
+		if config.TLSNoVerify {
+			log.Warnf("{danger:TLS verification of %s is disabled, this is insecure and only for testing}", apiServer)
+			tlsConfig.InsecureSkipVerify = true
+		}
+		if config.CABundle != "" {
+			pool, err := util.LoadCertPool(config.CABundle)
+			if err != nil {
+				log.Errorf("Cannot load the CA bundle {warning:%s} - {danger:%s}", config.CABundle, err)
+			} else {
+				tlsConfig.RootCAs = pool
+			}
+		}
+		c.SetTLSClientConfig(tlsConfig)
 	}
 	c.SetHeader("User-Agent", "soluble-cli/"+version.Version)
+	// don't leak tokens into debug logs
+	c.OnRequestLog(func(rl *resty.RequestLog) error {
+		rl.Header = redactHeaders(rl.Header)
+		return nil
+	})
 	c.EnableTrace()
 	c.OnAfterResponse(func(c *resty.Client, r *resty.Response) error {
 		info := r.Request.TraceInfo()
@@ -179,6 +210,14 @@ func (c *Client) Delete(path string, options ...Option) (*jnode.Node, error) {
 	return result, nil
 }
 
+func (c *Client) Put(path string, body interface{}, options ...Option) (*jnode.Node, error) {
+	result := jnode.NewObjectNode()
+	if err := c.execute(c.R().SetResult(result).SetBody(body), resty.MethodPut, path, options); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (c *Client) Patch(path string, body *jnode.Node, options ...Option) (*jnode.Node, error) {
 	result := jnode.NewObjectNode()
 	if err := c.execute(c.R().SetResult(result).SetBody(body), resty.MethodPatch, path, options); err != nil {
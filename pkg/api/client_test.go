@@ -15,10 +15,18 @@
 package api
 
 import (
+	"bytes"
+	"encoding/pem"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/fatih/color"
 	"github.com/jarcoal/httpmock"
 	"github.com/soluble-ai/go-jnode"
 )
@@ -60,4 +68,85 @@ func TestError(t *testing.T) {
 	if !errors.Is(e, HTTPError) {
 		t.Error(e)
 	}
+	if code := GetStatusCode(fmt.Errorf("x: %w", httpError("https://example.com/x returned 404"))); code != 404 {
+		t.Error(code)
+	}
+	if code := GetStatusCode(errors.New("returned 404")); code != 0 {
+		t.Error(code)
+	}
+}
+
+func TestCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"hello":"world"}`))
+	}))
+	defer server.Close()
+	c := NewClient(&Config{APIServer: server.URL})
+	if _, err := c.Get("/hello"); err == nil {
+		t.Error("the server certificate should not be trusted")
+	}
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	dat := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundle, dat, 0600); err != nil {
+		t.Fatal(err)
+	}
+	c = NewClient(&Config{APIServer: server.URL, CABundle: bundle})
+	n, err := c.Get("/hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Path("hello").AsText() != "world" {
+		t.Error(n)
+	}
+}
+
+func TestTLSNoVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"hello":"world"}`))
+	}))
+	defer server.Close()
+	w := &bytes.Buffer{}
+	output := color.Output
+	color.Output = w
+	defer func() { color.Output = output }()
+	c := NewClient(&Config{APIServer: server.URL, TLSNoVerify: true})
+	if _, err := c.Get("/hello"); err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(w.String(), "TLS verification of "+server.URL+" is disabled") {
+		t.Error(w.String())
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	c := NewClient(&Config{
+		APIServer: "https://api.soluble.cloud",
+		Headers:   []string{"X-Api-Gateway-Token:abc=123", "X-Route:blue"},
+	})
+	httpmock.ActivateNonDefault(c.Client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", "https://api.soluble.cloud/api/v1/xcp/test/data",
+		func(r *http.Request) (*http.Response, error) {
+			if h := r.Header.Get("X-Api-Gateway-Token"); h != "abc=123" {
+				t.Error(h)
+			}
+			if h := r.Header.Get("X-Route"); h != "blue" {
+				t.Error(h)
+			}
+			return httpmock.NewJsonResponse(http.StatusOK, jnode.NewObjectNode())
+		})
+	if _, err := c.XCPPost("1234", "test", nil, nil); err != nil {
+		t.Error(err)
+	}
+	redacted := redactHeaders(http.Header{
+		"Authorization":       {"Bearer xxx"},
+		"X-Api-Gateway-Token": {"abc=123"},
+		"X-Route":             {"blue"},
+	})
+	if redacted.Get("Authorization") == "Bearer xxx" || redacted.Get("X-Api-Gateway-Token") == "abc=123" ||
+		redacted.Get("X-Route") != "blue" {
+		t.Error(redacted)
+	}
 }
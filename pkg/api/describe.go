@@ -0,0 +1,69 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Return a short explanation of why a request failed, distinguishing
+// DNS, proxy, TLS, timeout, and HTTP errors.
+func DescribeError(err error) string {
+	if err == nil {
+		return ""
+	}
+	var (
+		dnsErr      *net.DNSError
+		opErr       *net.OpError
+		unknownCA   x509.UnknownAuthorityError
+		hostnameErr x509.HostnameError
+		certErr     x509.CertificateInvalidError
+		urlErr      *url.Error
+		httpErr     httpError
+	)
+	switch {
+	case errors.As(err, &httpErr):
+		return "the server returned an error"
+	case errors.As(err, &opErr) && opErr.Op == "proxyconnect":
+		return "could not connect to the proxy " + getProxyEnv()
+	case errors.As(err, &dnsErr):
+		return "could not resolve " + dnsErr.Name
+	case errors.As(err, &unknownCA):
+		return "the server's TLS certificate is signed by an unknown authority"
+	case errors.As(err, &hostnameErr):
+		return "the server's TLS certificate doesn't match the host name"
+	case errors.As(err, &certErr):
+		return "the server's TLS certificate is invalid"
+	case errors.As(err, &urlErr) && urlErr.Timeout():
+		return "the request timed out"
+	case errors.As(err, &opErr):
+		return "could not connect to the server"
+	}
+	return err.Error()
+}
+
+func getProxyEnv() string {
+	for _, k := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"} {
+		if v := os.Getenv(k); v != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribeError(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("", DescribeError(nil))
+	dns := &url.Error{Op: "Get", URL: "https://api.example.com", Err: &net.OpError{
+		Op: "dial", Err: &net.DNSError{Name: "api.example.com", Err: "no such host"},
+	}}
+	assert.Equal("could not resolve api.example.com", DescribeError(dns))
+	proxy := &url.Error{Op: "Get", URL: "https://api.example.com", Err: &net.OpError{
+		Op: "proxyconnect", Err: fmt.Errorf("connection refused"),
+	}}
+	assert.Contains(DescribeError(proxy), "could not connect to the proxy")
+	tls := &url.Error{Op: "Get", URL: "https://api.example.com", Err: x509.UnknownAuthorityError{}}
+	assert.Contains(DescribeError(tls), "unknown authority")
+	assert.Equal("the server returned an error", DescribeError(fmt.Errorf("x: %w", httpError("401"))))
+}
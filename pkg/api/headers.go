@@ -0,0 +1,50 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Header names that contain any of these have their values redacted
+// from debug logs, including the headers set with --api-header
+var sensitiveHeaderSubstrings = []string{
+	"AUTH", "TOKEN", "SECRET", "KEY", "PASSWORD", "COOKIE", "CREDENTIAL",
+}
+
+func isSensitiveHeader(name string) bool {
+	name = strings.ToUpper(name)
+	for _, s := range sensitiveHeaderSubstrings {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns a copy of the headers with the values of sensitive headers
+// replaced.
+func redactHeaders(header http.Header) http.Header {
+	redacted := http.Header{}
+	for k, vs := range header {
+		if isSensitiveHeader(k) {
+			redacted[k] = []string{"*****"}
+		} else {
+			redacted[k] = vs
+		}
+	}
+	return redacted
+}
@@ -15,16 +15,15 @@
 package assessments
 
 import (
-	"bufio"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/soluble-ai/soluble-cli/pkg/api"
-	"github.com/soluble-ai/soluble-cli/pkg/assessments/fingerprint"
 	"github.com/soluble-ai/soluble-cli/pkg/inventory"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
@@ -62,6 +61,11 @@ type Finding struct {
 	RepoPath           string            `json:"repoPath,omitempty"`
 	PartialFingerprint string            `json:"partialFingerprint,omitempty"`
 	Tool               map[string]string `json:"tool,omitempty"`
+	// Labels from the rule_labels config, kept apart from Tool so they
+	// can't clash with the tool's own keys
+	Labels map[string]string `json:"labels,omitempty"`
+	// The owners of the file from the owners config or CODEOWNERS
+	Owners []string `json:"owners,omitempty"`
 }
 
 type Findings []*Finding
@@ -89,42 +93,60 @@ func (a *Assessment) EvaluateFailures(thresholds map[string]int) {
 	}
 }
 
+// Returns the path of filePath (which is relative to dir, or absolute)
+// relative to repoRoot.  This doesn't depend on git so it works the
+// same way for untracked files.  If the file isn't within the repo then
+// the absolute path and false are returned.
+func GetRepoPath(repoRoot, dir, filePath string) (string, bool) {
+	path := filePath
+	if !filepath.IsAbs(path) {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return filePath, false
+		}
+		path = filepath.Join(absDir, path)
+	}
+	rel, err := filepath.Rel(repoRoot, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path, false
+	}
+	return filepath.ToSlash(rel), true
+}
+
 func (findings Findings) ComputePartialFingerprints(dir string) {
 	findingsForFiles := map[string][]*Finding{}
 	repoRoot, _ := inventory.FindRepoRoot(dir)
-	var relDir string
-	if repoRoot != "" {
-		relDir, _ = filepath.Rel(repoRoot, dir)
-	}
+	outside := util.NewStringSet()
 	for _, f := range findings {
 		if f.FilePath != "" && f.Line > 0 {
 			findingsForFiles[f.FilePath] = append(findingsForFiles[f.FilePath], f)
 		}
-		if f.RepoPath == "" && f.FilePath != "" && relDir != "" && !f.GeneratedFile {
-			f.RepoPath = filepath.Join(relDir, f.FilePath)
+		if f.RepoPath == "" && f.FilePath != "" && repoRoot != "" && !f.GeneratedFile {
+			var ok bool
+			f.RepoPath, ok = GetRepoPath(repoRoot, dir, f.FilePath)
+			if !ok && outside.Add(f.FilePath) {
+				log.Warnf("{warning:%s} is outside of the repository {info:%s}", f.RepoPath, repoRoot)
+			}
 		}
 	}
 	for filePath, fs := range findingsForFiles {
-		file, err := os.Open(filepath.Join(dir, filePath))
+		data, err := os.ReadFile(filepath.Join(dir, filePath))
 		if err != nil {
 			if !errors.Is(err, os.ErrNotExist) {
 				log.Warnf("Could not read file for fingerprinting - {warning:%s}", err.Error())
 			}
 			continue
 		}
-		findingsForLine := map[int][]*Finding{}
-		for _, f := range fs {
-			findingsForLine[f.Line] = append(findingsForLine[f.Line], f)
+		fingerprints, err := getPartialFingerprints(data)
+		if err != nil {
+			log.Warnf("Could not compute partial fingerprint for %s - %s", filePath, err.Error())
+			continue
 		}
-		err = fingerprint.Partial(bufio.NewReader(file), func(lineNumber int, fingerprint string) {
-			for _, f := range findingsForLine[lineNumber] {
+		for _, f := range fs {
+			if fingerprint, ok := fingerprints[f.Line]; ok {
 				f.PartialFingerprint = fingerprint
 			}
-		})
-		if err != nil {
-			log.Warnf("Could not compute partial fingerprint for %s - %s", filePath, err.Error())
 		}
-		_ = file.Close()
 	}
 }
 
@@ -136,6 +158,17 @@ func (f *Finding) SetAttribute(name, value string) *Finding {
 	return f
 }
 
+// Returns the id of the rule that generated a finding.  This is the
+// tool's rule_id or check_id if it has one, otherwise the Soluble id.
+func (f *Finding) GetRuleID() string {
+	for _, k := range []string{"rule_id", "check_id"} {
+		if id := f.Tool[k]; id != "" {
+			return id
+		}
+	}
+	return f.SID
+}
+
 func (f *Finding) GetTitle() string {
 	if f.Title != "" {
 		return f.Title
@@ -163,3 +196,39 @@ func FindCIEnvAssessments(client *api.Client) (Assessments, error) {
 	}
 	return assessments, nil
 }
+
+// Find the findings of the most recent assessment of module that was
+// uploaded for the git repository of dir and branch.  If branch is empty
+// then the current branch of dir is used.  Returns nil if there's no
+// such assessment.
+func FindLatestFindings(client *api.Client, dir, module, branch string) (Findings, error) {
+	env := xcp.GetCIEnv(dir)
+	repo := env["SOLUBLE_METADATA_GIT_REMOTE"]
+	if repo == "" {
+		return nil, fmt.Errorf("cannot determine the git remote of %s", dir)
+	}
+	if branch == "" {
+		branch = env["SOLUBLE_METADATA_GIT_BRANCH"]
+	}
+	n, err := client.Get("/api/v1/org/{org}/assessments",
+		func(r *resty.Request) {
+			r.SetQueryParam("detail", "true")
+			r.SetQueryParam("searchType", "latest")
+			r.SetQueryParam("module", module)
+			r.SetQueryParam("SOLUBLE_METADATA_GIT_REMOTE", repo)
+			r.SetQueryParam("SOLUBLE_METADATA_GIT_BRANCH", branch)
+		})
+	if err != nil {
+		return nil, err
+	}
+	data := n.Path("data")
+	if data.Size() == 0 {
+		log.Infof("No previous {info:%s} assessment found for {info:%s} {info:%s}", module, repo, branch)
+		return nil, nil
+	}
+	assessment := &Assessment{}
+	if err := json.Unmarshal([]byte(data.Get(0).String()), assessment); err != nil {
+		return nil, err
+	}
+	return assessment.Findings, nil
+}
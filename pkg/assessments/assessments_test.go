@@ -15,8 +15,15 @@
 package assessments
 
 import (
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
 
+	"github.com/jarcoal/httpmock"
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/api"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -46,3 +53,67 @@ func TestAssessmentHasFailures(t *testing.T) {
 		assert.Equal(tc.count, assessment.FailedCount, tc)
 	}
 }
+
+func TestRepoPath(t *testing.T) {
+	assert := assert.New(t)
+	root := t.TempDir()
+	assert.NoError(os.MkdirAll(filepath.Join(root, ".git"), 0777))
+	assert.NoError(os.WriteFile(filepath.Join(root, ".git", "config"), nil, 0600))
+	dir := filepath.Join(root, "infra")
+	assert.NoError(os.MkdirAll(dir, 0777))
+	outside := filepath.Join(t.TempDir(), "other.tf")
+	findings := Findings{
+		{FilePath: "main.tf", Line: 1},
+		{FilePath: filepath.Join(dir, "new", "untracked.tf"), Line: 1},
+		{FilePath: outside, Line: 1},
+		{FilePath: "module.tf", Line: 1, GeneratedFile: true},
+	}
+	findings.ComputePartialFingerprints(dir)
+	assert.Equal("infra/main.tf", findings[0].RepoPath)
+	assert.Equal("infra/new/untracked.tf", findings[1].RepoPath)
+	assert.Equal(outside, findings[2].RepoPath)
+	assert.Equal("", findings[3].RepoPath)
+}
+
+func TestFindLatestFindings(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	assert := assert.New(t)
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"remote", "add", "origin", "git@github.com:fizz/buzz.git"},
+	} {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		assert.NoError(c.Run())
+	}
+	client := api.NewClient(&api.Config{APIServer: "https://api.example.com"})
+	client.Organization = "1234"
+	httpmock.ActivateNonDefault(client.GetClient().GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "https://api.example.com/api/v1/org/1234/assessments",
+		func(r *http.Request) (*http.Response, error) {
+			q := r.URL.Query()
+			assert.Equal("latest", q.Get("searchType"))
+			assert.Equal("checkov", q.Get("module"))
+			assert.Equal("github.com/fizz/buzz", q.Get("SOLUBLE_METADATA_GIT_REMOTE"))
+			n := jnode.NewObjectNode()
+			data := n.PutArray("data")
+			if q.Get("SOLUBLE_METADATA_GIT_BRANCH") == "main" {
+				data.AppendObject().PutArray("findings").AppendObject().
+					Put("sid", "c-aws-s3-1").Put("filePath", "main.tf").Put("line", 3)
+			}
+			return httpmock.NewJsonResponse(http.StatusOK, n)
+		})
+	findings, err := FindLatestFindings(client, dir, "checkov", "main")
+	assert.NoError(err)
+	if assert.Len(findings, 1) {
+		assert.Equal("c-aws-s3-1", findings[0].SID)
+		assert.Equal(3, findings[0].Line)
+	}
+	findings, err = FindLatestFindings(client, dir, "checkov", "feature")
+	assert.NoError(err)
+	assert.Nil(findings)
+}
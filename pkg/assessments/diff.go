@@ -0,0 +1,135 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assessments
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/soluble-ai/go-jnode"
+)
+
+// The difference between the failed findings of two scans
+type FindingsDiff struct {
+	Added     Findings `json:"added"`
+	Removed   Findings `json:"removed"`
+	Unchanged Findings `json:"unchanged"`
+}
+
+// Compare the failed findings of an older and newer scan.  Findings are
+// the same if they're for the same rule in the same file, and on a line
+// with the same partial fingerprint, so findings that only moved because
+// lines were added or removed above them are unchanged.  (Findings without
+// a partial fingerprint are compared by line.)  The unchanged findings are
+// the ones from the newer scan.
+func DiffFindings(older, newer Findings) *FindingsDiff {
+	diff := &FindingsDiff{
+		Added:     Findings{},
+		Removed:   Findings{},
+		Unchanged: Findings{},
+	}
+	olderByKey := map[string]Findings{}
+	for _, f := range older {
+		if !f.Pass {
+			k := f.getDiffKey()
+			olderByKey[k] = append(olderByKey[k], f)
+		}
+	}
+	for _, f := range newer {
+		if f.Pass {
+			continue
+		}
+		k := f.getDiffKey()
+		if fs := olderByKey[k]; len(fs) > 0 {
+			olderByKey[k] = fs[1:]
+			diff.Unchanged = append(diff.Unchanged, f)
+		} else {
+			diff.Added = append(diff.Added, f)
+		}
+	}
+	// keep the removed findings in their original order
+	for _, f := range older {
+		if f.Pass {
+			continue
+		}
+		k := f.getDiffKey()
+		if fs := olderByKey[k]; len(fs) > 0 && fs[0] == f {
+			olderByKey[k] = fs[1:]
+			diff.Removed = append(diff.Removed, f)
+		}
+	}
+	return diff
+}
+
+func (f *Finding) getDiffKey() string {
+	rule := f.GetRuleID()
+	if rule == "" {
+		rule = f.Title
+	}
+	path := f.RepoPath
+	if path == "" {
+		path = f.FilePath
+	}
+	position := f.PartialFingerprint
+	if position == "" {
+		position = strconv.Itoa(f.Line)
+	}
+	return fmt.Sprintf("%s\x00%s\x00%s", rule, path, position)
+}
+
+// Read findings from a file.  The file can have an array of findings
+// (e.g. the findings.json of an upload), an array of assessments with
+// findings (as printed by --format json), or a single object with
+// findings.
+func ReadFindingsFile(path string) (Findings, error) {
+	d, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	n, err := jnode.FromJSON(d)
+	if err != nil {
+		return nil, fmt.Errorf("could not read findings from %s: %w", path, err)
+	}
+	var findings Findings
+	switch {
+	case n.IsObject() && n.Path("findings").IsArray():
+		err = unmarshalFindings(n.Path("findings"), &findings)
+	case n.IsArray() && n.Size() > 0 && n.Get(0).Path("findings").IsArray():
+		for _, e := range n.Elements() {
+			if err = unmarshalFindings(e.Path("findings"), &findings); err != nil {
+				break
+			}
+		}
+	case n.IsArray():
+		err = unmarshalFindings(n, &findings)
+	default:
+		return nil, fmt.Errorf("%s does not have findings", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read findings from %s: %w", path, err)
+	}
+	return findings, nil
+}
+
+func unmarshalFindings(n *jnode.Node, findings *Findings) error {
+	var fs Findings
+	if err := json.Unmarshal([]byte(n.String()), &fs); err != nil {
+		return err
+	}
+	*findings = append(*findings, fs...)
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assessments
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffFindings(t *testing.T) {
+	assert := assert.New(t)
+	older := Findings{
+		{SID: "c1", RepoPath: "main.tf", Line: 10, PartialFingerprint: "aa"},
+		{SID: "c2", RepoPath: "main.tf", Line: 20, PartialFingerprint: "bb"},
+		{SID: "c2", RepoPath: "main.tf", Line: 20, PartialFingerprint: "bb"},
+		{SID: "c3", RepoPath: "main.tf", Line: 30},
+		{SID: "c4", RepoPath: "main.tf", Line: 40, Pass: true},
+	}
+	newer := Findings{
+		// moved down 5 lines
+		{SID: "c1", RepoPath: "main.tf", Line: 15, PartialFingerprint: "aa"},
+		{SID: "c2", RepoPath: "main.tf", Line: 25, PartialFingerprint: "bb"},
+		// no fingerprint, so compared by line
+		{SID: "c3", RepoPath: "main.tf", Line: 35},
+		{Tool: map[string]string{"rule_id": "r5"}, FilePath: "x.yaml", Line: 1},
+		{SID: "c4", RepoPath: "main.tf", Line: 45, Pass: true},
+	}
+	diff := DiffFindings(older, newer)
+	assert.Equal(Findings{newer[2], newer[3]}, diff.Added)
+	assert.Equal(Findings{older[2], older[3]}, diff.Removed)
+	assert.Equal(Findings{newer[0], newer[1]}, diff.Unchanged)
+	diff = DiffFindings(older, older)
+	assert.Empty(diff.Added)
+	assert.Empty(diff.Removed)
+	assert.Len(diff.Unchanged, 4)
+}
+
+func TestReadFindingsFile(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"findings.json":    `[{"sid":"c1","line":1},{"sid":"c2","line":2}]`,
+		"assessments.json": `[{"findings":[{"sid":"c1","line":1}]},{"findings":[{"sid":"c2","line":2}]}]`,
+		"assessment.json":  `{"findings":[{"sid":"c1","line":1},{"sid":"c2","line":2}]}`,
+	} {
+		path := filepath.Join(dir, name)
+		assert.NoError(os.WriteFile(path, []byte(content), 0600))
+		findings, err := ReadFindingsFile(path)
+		if assert.NoError(err, name) && assert.Len(findings, 2, name) {
+			assert.Equal("c1", findings[0].SID, name)
+			assert.Equal(2, findings[1].Line, name)
+		}
+	}
+	path := filepath.Join(dir, "other.json")
+	assert.NoError(os.WriteFile(path, []byte(`{"data":[]}`), 0600))
+	_, err := ReadFindingsFile(path)
+	assert.Error(err)
+}
@@ -0,0 +1,187 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assessments
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments/fingerprint"
+	"github.com/soluble-ai/soluble-cli/pkg/config"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+const (
+	// Bump this whenever the partial fingerprint algorithm changes so
+	// that cached fingerprints are recomputed
+	fingerprintVersion = 1
+	// Smaller files are quicker to fingerprint than to look up
+	minCachedFileSize = 16 << 10
+	// Cached fingerprints that haven't been used for this long are
+	// removed, and then the least recently used are removed until the
+	// cache is no larger than maxFingerprintCacheSize
+	maxFingerprintCacheAge  = 30 * 24 * time.Hour
+	maxFingerprintCacheSize = 64 << 20
+	// How often the cache is pruned
+	fingerprintCachePruneInterval = 24 * time.Hour
+)
+
+var pruneFingerprintCacheOnce sync.Once
+
+func getFingerprintCacheDir() string {
+	if config.ConfigDir == "" {
+		return ""
+	}
+	return filepath.Join(config.ConfigDir, "fingerprints", fmt.Sprintf("v%d", fingerprintVersion))
+}
+
+// Returns the partial fingerprint of each line of data.  The fingerprints
+// of larger files are cached by the hash of their content, so a file
+// that hasn't changed since it was last fingerprinted isn't fingerprinted
+// again.
+func getPartialFingerprints(data []byte) (map[int]string, error) {
+	var cacheFile string
+	if dir := getFingerprintCacheDir(); dir != "" && len(data) >= minCachedFileSize {
+		sum := sha256.Sum256(data)
+		h := hex.EncodeToString(sum[:])
+		cacheFile = filepath.Join(dir, h[:2], fmt.Sprintf("%s.json", h))
+		pruneFingerprintCacheOnce.Do(func() { pruneFingerprintCache(dir, time.Now()) })
+		if fingerprints := readFingerprintCache(cacheFile); fingerprints != nil {
+			return fingerprints, nil
+		}
+	}
+	fingerprints := map[int]string{}
+	err := fingerprint.Partial(bufio.NewReader(bytes.NewReader(data)), func(lineNumber int, fingerprint string) {
+		fingerprints[lineNumber] = fingerprint
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cacheFile != "" {
+		if err := writeFingerprintCache(cacheFile, fingerprints); err != nil {
+			log.Debugf("Could not cache fingerprints - %s", err)
+		}
+	}
+	return fingerprints, nil
+}
+
+func readFingerprintCache(path string) map[int]string {
+	d, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var fingerprints map[int]string
+	if err := json.Unmarshal(d, &fingerprints); err != nil {
+		log.Debugf("Ignoring invalid fingerprint cache %s - %s", path, err)
+		return nil
+	}
+	// the modification time records when the fingerprints were last used
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return fingerprints
+}
+
+type fingerprintCacheFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// Remove the caches of other fingerprint versions, the fingerprints that
+// haven't been used recently, and then the least recently used
+// fingerprints until the cache is small enough.  This is done at most
+// once per fingerprintCachePruneInterval.
+func pruneFingerprintCache(dir string, now time.Time) {
+	marker := filepath.Join(dir, ".pruned")
+	if info, err := os.Stat(marker); err == nil && now.Sub(info.ModTime()) < fingerprintCachePruneInterval {
+		return
+	}
+	if entries, err := os.ReadDir(filepath.Dir(dir)); err == nil {
+		for _, e := range entries {
+			if e.Name() != filepath.Base(dir) {
+				_ = os.RemoveAll(filepath.Join(filepath.Dir(dir), e.Name()))
+			}
+		}
+	}
+	var (
+		files []*fingerprintCacheFile
+		size  int64
+	)
+	_ = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if now.Sub(info.ModTime()) > maxFingerprintCacheAge {
+			_ = os.Remove(path)
+			return nil
+		}
+		files = append(files, &fingerprintCacheFile{path: path, size: info.Size(), modTime: info.ModTime()})
+		size += info.Size()
+		return nil
+	})
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, f := range files {
+		if size <= maxFingerprintCacheSize {
+			break
+		}
+		if os.Remove(f.path) == nil {
+			size -= f.size
+		}
+	}
+	if err := os.MkdirAll(dir, 0700); err == nil {
+		if err := os.WriteFile(marker, nil, 0600); err == nil {
+			_ = os.Chtimes(marker, now, now)
+		}
+	}
+}
+
+func writeFingerprintCache(path string, fingerprints map[int]string) error {
+	d, err := json.Marshal(fingerprints)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	// write then rename so that concurrent scans never see a partial file
+	f, err := os.CreateTemp(filepath.Dir(path), "fingerprints*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(d)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+	return err
+}
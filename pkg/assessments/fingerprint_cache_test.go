@@ -0,0 +1,91 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assessments
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soluble-ai/soluble-cli/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprintCache(t *testing.T) {
+	assert := assert.New(t)
+	configDir := config.ConfigDir
+	defer func() { config.ConfigDir = configDir }()
+	config.ConfigDir = t.TempDir()
+	dir := t.TempDir()
+	var sb strings.Builder
+	for i := 0; sb.Len() < minCachedFileSize; i++ {
+		fmt.Fprintf(&sb, "resource \"aws_s3_bucket\" \"b%d\" {}\n", i)
+	}
+	path := filepath.Join(dir, "main.tf")
+	assert.NoError(os.WriteFile(path, []byte(sb.String()), 0600))
+	fingerprint := func() string {
+		findings := Findings{{FilePath: "main.tf", Line: 3}}
+		findings.ComputePartialFingerprints(dir)
+		return findings[0].PartialFingerprint
+	}
+	fp := fingerprint()
+	assert.NotEmpty(fp)
+	cached, _ := filepath.Glob(filepath.Join(getFingerprintCacheDir(), "*", "*.json"))
+	if assert.Len(cached, 1) {
+		// prove the cache is used by tampering with it
+		assert.NoError(os.WriteFile(cached[0], []byte(`{"3":"cached"}`), 0600))
+		assert.Equal("cached", fingerprint())
+	}
+	// a change to the file invalidates the cache
+	assert.NoError(os.WriteFile(path, []byte(sb.String()+"\n"), 0600))
+	assert.Equal(fp, fingerprint())
+	cached, _ = filepath.Glob(filepath.Join(getFingerprintCacheDir(), "*", "*.json"))
+	assert.Len(cached, 2)
+}
+
+func TestPruneFingerprintCache(t *testing.T) {
+	assert := assert.New(t)
+	root := t.TempDir()
+	dir := filepath.Join(root, "v2")
+	write := func(path string, size int, age time.Duration) {
+		assert.NoError(os.MkdirAll(filepath.Dir(path), 0700))
+		assert.NoError(os.WriteFile(path, make([]byte, size), 0600))
+		mtime := time.Now().Add(-age)
+		assert.NoError(os.Chtimes(path, mtime, mtime))
+	}
+	write(filepath.Join(root, "v1", "aa", "old-version.json"), 1, 0)
+	write(filepath.Join(dir, "aa", "stale.json"), 1, maxFingerprintCacheAge+time.Hour)
+	write(filepath.Join(dir, "aa", "lru.json"), maxFingerprintCacheSize/2, 2*time.Hour)
+	write(filepath.Join(dir, "bb", "older.json"), maxFingerprintCacheSize/2, time.Hour)
+	write(filepath.Join(dir, "bb", "recent.json"), 1, 0)
+	pruneFingerprintCache(dir, time.Now())
+	var remaining []string
+	_ = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err == nil && filepath.Ext(path) == ".json" {
+			remaining = append(remaining, filepath.Base(path))
+		}
+		return nil
+	})
+	assert.ElementsMatch([]string{"older.json", "recent.json"}, remaining)
+	// not pruned again until the interval has passed
+	write(filepath.Join(dir, "aa", "stale.json"), 1, maxFingerprintCacheAge+time.Hour)
+	pruneFingerprintCache(dir, time.Now())
+	assert.FileExists(filepath.Join(dir, "aa", "stale.json"))
+	pruneFingerprintCache(dir, time.Now().Add(fingerprintCachePruneInterval+time.Hour))
+	assert.NoFileExists(filepath.Join(dir, "aa", "stale.json"))
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assessments
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Converts byte offsets within files into line numbers, for tools that
+// report the position of a finding as an offset.  Each file is read once
+// and the offsets of its lines are kept for later findings in the same file.
+type LineOffsets struct {
+	m     sync.Mutex
+	files map[string]*lineIndex
+}
+
+type lineIndex struct {
+	// the offset of the start of each line
+	starts []int
+	size   int
+}
+
+func NewLineOffsets() *LineOffsets {
+	return &LineOffsets{files: map[string]*lineIndex{}}
+}
+
+// Returns the 1-based line number of offset in the file at path.  An
+// offset at the end of the file is on the last line.
+func (lo *LineOffsets) GetLine(path string, offset int) (int, error) {
+	index, err := lo.getIndex(path)
+	if err != nil {
+		return 0, err
+	}
+	if offset < 0 || offset > index.size {
+		return 0, fmt.Errorf("offset %d is outside of %s which is %d bytes", offset, path, index.size)
+	}
+	return sort.Search(len(index.starts), func(i int) bool {
+		return index.starts[i] > offset
+	}), nil
+}
+
+func (lo *LineOffsets) getIndex(path string) (*lineIndex, error) {
+	lo.m.Lock()
+	defer lo.m.Unlock()
+	if index := lo.files[path]; index != nil {
+		return index, nil
+	}
+	d, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// lines end with \n, so a \r\n ending is counted as part of its line
+	index := &lineIndex{starts: []int{0}, size: len(d)}
+	for i := 0; ; {
+		nl := bytes.IndexByte(d[i:], '\n')
+		if nl < 0 || i+nl+1 == len(d) {
+			break
+		}
+		i += nl + 1
+		index.starts = append(index.starts, i)
+	}
+	lo.files[path] = index
+	return index, nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assessments
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineOffsets(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.tf")
+	assert.NoError(os.WriteFile(path, []byte("one\r\ntwo\n\nfour\n"), 0600))
+	lo := NewLineOffsets()
+	for offset, line := range map[int]int{0: 1, 4: 1, 5: 2, 8: 2, 9: 3, 10: 4, 14: 4, 15: 4} {
+		l, err := lo.GetLine(path, offset)
+		if assert.NoError(err) {
+			assert.Equal(line, l, "offset %d", offset)
+		}
+	}
+	_, err := lo.GetLine(path, 16)
+	assert.Error(err)
+	_, err = lo.GetLine(path, -1)
+	assert.Error(err)
+	// the index is cached
+	assert.NoError(os.Remove(path))
+	l, err := lo.GetLine(path, 10)
+	assert.NoError(err)
+	assert.Equal(4, l)
+	_, err = lo.GetLine(filepath.Join(dir, "missing.tf"), 0)
+	assert.Error(err)
+	empty := filepath.Join(dir, "empty.tf")
+	assert.NoError(os.WriteFile(empty, nil, 0600))
+	l, err = lo.GetLine(empty, 0)
+	assert.NoError(err)
+	assert.Equal(1, l)
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assessments
+
+import (
+	"sort"
+	"strings"
+)
+
+// Tools use a variety of names for their severity levels, this maps the
+// common ones to one of SeverityNames.
+var severityAliases = map[string]string{
+	"informational": "info",
+	"information":   "info",
+	"note":          "info",
+	"style":         "info",
+	"warn":          "medium",
+	"warning":       "medium",
+	"moderate":      "medium",
+	"error":         "high",
+	"danger":        "high",
+}
+
+// Return the canonical (one of SeverityNames) name of a severity, or ""
+// if the severity isn't recognized.
+func NormalizeSeverity(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if SeverityNames.Contains(s) {
+		return s
+	}
+	return severityAliases[s]
+}
+
+// Return the rank of a severity, from 0 for unrecognized severities
+// up to 5 for critical.
+func SeverityLevel(s string) int {
+	s = NormalizeSeverity(s)
+	for i, name := range SeverityNames.Values() {
+		if name == s {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Return the canonical severity of a finding.  This is the severity
+// assigned by Soluble if present, otherwise the severity the tool reported.
+func (f *Finding) GetSeverity() string {
+	if f.Severity != "" {
+		return NormalizeSeverity(f.Severity)
+	}
+	return NormalizeSeverity(f.Tool["severity"])
+}
+
+// Sort the findings from most to least severe.  The relative order of
+// findings with the same severity is preserved.
+func (findings Findings) SortBySeverity() {
+	sort.SliceStable(findings, func(i, j int) bool {
+		return SeverityLevel(findings[i].GetSeverity()) > SeverityLevel(findings[j].GetSeverity())
+	})
+}
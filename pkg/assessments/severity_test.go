@@ -0,0 +1,48 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assessments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeSeverity(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("high", NormalizeSeverity("HIGH"))
+	assert.Equal("medium", NormalizeSeverity("warning"))
+	assert.Equal("info", NormalizeSeverity("style"))
+	assert.Equal("", NormalizeSeverity("bogus"))
+	assert.Equal(5, SeverityLevel("critical"))
+	assert.Equal(0, SeverityLevel(""))
+}
+
+func TestSortBySeverity(t *testing.T) {
+	assert := assert.New(t)
+	findings := Findings{
+		{FilePath: "a", Tool: map[string]string{"severity": "LOW"}},
+		{FilePath: "b"},
+		{FilePath: "c", Severity: "critical"},
+		{FilePath: "d", Tool: map[string]string{"severity": "error"}},
+		{FilePath: "e", Tool: map[string]string{"severity": "low"}},
+	}
+	findings.SortBySeverity()
+	var paths []string
+	for _, f := range findings {
+		paths = append(paths, f.FilePath)
+	}
+	assert.Equal([]string{"c", "d", "a", "e", "b"}, paths)
+}
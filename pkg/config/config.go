@@ -39,11 +39,15 @@ var GlobalConfig = &struct {
 
 // Config points to the current profile
 var (
-	Config             = &ProfileT{}
-	ConfigFile         string
-	ConfigDir          string
-	configFileRead     string
-	migrationAvailable bool
+	Config     = &ProfileT{}
+	ConfigFile string
+	ConfigDir  string
+	// A file of additional CA certificates to trust
+	CABundle string
+	// Disable TLS verification everywhere, for testing only
+	InsecureSkipTLSVerify bool
+	configFileRead        string
+	migrationAvailable    bool
 )
 
 const Redacted = "*** redacted ***"
@@ -146,6 +150,13 @@ func (c *ProfileT) GetAPIToken() string {
 	if token != "" {
 		return token
 	}
+	if path := os.Getenv("SOLUBLE_API_TOKEN_FILE"); path != "" {
+		token, err := ReadAPITokenFile(path)
+		if err != nil {
+			log.Errorf("Could not read API token from {info:%s}: {danger:%s}", path, err.Error())
+		}
+		return token
+	}
 	return c.APIToken
 }
 
@@ -153,9 +164,32 @@ func (c *ProfileT) AssertAPITokenFromConfig() error {
 	if os.Getenv("SOLUBLE_API_TOKEN") != "" {
 		return fmt.Errorf("the environment variable SOLUBLE_API_TOKEN is set")
 	}
+	if os.Getenv("SOLUBLE_API_TOKEN_FILE") != "" {
+		return fmt.Errorf("the environment variable SOLUBLE_API_TOKEN_FILE is set")
+	}
 	return nil
 }
 
+// Read an API token from a file e.g. a mounted kubernetes secret.  Leading
+// and trailing whitespace (including newlines) is removed.
+func ReadAPITokenFile(path string) (string, error) {
+	dat, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(dat)), nil
+}
+
+// Returns the organization to use, which can be overridden with the
+// SOLUBLE_ORG environment variable.
+func (c *ProfileT) GetOrganization() string {
+	org := strings.TrimSpace(os.Getenv("SOLUBLE_ORG"))
+	if org != "" {
+		return org
+	}
+	return c.Organization
+}
+
 func (c *ProfileT) GetAPIServer() string {
 	server := strings.TrimSpace(os.Getenv(("SOLUBLE_API_SERVER")))
 	if server != "" {
@@ -220,6 +254,9 @@ func Set(name, value string) error {
 }
 
 func Load() {
+	if CABundle == "" {
+		CABundle = os.Getenv("SOLUBLE_CA_BUNDLE")
+	}
 	if ConfigDir == "" {
 		ConfigDir = os.Getenv("SOLUBLE_CONFIG_DIR")
 		if ConfigDir == "" {
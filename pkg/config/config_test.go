@@ -72,3 +72,28 @@ func TestGetAppURL(t *testing.T) {
 		t.Error(c.APIServer, u)
 	}
 }
+
+func TestGetAPITokenFromFile(t *testing.T) {
+	assert := assert.New(t)
+	f, err := ioutil.TempFile("", "token*")
+	if !assert.NoError(err) {
+		return
+	}
+	defer os.Remove(f.Name())
+	_, _ = f.WriteString("  zzz\n")
+	f.Close()
+	os.Setenv("SOLUBLE_API_TOKEN_FILE", f.Name())
+	defer os.Unsetenv("SOLUBLE_API_TOKEN_FILE")
+	c := &ProfileT{APIToken: "xxx"}
+	assert.Equal("zzz", c.GetAPIToken())
+	assert.Error(c.AssertAPITokenFromConfig())
+}
+
+func TestGetOrganization(t *testing.T) {
+	assert := assert.New(t)
+	c := &ProfileT{Organization: "1234"}
+	t.Setenv("SOLUBLE_ORG", "")
+	assert.Equal("1234", c.GetOrganization())
+	t.Setenv("SOLUBLE_ORG", " 5678 ")
+	assert.Equal("5678", c.GetOrganization())
+}
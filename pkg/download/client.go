@@ -0,0 +1,66 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+
+	"github.com/soluble-ai/soluble-cli/pkg/config"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+)
+
+var (
+	httpClient    *http.Client
+	httpClientKey string
+)
+
+// Returns the client used for downloads (and other requests outside of
+// the api), which trusts the certificates in config.CABundle if it's set,
+// or doesn't verify certificates at all with config.InsecureSkipTLSVerify.
+func GetHTTPClient() *http.Client {
+	if config.InsecureSkipTLSVerify {
+		log.Warnf("{danger:TLS verification of downloads is disabled, this is insecure and only for testing}")
+	} else if config.CABundle == "" {
+		return http.DefaultClient
+	}
+	key := fmt.Sprintf("%s:%v", config.CABundle, config.InsecureSkipTLSVerify)
+	if httpClient == nil || httpClientKey != key {
+		tlsConfig := &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			// #nosec G402
+			InsecureSkipVerify: config.InsecureSkipTLSVerify,
+		}
+		if config.CABundle != "" {
+			pool, err := util.LoadCertPool(config.CABundle)
+			if err != nil {
+				log.Errorf("Cannot load the CA bundle {warning:%s} - {danger:%s}", config.CABundle, err)
+				return http.DefaultClient
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport, ok := http.DefaultTransport.(*http.Transport)
+		if !ok {
+			return http.DefaultClient
+		}
+		transport = transport.Clone()
+		transport.TLSClientConfig = tlsConfig
+		httpClient = &http.Client{Transport: transport}
+		httpClientKey = key
+	}
+	return httpClient
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"bytes"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/jarcoal/httpmock"
+	"github.com/soluble-ai/soluble-cli/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchWithCABundle(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.DeactivateAndReset()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	config.CABundle = filepath.Join(dir, "ca.pem")
+	defer func() { config.CABundle = "" }()
+	dat := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assert.NoError(os.WriteFile(config.CABundle, dat, 0600))
+	file := filepath.Join(dir, "hello.txt")
+	assert.NoError(fetch(GetHTTPClient(), server.URL+"/hello.txt", file, "", nil))
+	dat, err := os.ReadFile(file)
+	assert.NoError(err)
+	assert.Equal("hello", string(dat))
+}
+
+func TestFetchInsecureSkipTLSVerify(t *testing.T) {
+	assert := assert.New(t)
+	httpmock.DeactivateAndReset()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+	file := filepath.Join(t.TempDir(), "hello.txt")
+	assert.Error(fetch(GetHTTPClient(), server.URL+"/hello.txt", file, "", nil))
+	w := &bytes.Buffer{}
+	output := color.Output
+	color.Output = w
+	defer func() { color.Output = output }()
+	config.InsecureSkipTLSVerify = true
+	defer func() { config.InsecureSkipTLSVerify = false }()
+	assert.NoError(fetch(GetHTTPClient(), server.URL+"/hello.txt", file, "", nil))
+	assert.Contains(w.String(), "TLS verification of downloads is disabled")
+}
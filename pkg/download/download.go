@@ -48,6 +48,10 @@ type Download struct {
 	Dir               string
 	InstallTime       time.Time
 	OverrideExe       string `json:"-"`
+	// For terraform modules, the directory of the module within the download
+	Subdir string `json:",omitempty"`
+	// The ETag of the download, used to check if an artifact has changed
+	ETag string `json:",omitempty"`
 }
 
 type DownloadMeta struct {
@@ -67,6 +71,13 @@ type Spec struct {
 	GithubReleaseMatcher       GithubReleaseMatcher
 	LatestReleaseCacheDuration time.Duration
 	GetLatestVersion           func(*Spec) (string, error)
+	// If set, the download must have this sha256 checksum
+	SHA256 string
+	// If set, download this terraform registry module source.  The
+	// RequestedVersion must be given.
+	TerraformModule string
+	archiveType     string
+	subdir          string
 }
 
 type APIServer interface {
@@ -88,6 +99,14 @@ func NewManager() *Manager {
 	}
 }
 
+// Returns a manager that installs into dir instead of the download
+// cache, for downloads that are only used once.
+func NewManagerInDir(dir string) *Manager {
+	return &Manager{
+		downloadDir: dir,
+	}
+}
+
 func (m *Manager) GetMeta(name string) *DownloadMeta {
 	for _, meta := range m.List() {
 		if meta.Name == name {
@@ -171,6 +190,18 @@ func (m *Manager) Install(spec *Spec) (*Download, error) {
 	if owner != "" {
 		spec.Name = fmt.Sprintf("%s-%s", owner, repo)
 	}
+	var module *moduleSource
+	if spec.TerraformModule != "" {
+		var err error
+		module, err = parseModuleSource(spec.TerraformModule)
+		if err != nil {
+			return nil, err
+		}
+		if spec.RequestedVersion == "" || isLatestTag(spec.RequestedVersion) {
+			return nil, fmt.Errorf("a version must be given for the module %s", spec.TerraformModule)
+		}
+		spec.Name = module.getName()
+	}
 	if spec.Name == "" {
 		return nil, fmt.Errorf("name must be specified for plain URL downloads")
 	}
@@ -208,6 +239,20 @@ func (m *Manager) Install(spec *Spec) (*Download, error) {
 		}
 	}
 	options := []downloadOption{}
+	if module != nil {
+		token := getRegistryToken(os.Getenv, module.host)
+		md, err := getModuleDownload(GetHTTPClient(), module, spec.RequestedVersion, token)
+		if err != nil {
+			return nil, err
+		}
+		spec.URL = md.url
+		spec.archiveType = md.archiveType
+		spec.subdir = md.subdir
+		// only send the registry's token back to the registry
+		if u, err := url.Parse(md.url); err == nil && u.Host == module.host && token != "" {
+			options = append(options, withBearerToken(token))
+		}
+	}
 	if spec.APIServerArtifact != "" {
 		url := fmt.Sprintf("%s%s", spec.APIServer.GetHostURL(), spec.APIServerArtifact)
 		spec.URL = strings.ReplaceAll(url, "{org}", spec.APIServer.GetOrganization())
@@ -236,6 +281,32 @@ func (m *Manager) Remove(name, version string) error {
 	return meta.removeVersion(m, version)
 }
 
+// Remove all downloaded components, or just the component name if
+// name isn't empty.  Returns the number of bytes freed.
+func (m *Manager) Clear(name string) (int64, error) {
+	dir := m.downloadDir
+	if name != "" {
+		meta := m.GetMeta(name)
+		if meta == nil {
+			return 0, fmt.Errorf("the component %s is not installed", name)
+		}
+		dir = meta.Dir
+	}
+	var size int64
+	_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	log.Infof("Removing {info:%s}", dir)
+	if err := os.RemoveAll(dir); err != nil {
+		return 0, err
+	}
+	m.meta = nil
+	return size, nil
+}
+
 func (m *Manager) save(meta *DownloadMeta) error {
 	f, err := os.Create(filepath.Join(m.downloadDir, meta.Name, "meta.json"))
 	if err != nil {
@@ -267,41 +338,45 @@ func (meta *DownloadMeta) install(m *Manager, spec *Spec, actualVersion string,
 	if err != nil {
 		return nil, err
 	}
+	if spec.archiveType != "" && !strings.HasSuffix(base, "."+spec.archiveType) {
+		base = fmt.Sprintf("%s.%s", base, spec.archiveType)
+	}
 	nameDir := filepath.Join(m.downloadDir, meta.Name)
 	if err := os.MkdirAll(nameDir, 0777); err != nil {
 		return nil, err
 	}
 	archiveFile := filepath.Join(nameDir, base)
-	w, err := os.Create(archiveFile)
-	if err != nil {
-		return nil, err
+	// don't resume from some earlier download
+	_ = os.Remove(archiveFile)
+	// API server artifacts are always "latest", so use the ETag of what we
+	// have to see if there's anything new
+	var current *Download
+	if spec.APIServerArtifact != "" {
+		current = meta.findVersionExactly(actualVersion)
 	}
-	defer w.Close()
-	log.Infof("Getting {info:%s}", spec.URL)
-	req, err := http.NewRequest("GET", spec.URL, nil)
-	if err != nil {
-		return nil, err
+	var etag string
+	if current != nil {
+		etag = current.ETag
 	}
-	for _, opt := range options {
-		if err = opt(req); err != nil {
+	log.Infof("Getting {info:%s}", spec.URL)
+	etag, err = fetchWithETag(GetHTTPClient(), spec.URL, archiveFile, spec.SHA256, etag, options)
+	if errors.Is(err, errNotModified) {
+		log.Infof("{primary:%s} is unchanged", meta.Name)
+		meta.updateLatestInfo(spec.RequestedVersion, actualVersion)
+		if err := m.save(meta); err != nil {
 			return nil, err
 		}
+		return current, nil
 	}
-	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		log.Errorf("Request to install {warning:%s} returned status code {danger:%d}", meta.Name,
-			resp.StatusCode)
-		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusNotFound {
-			log.Infof("Not logged into soluble?  Use {primary:soluble login} to login.")
+		var se *statusError
+		if errors.As(err, &se) {
+			log.Errorf("Request to install {warning:%s} returned status code {danger:%d}", meta.Name,
+				se.statusCode)
+			if se.statusCode == http.StatusUnauthorized || se.statusCode == http.StatusNotFound {
+				log.Infof("Not logged into soluble?  Use {primary:soluble login} to login.")
+			}
 		}
-		return nil, fmt.Errorf("%s returned %d", spec.URL, resp.StatusCode)
-	}
-	_, err = io.Copy(w, resp.Body)
-	if err != nil {
 		return nil, err
 	}
 	d := &Download{
@@ -311,6 +386,8 @@ func (meta *DownloadMeta) install(m *Manager, spec *Spec, actualVersion string,
 		APIServerArtifact: spec.APIServerArtifact,
 		Dir:               filepath.Join(m.downloadDir, meta.Name, actualVersion),
 		InstallTime:       time.Now(),
+		Subdir:            spec.subdir,
+		ETag:              etag,
 	}
 	meta.removeInstalledVersion(d.Version)
 	meta.Installed = append(meta.Installed, d)
@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/jarcoal/httpmock"
 )
@@ -105,6 +106,78 @@ func TestAPIServerArtifact(t *testing.T) {
 	}
 }
 
+func TestAPIServerArtifactETag(t *testing.T) {
+	setupHTTP()
+	defer httpmock.DeactivateAndReset()
+	dat, err := ioutil.ReadFile(filepath.Join("testdata", "hello.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	etag := `"v1"`
+	fetches := 0
+	httpmock.RegisterResponder("GET", "https://example.com/secure/rules.zip",
+		func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("If-None-Match") == etag {
+				return httpmock.NewStringResponse(http.StatusNotModified, ""), nil
+			}
+			fetches++
+			resp := httpmock.NewBytesResponse(http.StatusOK, dat)
+			resp.Header.Set("ETag", etag)
+			return resp, nil
+		})
+	m := setupManager()
+	spec := func() *Spec {
+		return &Spec{
+			Name: "rules", APIServerArtifact: "/rules.zip", APIServer: apiServer("foo"),
+			LatestReleaseCacheDuration: time.Nanosecond,
+		}
+	}
+	d, err := m.Install(spec())
+	if err != nil || d.ETag != `"v1"` || fetches != 1 {
+		t.Fatal(d, err, fetches)
+	}
+	d, err = m.Install(spec())
+	if err != nil || d.ETag != `"v1"` || fetches != 1 {
+		t.Fatal("unchanged artifact should not be fetched again", d, err, fetches)
+	}
+	if _, err := os.Stat(filepath.Join(d.Dir, "README.txt")); err != nil {
+		t.Error(err)
+	}
+	etag = `"v2"`
+	d, err = m.Install(spec())
+	if err != nil || d.ETag != `"v2"` || fetches != 2 {
+		t.Fatal("changed artifact should be fetched", d, err, fetches)
+	}
+}
+
+func TestClear(t *testing.T) {
+	setupHTTP()
+	defer httpmock.DeactivateAndReset()
+	m := setupManager()
+	for _, name := range []string{"hello", "world"} {
+		if _, err := m.Install(&Spec{Name: name, RequestedVersion: "1.0", URL: "https://example.com/hello.tar.gz"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := m.Clear("nope"); err == nil {
+		t.Error("clearing a missing component should fail")
+	}
+	size, err := m.Clear("hello")
+	if err != nil || size == 0 {
+		t.Error(size, err)
+	}
+	if m.GetMeta("hello") != nil || m.GetMeta("world") == nil {
+		t.Error("only hello should be removed")
+	}
+	size, err = m.Clear("")
+	if err != nil || size == 0 {
+		t.Error(size, err)
+	}
+	if len(m.List()) != 0 {
+		t.Error("everything should be removed")
+	}
+}
+
 func setupManager() *Manager {
 	m := NewManager()
 	dir, err := ioutil.TempDir("", "downloadtest*")
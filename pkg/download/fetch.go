@@ -0,0 +1,225 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+const fetchAttempts = 3
+
+var fetchRetryWait = 2 * time.Second
+
+// A non-retryable error, e.g. a 404.
+type statusError struct {
+	url        string
+	statusCode int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s returned %d", e.url, e.statusCode)
+}
+
+// Returns true if err means that a download couldn't be reached, e.g.
+// the network is down or the server is unavailable.  Errors about what
+// was downloaded, like a checksum mismatch, are not.
+func IsUnavailable(err error) bool {
+	var (
+		se  *statusError
+		ue  *url.Error
+		ne  net.Error
+		ger *github.ErrorResponse
+		rle *github.RateLimitError
+	)
+	switch {
+	case errors.As(err, &se):
+		return se.statusCode == http.StatusTooManyRequests || se.statusCode >= 500
+	case errors.As(err, &ger):
+		return ger.Response != nil && ger.Response.StatusCode >= 500
+	case errors.As(err, &rle):
+		return true
+	case errors.As(err, &ue), errors.As(err, &ne):
+		return true
+	}
+	return false
+}
+
+// Returned when a conditional fetch finds the server's copy is unchanged.
+var errNotModified = errors.New("not modified")
+
+// Fetch url into file, retrying if the download is interrupted or if the
+// result is corrupt.  If the server supports range requests, an interrupted
+// download is resumed rather than restarted.  The file is only left in place
+// when it's complete and verified.
+func fetch(client *http.Client, url, file, sha256sum string, options []downloadOption) error {
+	_, err := fetchWithETag(client, url, file, sha256sum, "", options)
+	return err
+}
+
+// Like fetch but if etag is given, only fetch url if the server's ETag
+// is different, returning errNotModified if it isn't.  Returns the ETag
+// of the fetched file.
+func fetchWithETag(client *http.Client, url, file, sha256sum, etag string, options []downloadOption) (string, error) {
+	var (
+		err     error
+		newETag string
+	)
+	for attempt := 1; attempt <= fetchAttempts; attempt++ {
+		if attempt > 1 {
+			log.Warnf("Download of {info:%s} failed: {warning:%s}, retrying", url, err)
+			time.Sleep(fetchRetryWait)
+		}
+		newETag, err = fetchOnce(client, url, file, etag, options)
+		if err == nil {
+			err = verify(file, sha256sum)
+			if err != nil {
+				// a corrupt file can't be resumed, so start over
+				_ = os.Remove(file)
+			}
+		}
+		var se *statusError
+		if err == nil || errors.Is(err, errNotModified) || errors.As(err, &se) {
+			break
+		}
+	}
+	if err != nil {
+		_ = os.Remove(file)
+	}
+	return newETag, err
+}
+
+func fetchOnce(client *http.Client, url, file, etag string, options []downloadOption) (string, error) {
+	var offset int64
+	if fi, err := os.Stat(file); err == nil {
+		offset = fi.Size()
+	}
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	for _, opt := range options {
+		if err = opt(req); err != nil {
+			return "", err
+		}
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	} else if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var (
+		w    *os.File
+		size int64 = -1
+	)
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		log.Infof("Resuming download at {info:%d} bytes", offset)
+		w, err = os.OpenFile(file, os.O_WRONLY|os.O_APPEND, 0666)
+		size = getContentRangeSize(resp.Header.Get("Content-Range"))
+	case http.StatusOK:
+		w, err = os.Create(file)
+		size = resp.ContentLength
+	case http.StatusNotModified:
+		return etag, errNotModified
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the partial file is bogus, start again
+		_ = os.Remove(file)
+		return "", fmt.Errorf("%s could not resume download", url)
+	default:
+		return "", &statusError{url: url, statusCode: resp.StatusCode}
+	}
+	if err != nil {
+		return "", err
+	}
+	defer w.Close()
+	var dst io.Writer = w
+	if p := newProgress(path.Base(req.URL.Path), offset, size); p != nil {
+		dst = io.MultiWriter(w, p)
+		defer p.finish()
+	}
+	if _, err := io.Copy(dst, resp.Body); err != nil {
+		return "", err
+	}
+	if size >= 0 {
+		fi, err := w.Stat()
+		if err != nil {
+			return "", err
+		}
+		if fi.Size() != size {
+			return "", fmt.Errorf("%s is incomplete, expected %d bytes but got %d", url, size, fi.Size())
+		}
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+// Returns the total size from a Content-Range header, or -1 if unknown
+func getContentRangeSize(contentRange string) int64 {
+	slash := strings.LastIndexByte(contentRange, '/')
+	if slash < 0 {
+		return -1
+	}
+	size, err := strconv.ParseInt(contentRange[slash+1:], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return size
+}
+
+func verify(file, sha256sum string) error {
+	if sha256sum != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+		if actual := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(actual, sha256sum) {
+			return fmt.Errorf("checksum of %s is %s but expected %s", file, actual, sha256sum)
+		}
+	}
+	if strings.HasSuffix(file, ".zip") {
+		// a truncated zip is missing its central directory
+		z, err := zip.OpenReader(file)
+		if err != nil {
+			return fmt.Errorf("%s is not a valid zip file: %w", file, err)
+		}
+		_ = z.Close()
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchResume(t *testing.T) {
+	assert := assert.New(t)
+	fetchRetryWait = 0
+	data, err := os.ReadFile("testdata/hello.zip")
+	assert.NoError(err)
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rng := r.Header.Get("Range")
+		ranges = append(ranges, rng)
+		if rng == "" {
+			// claim the full length but only send half
+			w.Header().Set("Content-Length", fmt.Sprintf("%d", len(data)))
+			_, _ = w.Write(data[0 : len(data)/2])
+			return
+		}
+		var start int
+		_, _ = fmt.Sscanf(rng, "bytes=%d-", &start)
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(data)-1, len(data)))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write(data[start:])
+	}))
+	defer server.Close()
+	file := filepath.Join(t.TempDir(), "hello.zip")
+	assert.NoError(fetch(server.Client(), server.URL+"/hello.zip", file, "", nil))
+	assert.Equal([]string{"", fmt.Sprintf("bytes=%d-", len(data)/2)}, ranges)
+	d, err := os.ReadFile(file)
+	assert.NoError(err)
+	assert.Equal(data, d)
+}
+
+func TestFetchCorrupt(t *testing.T) {
+	assert := assert.New(t)
+	fetchRetryWait = 0
+	count := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		_, _ = w.Write([]byte("not a zip"))
+	}))
+	defer server.Close()
+	file := filepath.Join(t.TempDir(), "hello.zip")
+	assert.Error(fetch(server.Client(), server.URL+"/hello.zip", file, "", nil))
+	assert.Equal(fetchAttempts, count)
+	assert.NoFileExists(file)
+	assert.Error(fetch(server.Client(), server.URL+"/x", file+".tar", "0000", nil))
+}
+
+func TestFetchNotFound(t *testing.T) {
+	assert := assert.New(t)
+	count := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	err := fetch(server.Client(), server.URL+"/hello.zip", filepath.Join(t.TempDir(), "x.zip"), "", nil)
+	assert.Error(err)
+	assert.Equal(1, count)
+}
+
+func TestFetchProgress(t *testing.T) {
+	assert := assert.New(t)
+	data, err := os.ReadFile("testdata/hello.zip")
+	assert.NoError(err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+	out := &bytes.Buffer{}
+	progressOutput = out
+	defer func() { progressOutput = nil }()
+	assert.NoError(fetch(server.Client(), server.URL+"/hello.zip", filepath.Join(t.TempDir(), "hello.zip"), "", nil))
+	assert.Contains(out.String(), fmt.Sprintf("Downloading hello.zip %d B / %d B (100%%)", len(data), len(data)))
+	assert.Equal("1.5 KiB", formatSize(1536))
+	assert.Equal("2.0 MiB", formatSize(2<<20))
+}
+
+func TestIsUnavailable(t *testing.T) {
+	assert := assert.New(t)
+	fetchRetryWait = 0
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status == http.StatusOK {
+			_, _ = w.Write([]byte("tampered"))
+			return
+		}
+		w.WriteHeader(status)
+	}))
+	file := filepath.Join(t.TempDir(), "x.tar")
+	assert.True(IsUnavailable(fetch(server.Client(), server.URL+"/x.tar", file, "", nil)))
+	status = http.StatusNotFound
+	assert.False(IsUnavailable(fetch(server.Client(), server.URL+"/x.tar", file, "", nil)))
+	status = http.StatusOK
+	err := fetch(server.Client(), server.URL+"/x.tar", file, "0000", nil)
+	assert.Error(err)
+	assert.False(IsUnavailable(err))
+	server.Close()
+	assert.True(IsUnavailable(fetch(server.Client(), server.URL+"/x.tar", file, "", nil)))
+}
@@ -38,7 +38,7 @@ func parseGithubRepo(url string) (string, string) {
 }
 
 func getGithubReleaseAsset(owner, repo, tag string, releaseMatcher GithubReleaseMatcher) (*github.RepositoryRelease, *github.ReleaseAsset, error) {
-	client := github.NewClient(nil)
+	client := github.NewClient(GetHTTPClient())
 	var release *github.RepositoryRelease
 	var err error
 	ctx, cf := context.WithTimeout(context.Background(), 10*time.Second)
@@ -0,0 +1,95 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+var (
+	progressInterval = 250 * time.Millisecond
+
+	// If set, progress is always written here (unless --quiet)
+	progressOutput io.Writer
+)
+
+// Reports the progress of a download on a single line that's
+// rewritten as bytes arrive.
+type progress struct {
+	w     io.Writer
+	name  string
+	done  int64
+	total int64
+	last  time.Time
+}
+
+// Returns a progress reporter for a download of total bytes (or -1 if
+// unknown) that's starting at offset, or nil if progress shouldn't be
+// shown.  Progress is only shown on a terminal, and not with --quiet.
+func newProgress(name string, offset, total int64) *progress {
+	if log.Level < log.Info {
+		return nil
+	}
+	w := progressOutput
+	if w == nil {
+		if !isatty.IsTerminal(os.Stderr.Fd()) {
+			return nil
+		}
+		w = os.Stderr
+	}
+	return &progress{w: w, name: name, done: offset, total: total}
+}
+
+func (p *progress) Write(b []byte) (int, error) {
+	p.done += int64(len(b))
+	if now := time.Now(); now.Sub(p.last) >= progressInterval {
+		p.last = now
+		p.print()
+	}
+	return len(b), nil
+}
+
+func (p *progress) print() {
+	if p.total > 0 {
+		fmt.Fprintf(p.w, "\rDownloading %s %s / %s (%d%%)  ", p.name,
+			formatSize(p.done), formatSize(p.total), p.done*100/p.total)
+	} else {
+		fmt.Fprintf(p.w, "\rDownloading %s %s  ", p.name, formatSize(p.done))
+	}
+}
+
+func (p *progress) finish() {
+	p.print()
+	fmt.Fprintln(p.w)
+}
+
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,230 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Support for downloading modules from a terraform registry, see
+// https://www.terraform.io/internals/module-registry-protocol
+
+const defaultRegistryHost = "registry.terraform.io"
+
+type moduleSource struct {
+	host string
+	// namespace/name/provider
+	path   string
+	subdir string
+}
+
+type moduleDownload struct {
+	url         string
+	archiveType string
+	subdir      string
+}
+
+// Parse a registry module source of the form [host/]namespace/name/provider[//subdir]
+func parseModuleSource(source string) (*moduleSource, error) {
+	ms := &moduleSource{}
+	s := source
+	if i := strings.Index(s, "//"); i >= 0 {
+		ms.subdir = s[i+2:]
+		s = s[:i]
+	}
+	parts := strings.Split(s, "/")
+	switch len(parts) {
+	case 3:
+		ms.host = defaultRegistryHost
+	case 4:
+		ms.host = parts[0]
+		parts = parts[1:]
+	default:
+		return nil, fmt.Errorf("invalid module source %s, expecting [host/]namespace/name/provider", source)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("invalid module source %s, expecting [host/]namespace/name/provider", source)
+		}
+	}
+	ms.path = strings.Join(parts, "/")
+	return ms, nil
+}
+
+func (ms *moduleSource) getName() string {
+	return "tf-module-" + strings.ReplaceAll(ms.host+"/"+ms.path, "/", "-")
+}
+
+// Returns the token for a registry host from the environment in the same
+// way that terraform does i.e. TF_TOKEN_<host> with dots replaced by
+// underscores and dashes by double underscores.
+func getRegistryToken(getenv func(string) string, host string) string {
+	name := strings.ReplaceAll(host, "-", "__")
+	name = strings.ReplaceAll(name, ".", "_")
+	return getenv("TF_TOKEN_" + name)
+}
+
+func registryRequest(client *http.Client, u, token string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		resp.Body.Close()
+		return nil, &statusError{url: u, statusCode: resp.StatusCode}
+	}
+	return resp, nil
+}
+
+// Find the modules API of a registry with service discovery
+func getModulesURL(client *http.Client, host, token string) (*url.URL, error) {
+	discovery := fmt.Sprintf("https://%s/.well-known/terraform.json", host)
+	resp, err := registryRequest(client, discovery, token)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var services map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&services); err != nil {
+		return nil, fmt.Errorf("invalid service discovery document from %s: %w", host, err)
+	}
+	modules, ok := services["modules.v1"].(string)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a terraform module registry", host)
+	}
+	base, _ := url.Parse(discovery)
+	u, err := base.Parse(modules)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return u, nil
+}
+
+// Ask the registry where the source of a module version can be downloaded from
+func getModuleDownload(client *http.Client, ms *moduleSource, version, token string) (*moduleDownload, error) {
+	modules, err := getModulesURL(client, ms.host, token)
+	if err != nil {
+		return nil, err
+	}
+	u, err := modules.Parse(fmt.Sprintf("%s/%s/download", ms.path, version))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := registryRequest(client, u.String(), token)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	location := resp.Header.Get("X-Terraform-Get")
+	if location == "" {
+		return nil, fmt.Errorf("the registry did not return a download location for %s %s", ms.path, version)
+	}
+	md, err := parseModuleLocation(u, location)
+	if err != nil {
+		return nil, err
+	}
+	md.subdir = filepath.Join(md.subdir, ms.subdir)
+	return md, nil
+}
+
+// Turn the location returned by the registry into something that can be
+// fetched.  The location is in go-getter format, of which we support
+// http(s) archives and github repositories.
+func parseModuleLocation(base *url.URL, location string) (*moduleDownload, error) {
+	md := &moduleDownload{}
+	getter := ""
+	if i := strings.Index(location, "::"); i >= 0 {
+		getter = location[:i]
+		location = location[i+2:]
+	}
+	if getter == "" && strings.HasPrefix(location, "github.com/") {
+		// go-getter's shorthand for a github repository
+		getter = "git"
+		location = "https://" + location
+	}
+	// a subdirectory within the source is separated by a double slash
+	// that isn't part of the scheme
+	start := 0
+	if i := strings.Index(location, "://"); i >= 0 {
+		start = i + 3
+	}
+	if i := strings.Index(location[start:], "//"); i >= 0 {
+		rest := location[start+i+2:]
+		location = location[:start+i]
+		if q := strings.IndexByte(rest, '?'); q >= 0 {
+			location += rest[q:]
+			rest = rest[:q]
+		}
+		md.subdir = rest
+	}
+	u, err := base.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	switch getter {
+	case "git", "github":
+		// fetch an archive of the repository from github rather than cloning it
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if u.Host != "github.com" || len(parts) != 2 {
+			return nil, fmt.Errorf("unsupported git module location %s, only github repositories are supported", location)
+		}
+		ref := query.Get("ref")
+		if ref == "" {
+			ref = "HEAD"
+		}
+		md.url = fmt.Sprintf("https://github.com/%s/%s/archive/%s.tar.gz", parts[0], strings.TrimSuffix(parts[1], ".git"), ref)
+		md.archiveType = "tar.gz"
+		return md, nil
+	case "", "http", "https":
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("unsupported module location %s", location)
+		}
+	default:
+		return nil, fmt.Errorf("modules with %s sources are not supported", getter)
+	}
+	md.archiveType = query.Get("archive")
+	query.Del("archive")
+	u.RawQuery = query.Encode()
+	md.url = u.String()
+	return md, nil
+}
+
+// Returns the directory that contains a downloaded terraform module
+func (d *Download) GetModuleDir() string {
+	dir := d.Dir
+	// archives of a repository put their content in a single dir
+	entries, err := os.ReadDir(dir)
+	if err == nil && len(entries) == 1 && entries[0].IsDir() {
+		dir = filepath.Join(dir, entries[0].Name())
+	}
+	return filepath.Join(dir, d.Subdir)
+}
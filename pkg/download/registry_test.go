@@ -0,0 +1,113 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseModuleSource(t *testing.T) {
+	assert := assert.New(t)
+	ms, err := parseModuleSource("hashicorp/consul/aws")
+	if assert.NoError(err) {
+		assert.Equal(&moduleSource{host: "registry.terraform.io", path: "hashicorp/consul/aws"}, ms)
+		assert.Equal("tf-module-registry.terraform.io-hashicorp-consul-aws", ms.getName())
+	}
+	ms, err = parseModuleSource("tf.example.com/acme/network/aws//modules/vpc")
+	if assert.NoError(err) {
+		assert.Equal(&moduleSource{host: "tf.example.com", path: "acme/network/aws", subdir: "modules/vpc"}, ms)
+	}
+	for _, source := range []string{"acme/network", "a/b/c/d/e", "acme//aws"} {
+		_, err = parseModuleSource(source)
+		assert.Error(err, source)
+	}
+}
+
+func TestGetRegistryToken(t *testing.T) {
+	getenv := func(name string) string {
+		if name == "TF_TOKEN_tf__registry_example_com" {
+			return "xyzzy"
+		}
+		return ""
+	}
+	assert.Equal(t, "xyzzy", getRegistryToken(getenv, "tf-registry.example.com"))
+	assert.Equal(t, "", getRegistryToken(getenv, "registry.terraform.io"))
+}
+
+func TestParseModuleLocation(t *testing.T) {
+	assert := assert.New(t)
+	base, _ := url.Parse("https://tf.example.com/v1/modules/acme/network/aws/1.0.0/download")
+	var testCases = []struct {
+		location, url, archiveType, subdir string
+	}{
+		{"https://cdn.example.com/network-1.0.0.tar.gz", "https://cdn.example.com/network-1.0.0.tar.gz", "", ""},
+		{"/archives/network?archive=zip", "https://tf.example.com/archives/network", "zip", ""},
+		{"https://cdn.example.com/network.tgz//modules/vpc?archive=tgz", "https://cdn.example.com/network.tgz", "tgz", "modules/vpc"},
+		{"git::https://github.com/acme/terraform-aws-network.git?ref=v1.0.0", "https://github.com/acme/terraform-aws-network/archive/v1.0.0.tar.gz", "tar.gz", ""},
+		{"github.com/acme/terraform-aws-network//vpc?ref=v1.0.0", "https://github.com/acme/terraform-aws-network/archive/v1.0.0.tar.gz", "tar.gz", "vpc"},
+	}
+	for _, tc := range testCases {
+		md, err := parseModuleLocation(base, tc.location)
+		if assert.NoError(err, tc.location) {
+			assert.Equal(&moduleDownload{url: tc.url, archiveType: tc.archiveType, subdir: tc.subdir}, md, tc.location)
+		}
+	}
+	for _, location := range []string{"git::https://gitlab.com/acme/network.git", "s3::https://s3.amazonaws.com/acme/network.zip"} {
+		_, err := parseModuleLocation(base, location)
+		assert.Error(err, location)
+	}
+}
+
+func TestInstallTerraformModule(t *testing.T) {
+	assert := assert.New(t)
+	setupHTTP()
+	defer httpmock.DeactivateAndReset()
+	t.Setenv("TF_TOKEN_tf_example_com", "foo")
+	authorized := func(r httpmock.Responder) httpmock.Responder {
+		return func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("Authorization") != "Bearer foo" {
+				return httpmock.NewStringResponse(401, "Denied"), nil
+			}
+			return r(req)
+		}
+	}
+	httpmock.RegisterResponder("GET", "https://tf.example.com/.well-known/terraform.json",
+		authorized(httpmock.NewStringResponder(200, `{"modules.v1": "/v1/modules/"}`)))
+	httpmock.RegisterResponder("GET", "https://tf.example.com/v1/modules/acme/hello/aws/1.0.0/download",
+		authorized(func(req *http.Request) (*http.Response, error) {
+			resp := httpmock.NewStringResponse(204, "")
+			resp.Header.Set("X-Terraform-Get", "/archives/hello?archive=tar.gz")
+			return resp, nil
+		}))
+	dat, err := os.ReadFile(filepath.Join("testdata", "hello.tar.gz"))
+	assert.NoError(err)
+	httpmock.RegisterResponder("GET", "https://tf.example.com/archives/hello",
+		authorized(httpmock.NewBytesResponder(200, dat)))
+	m := setupManager()
+	_, err = m.Install(&Spec{TerraformModule: "tf.example.com/acme/hello/aws"})
+	assert.Error(err)
+	d, err := m.Install(&Spec{TerraformModule: "tf.example.com/acme/hello/aws", RequestedVersion: "1.0.0"})
+	if assert.NoError(err) {
+		assert.Equal("tf-module-tf.example.com-acme-hello-aws", d.Name)
+		assert.FileExists(filepath.Join(d.GetModuleDir(), "hello.txt"))
+	}
+}
@@ -14,6 +14,21 @@
 
 package exit
 
+import "errors"
+
+// The exit codes of the CLI
+const (
+	// The command failed
+	ErrorCode = 1
+	// The command ran but found problems, e.g. findings that exceed
+	// the fail thresholds
+	FailureCode = 2
+	// The command requires docker but docker is not available
+	DockerErrorCode = 3
+	// The scan produced no findings and --require-findings was given
+	NoFindingsCode = 4
+)
+
 // Exit code and message.  The root command will look at these and
 // log the error and exit with the code when a command completes
 var (
@@ -30,3 +45,30 @@ func AddFunc(f func()) {
 		}
 	}
 }
+
+// An error that causes the CLI to exit with a specific code
+type Error struct {
+	Code int
+	Err  error
+}
+
+func WithCode(code int, err error) error {
+	return &Error{Code: code, Err: err}
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Returns the code the CLI should exit with when a command returns err
+func CodeOf(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ErrorCode
+}
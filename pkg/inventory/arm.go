@@ -0,0 +1,44 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"strings"
+)
+
+type armDetector int
+
+var _ FileDetector = armDetector(0)
+
+func (armDetector) DetectFileName(m *Manifest, path string) ContentDetector {
+	switch {
+	case strings.HasSuffix(path, ".bicep"):
+		m.BicepFiles.Add(path)
+	case strings.HasSuffix(path, ".json"):
+		return armDetector(0)
+	}
+	return nil
+}
+
+func (armDetector) DetectContent(m *Manifest, path string, buf []byte) {
+	d := PartialDecodeJSON(buf)
+	// ARM templates declare their schema, e.g.
+	// https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#
+	schema := strings.ToLower(d["$schema"])
+	if strings.Contains(schema, "schema.management.azure.com") &&
+		strings.Contains(schema, "deploymenttemplate.json") {
+		m.ARMTemplates.Add(path)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestARMDetector(t *testing.T) {
+	assert := assert.New(t)
+	var testCases = []struct {
+		name, content string
+		match         bool
+	}{
+		{"azuredeploy.json", `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "contentVersion": "1.0.0.0"`, true},
+		{"sub.json", `{"$schema": "https://schema.management.azure.com/schemas/2018-05-01/subscriptionDeploymentTemplate.json#"}`, true},
+		{"params.json", `{"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentParameters.json#"}`, false},
+		{"package.json", `{"name": "foo"}`, false},
+	}
+	d := armDetector(0)
+	for _, tc := range testCases {
+		m := &Manifest{}
+		assert.NotNil(d.DetectFileName(m, tc.name))
+		d.DetectContent(m, tc.name, []byte(tc.content))
+		if tc.match {
+			assert.Equal([]string{tc.name}, m.ARMTemplates.Values(), tc.name)
+		} else {
+			assert.Equal(0, m.ARMTemplates.Len(), tc.name)
+		}
+	}
+	m := &Manifest{}
+	assert.Nil(d.DetectFileName(m, "main.bicep"))
+	assert.Equal([]string{"main.bicep"}, m.BicepFiles.Values())
+}
@@ -16,6 +16,7 @@ package inventory
 
 import (
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
@@ -24,15 +25,43 @@ type dockerDetector int
 var _ FileDetector = dockerDetector(0)
 
 func (d dockerDetector) DetectFileName(m *Manifest, path string) ContentDetector {
-	base := strings.ToLower(filepath.Base(path))
-	if base == "dockerfile" || strings.HasPrefix(base, "dockerfile.") || strings.HasSuffix(base, ".dockerfile") {
+	if IsDockerfileName(path) {
 		return d
 	}
 	return nil
 }
 
+// Returns true if path is named like a Dockerfile e.g. Dockerfile,
+// Dockerfile.dev or app.dockerfile
+func IsDockerfileName(path string) bool {
+	base := strings.ToLower(filepath.Base(path))
+	return base == "dockerfile" || strings.HasPrefix(base, "dockerfile.") || strings.HasSuffix(base, ".dockerfile")
+}
+
 func (dockerDetector) DetectContent(m *Manifest, path string, content []byte) {
 	if strings.Contains(string(content), "FROM ") {
 		m.DockerDirectories.Add(filepath.Dir(path))
 	}
 }
+
+type dockerComposeDetector int
+
+var _ FileDetector = dockerComposeDetector(0)
+
+// docker-compose.yml, compose.yaml, docker-compose.override.yml etc
+var composeFileRegexp = regexp.MustCompile(`^(docker-)?compose(\.[^.]+)?\.ya?ml$`)
+
+var servicesRegexp = regexp.MustCompile(`(?m)^services:`)
+
+func (d dockerComposeDetector) DetectFileName(m *Manifest, path string) ContentDetector {
+	if composeFileRegexp.MatchString(strings.ToLower(filepath.Base(path))) {
+		return d
+	}
+	return nil
+}
+
+func (dockerComposeDetector) DetectContent(m *Manifest, path string, content []byte) {
+	if servicesRegexp.Match(content) {
+		m.DockerComposeFiles.Add(path)
+	}
+}
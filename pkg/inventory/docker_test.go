@@ -28,3 +28,22 @@ func TestDocker(t *testing.T) {
 	assert.ElementsMatch(m.DockerDirectories.Values(),
 		[]string{filepath.FromSlash("d/dot"), filepath.FromSlash("d/simple"), filepath.FromSlash("d/rdot")})
 }
+
+func TestDockerCompose(t *testing.T) {
+	assert := assert.New(t)
+	m := &Manifest{}
+	m.scan("testdata", dockerComposeDetector(0))
+	assert.ElementsMatch(m.DockerComposeFiles.Values(),
+		[]string{filepath.FromSlash("d/compose/docker-compose.yml"), filepath.FromSlash("d/compose/compose.override.yaml")})
+}
+
+func TestDoFiles(t *testing.T) {
+	assert := assert.New(t)
+	m := DoFiles("testdata", []string{
+		filepath.FromSlash("d/dot/Dockerfile.dot"),
+		filepath.FromSlash("d/compose/docker-compose.yml"),
+		filepath.FromSlash("d/compose/compose.notes.txt"),
+	})
+	assert.Equal([]string{filepath.FromSlash("d/dot")}, m.DockerDirectories.Values())
+	assert.Equal([]string{filepath.FromSlash("d/compose/docker-compose.yml")}, m.DockerComposeFiles.Values())
+}
@@ -0,0 +1,64 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+type iamPolicyDetector int
+
+var _ FileDetector = iamPolicyDetector(0)
+
+// The versions of the IAM policy language
+var iamPolicyVersions = []string{"2012-10-17", "2008-10-17"}
+
+func (iamPolicyDetector) DetectFileName(m *Manifest, path string) ContentDetector {
+	if strings.HasSuffix(path, ".json") {
+		return iamPolicyDetector(0)
+	}
+	return nil
+}
+
+// An IAM policy document has a Version and Statement, and optionally an
+// Id, and nothing else.  Other documents that happen to have a Version
+// such as cloudformation templates have other keys.
+func (iamPolicyDetector) DetectContent(m *Manifest, path string, buf []byte) {
+	doc := gjson.ParseBytes(buf)
+	if !doc.IsObject() {
+		return
+	}
+	version := doc.Get("Version")
+	if version.Type != gjson.String || !isIAMPolicyVersion(version.Str) {
+		return
+	}
+	for k := range doc.Map() {
+		if k != "Version" && k != "Statement" && k != "Id" {
+			return
+		}
+	}
+	m.IAMPolicyFiles.Add(path)
+}
+
+func isIAMPolicyVersion(v string) bool {
+	for _, version := range iamPolicyVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inventory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIAMPolicyDetector(t *testing.T) {
+	assert := assert.New(t)
+	var testCases = []struct {
+		name, content string
+		match         bool
+	}{
+		{"policy.json", `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": "s3:GetObject", "Resource": "*"}]}`, true},
+		{"old.json", `{"Id": "x", "Version": "2008-10-17", "Statement": {"Effect": "Deny"`, true},
+		{"template.json", `{"AWSTemplateFormatVersion": "2010-09-09", "Version": "2012-10-17"}`, false},
+		{"package.json", `{"name": "foo", "version": "2012-10-17", "Statement": []}`, false},
+		{"list.json", `[{"Version": "2012-10-17"}]`, false},
+		{"other.json", `{"Version": "1.0", "Statement": []}`, false},
+	}
+	d := iamPolicyDetector(0)
+	for _, tc := range testCases {
+		m := &Manifest{}
+		assert.NotNil(d.DetectFileName(m, tc.name))
+		d.DetectContent(m, tc.name, []byte(tc.content))
+		if tc.match {
+			assert.Equal([]string{tc.name}, m.IAMPolicyFiles.Values(), tc.name)
+		} else {
+			assert.Equal(0, m.IAMPolicyFiles.Len(), tc.name)
+		}
+	}
+	assert.Nil(d.DetectFileName(&Manifest{}, "policy.yaml"))
+}
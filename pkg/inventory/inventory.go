@@ -28,10 +28,14 @@ type Manifest struct {
 	TerraformRootModules          util.StringSet `json:"terraform_root_modules"`
 	TerraformModules              util.StringSet `json:"terraform_modules"`
 	CloudformationFiles           util.StringSet `json:"cloudformation_files"`
+	ARMTemplates                  util.StringSet `json:"arm_templates"`
+	BicepFiles                    util.StringSet `json:"bicep_files"`
 	HelmCharts                    util.StringSet `json:"helm_charts"`
 	KubernetesManifestDirectories util.StringSet `json:"kubernetes_manifest_directories"`
 	CISystems                     util.StringSet `json:"ci_systems"`
 	DockerDirectories             util.StringSet `json:"docker_directories"`
+	DockerComposeFiles            util.StringSet `json:"docker_compose_files"`
+	IAMPolicyFiles                util.StringSet `json:"iam_policy_files"`
 	GODirectories                 util.StringSet `json:"go_directories"`
 	PythonDirectories             util.StringSet `json:"python_directories"`
 	NodeDirectories               util.StringSet `json:"node_directories"`
@@ -88,34 +92,43 @@ func (m *Manifest) scan(root string, detectors ...interface{}) {
 			if filepath.IsAbs(relpath) {
 				relpath, _ = filepath.Rel(root, relpath)
 			}
-			var cds []ContentDetector
 			if isdir {
 				for _, dd := range dirDetectors {
 					dd.DetectDirName(m, relpath)
 				}
 			} else {
-				for _, fd := range fileDetectors {
-					if cd := fd.DetectFileName(m, relpath); cd != nil {
-						cds = append(cds, cd)
-					}
-				}
-			}
-			if len(cds) > 0 {
-				// read the first 4k of the file
-				n, err := readFileStart(path, buf)
-				if err != nil && !errors.Is(err, io.EOF) {
-					log.Warnf("Could not read {info:%s}: {warning:%s}", path, err)
-					return nil
-				}
-				if n > 0 {
-					for _, d := range cds {
-						d.DetectContent(m, relpath, buf[0:n])
-					}
-				}
+				m.detectFile(fileDetectors, path, relpath, buf)
 			}
 		}
 		return nil
 	})
+	m.finalize(detectors)
+}
+
+// Run the file detectors on a single file
+func (m *Manifest) detectFile(fileDetectors []FileDetector, path, relpath string, buf []byte) {
+	var cds []ContentDetector
+	for _, fd := range fileDetectors {
+		if cd := fd.DetectFileName(m, relpath); cd != nil {
+			cds = append(cds, cd)
+		}
+	}
+	if len(cds) > 0 {
+		// read the first 4k of the file
+		n, err := readFileStart(path, buf)
+		if err != nil && !errors.Is(err, io.EOF) {
+			log.Warnf("Could not read {info:%s}: {warning:%s}", path, err)
+			return
+		}
+		if n > 0 {
+			for _, d := range cds {
+				d.DetectContent(m, relpath, buf[0:n])
+			}
+		}
+	}
+}
+
+func (m *Manifest) finalize(detectors []interface{}) {
 	for _, d := range detectors {
 		if fd, ok := d.(FinalizeDetector); ok {
 			fd.FinalizeDetection(m)
@@ -132,23 +145,45 @@ func readFileStart(path string, buf []byte) (int, error) {
 	return f.Read(buf)
 }
 
+func getDetectors() []interface{} {
+	return []interface{}{
+		cloudformationDetector(0),
+		armDetector(0),
+		kubernetesDetector(0),
+		cidetector(0),
+		dockerDetector(0),
+		dockerComposeDetector(0),
+		iamPolicyDetector(0),
+		&terraformDetector{},
+		goDetector(),
+		pythonDetector(),
+		javaAntMavenDetector(),
+		javaGradleDetector(),
+		nodeDetector(),
+		rubyDetector(),
+		cdkDetector(),
+	}
+}
+
 func Do(root string) *Manifest {
 	return cache.Get(root, func(dir string) interface{} {
 		m := &Manifest{}
-		m.scan(root,
-			cloudformationDetector(0),
-			kubernetesDetector(0),
-			cidetector(0),
-			dockerDetector(0),
-			&terraformDetector{},
-			goDetector(),
-			pythonDetector(),
-			javaAntMavenDetector(),
-			javaGradleDetector(),
-			nodeDetector(),
-			rubyDetector(),
-			cdkDetector(),
-		)
+		m.scan(root, getDetectors()...)
 		return m
 	}).(*Manifest)
 }
+
+// Returns the manifest of just the files (relative to root), without
+// scanning the rest of root.  Directories aren't detected.
+func DoFiles(root string, files []string) *Manifest {
+	m := &Manifest{}
+	buf := make([]byte, 4096)
+	root, _ = filepath.Abs(root)
+	detectors := getDetectors()
+	fileDetectors, _ := m.getDetectors(detectors)
+	for _, file := range files {
+		m.detectFile(fileDetectors, filepath.Join(root, file), file, buf)
+	}
+	m.finalize(detectors)
+	return m
+}
@@ -15,6 +15,7 @@
 package log
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/fatih/color"
@@ -25,42 +26,99 @@ import (
 
 var (
 	debug      bool
+	verbose    bool
 	quiet      bool
+	colorMode  string
+	noColor    bool
 	forceColor bool
 	logStdout  bool
 	logStderr  bool
 )
 
+func init() {
+	// honor NO_COLOR even for anything logged before Configure() is called
+	if os.Getenv("NO_COLOR") != "" {
+		color.NoColor = true
+	}
+}
+
 func AddFlags(flags *pflag.FlagSet) {
-	flags.BoolVar(&debug, "debug", false, "Run with debug logging")
-	flags.BoolVar(&quiet, "quiet", false, "Run with no logging")
-	flags.BoolVar(&color.NoColor, "no-color", false, "Disable color output")
-	flags.BoolVar(&forceColor, "force-color", false, "Enable color output")
+	flags.BoolVarP(&verbose, "verbose", "v", false, "Run with debug logging, including the full command line of tools that are run")
+	flags.BoolVar(&debug, "debug", false, "Run with debug logging, same as --verbose")
+	flags.BoolVar(&quiet, "quiet", false, "Only log errors")
+	flags.StringVar(&colorMode, "color", "auto", "Colorize log output, one of `auto`, `always`, or `never`.  The NO_COLOR environment variable disables color in auto mode.")
+	flags.BoolVar(&noColor, "no-color", false, "Disable color output, same as --color never")
+	flags.BoolVar(&forceColor, "force-color", false, "Enable color output, same as --color always")
 	flags.BoolVar(&logStdout, "log-stdout", false, "Force the CLI to log to stdout")
 	flags.BoolVar(&logStderr, "log-stderr", false, "Force the CLI to log to stderr")
+	_ = flags.MarkHidden("no-color")
+	_ = flags.MarkHidden("force-color")
 }
 
-func Configure() {
-	if forceColor {
-		color.NoColor = false
-	}
-	if quiet {
-		Level = Error
-	}
-	if debug {
-		Level = Debug
+func Configure() error {
+	level, err := getLevel(quiet, verbose || debug)
+	if err != nil {
+		return err
 	}
+	Level = level
+	out := os.Stderr
 	switch {
 	case logStdout:
-		return
+		out = os.Stdout
 	case os.Getenv("GITHUB_ACTIONS") == "true":
 		// github actions doesn't process interleaved stdout/stderr correctly
 		// so if we're running there log and stdout is a terminal, then log to stdout
 		if isatty.IsTerminal(os.Stdout.Fd()) {
-			return
+			out = os.Stdout
 		}
 	case logStderr:
 	default:
 	}
-	color.Output = colorable.NewColorableStderr()
+	if out == os.Stderr {
+		color.Output = colorable.NewColorableStderr()
+	}
+	mode := colorMode
+	switch {
+	case noColor:
+		mode = "never"
+	case forceColor:
+		mode = "always"
+	}
+	c, err := useColor(mode, os.Getenv, isatty.IsTerminal(out.Fd()))
+	if err != nil {
+		return err
+	}
+	color.NoColor = !c
+	return nil
+}
+
+// Logging always goes to stderr (unless --log-stdout is given) so the
+// level never affects the output of a command.
+func getLevel(quiet, verbose bool) (int, error) {
+	switch {
+	case quiet && verbose:
+		return Level, fmt.Errorf("--quiet and --verbose cannot be used together")
+	case quiet:
+		return Error, nil
+	case verbose:
+		return Debug, nil
+	default:
+		return Level, nil
+	}
+}
+
+// Color is only used for log output, the printers never colorize
+// their output.  In auto mode color is only ever turned off, so
+// that if color.NoColor has already been set it's respected.
+func useColor(mode string, getenv func(string) string, terminal bool) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto", "":
+		return !color.NoColor && terminal && getenv("NO_COLOR") == "", nil
+	default:
+		return false, fmt.Errorf("invalid --color %s, must be one of auto, always, or never", mode)
+	}
 }
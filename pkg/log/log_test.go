@@ -48,3 +48,53 @@ func TestTemp(t *testing.T) {
 		t.Error(s)
 	}
 }
+
+func TestUseColor(t *testing.T) {
+	noColor := color.NoColor
+	defer func() { color.NoColor = noColor }()
+	color.NoColor = false
+	env := map[string]string{}
+	getenv := func(k string) string { return env[k] }
+	var testCases = []struct {
+		mode     string
+		noColor  string
+		terminal bool
+		color    bool
+	}{
+		{"auto", "", true, true},
+		{"auto", "", false, false},
+		{"auto", "1", true, false},
+		{"always", "1", false, true},
+		{"never", "", true, false},
+	}
+	for _, tc := range testCases {
+		env["NO_COLOR"] = tc.noColor
+		c, err := useColor(tc.mode, getenv, tc.terminal)
+		if err != nil || c != tc.color {
+			t.Error(tc, c, err)
+		}
+	}
+	color.NoColor = true
+	if c, _ := useColor("auto", getenv, true); c {
+		t.Error("auto should respect NoColor")
+	}
+	if _, err := useColor("sometimes", getenv, true); err == nil {
+		t.Error("invalid mode should fail")
+	}
+}
+
+func TestGetLevel(t *testing.T) {
+	defer SetTempLevel(Info).Restore()
+	if l, err := getLevel(true, false); err != nil || l != Error {
+		t.Error(l, err)
+	}
+	if l, err := getLevel(false, true); err != nil || l != Debug {
+		t.Error(l, err)
+	}
+	if l, err := getLevel(false, false); err != nil || l != Info {
+		t.Error(l, err)
+	}
+	if _, err := getLevel(true, true); err == nil {
+		t.Error("--quiet and --verbose should conflict")
+	}
+}
@@ -15,6 +15,7 @@
 package options
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/soluble-ai/soluble-cli/pkg/api"
@@ -27,6 +28,7 @@ type ClientOpts struct {
 	api.Config
 	AuthNotRequired bool
 	DefaultTimeout  int
+	APITokenFile    string
 
 	client       *api.Client
 	unauthClient *api.Client
@@ -56,21 +58,39 @@ func (opts *ClientOpts) GetClientOptionsGroup() *HiddenOptionsGroup {
 			flags.Float64Var(&opts.RetryWaitSeconds, "api-retry-wait", 0,
 				"The initial time in `seconds` to wait between retry attempts, e.g. 0.5 to wait 500 millis")
 			flags.StringSliceVar(&opts.Headers, "api-header", nil, "Set custom headers in the form `name:value` on requests")
-			flags.StringVar(&opts.Organization, "organization", "", "The organization `id` to use.")
+			flags.StringVar(&opts.Organization, "organization", "", "The organization `id` to use.  Can also be set with SOLUBLE_ORG.")
+			flags.StringVar(&opts.Organization, "org", "", "The organization `id` to use, the same as --organization.")
 			flags.StringVar(&opts.APIToken, "api-token", "", "The authentication `token` (read from profile by default)")
+			flags.StringVar(&opts.APITokenFile, "api-token-file", "", "Read the authentication token from `file`.  Can also be set with SOLUBLE_API_TOKEN_FILE.")
 		},
 	}
 }
 
 func (opts *ClientOpts) Register(cmd *cobra.Command) {
 	opts.GetClientOptionsGroup().Register(cmd)
+	AddPreRunE(cmd, func(*cobra.Command, []string) error {
+		return opts.readAPITokenFile()
+	})
+}
+
+// Read the token from --api-token-file, unless --api-token was given
+func (opts *ClientOpts) readAPITokenFile() error {
+	if opts.APIToken != "" || opts.APITokenFile == "" {
+		return nil
+	}
+	token, err := config.ReadAPITokenFile(opts.APITokenFile)
+	if err != nil {
+		return fmt.Errorf("could not read the API token from %s: %w", opts.APITokenFile, err)
+	}
+	opts.APIToken = token
+	return nil
 }
 
 func (opts *ClientOpts) GetAPIClientConfig() *api.Config {
 	cfg := opts.Config
 
 	if cfg.Organization == "" {
-		cfg.Organization = config.Config.Organization
+		cfg.Organization = config.Config.GetOrganization()
 	}
 	if cfg.APIToken == "" {
 		cfg.APIToken = config.Config.GetAPIToken()
@@ -82,7 +102,10 @@ func (opts *ClientOpts) GetAPIClientConfig() *api.Config {
 		cfg.APIServer = "https://api.soluble.cloud"
 	}
 	if !cfg.TLSNoVerify {
-		cfg.TLSNoVerify = config.Config.TLSNoVerify
+		cfg.TLSNoVerify = config.Config.TLSNoVerify || config.InsecureSkipTLSVerify
+	}
+	if cfg.CABundle == "" {
+		cfg.CABundle = config.CABundle
 	}
 	return &cfg
 }
@@ -91,7 +114,15 @@ func (opts *ClientOpts) GetOrganization() string {
 	if opts.Organization != "" {
 		return opts.Organization
 	}
-	return config.Config.Organization
+	return config.Config.GetOrganization()
+}
+
+// Returns an error if there's no organization to send requests to.
+func (opts *ClientOpts) ValidateOrganization() error {
+	if opts.GetOrganization() == "" {
+		return fmt.Errorf("no organization is selected, use --org or SOLUBLE_ORG to choose one (or login again)")
+	}
+	return nil
 }
 
 func (opts *ClientOpts) GetAPIClient() *api.Client {
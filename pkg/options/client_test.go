@@ -0,0 +1,43 @@
+// Copyright 2020 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPITokenFile(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "token")
+	assert.NoError(os.WriteFile(path, []byte("xyzzy\n"), 0600))
+	opts := &ClientOpts{}
+	c := &cobra.Command{RunE: func(*cobra.Command, []string) error { return nil }}
+	opts.Register(c)
+	c.SetArgs([]string{"--api-token-file", path})
+	assert.NoError(c.Execute())
+	assert.Equal("xyzzy", opts.GetAPIClientConfig().APIToken)
+	opts = &ClientOpts{}
+	c = &cobra.Command{RunE: func(*cobra.Command, []string) error { return nil }}
+	opts.Register(c)
+	c.SilenceErrors = true
+	c.SilenceUsage = true
+	c.SetArgs([]string{"--api-token-file", filepath.Join(t.TempDir(), "missing")})
+	assert.Error(c.Execute())
+}
@@ -66,7 +66,7 @@ func (p *PrintOpts) GetPrintOptionsGroup() *HiddenOptionsGroup {
 			flags.StringVar(&p.Template, "print-template", "",
 				"The go `template` to print with.  If the template begins with @, then read the template from a file.")
 			flags.StringVar(&p.OutputFormat, "format", "",
-				"Use this output `format` where format is one of: table, yaml, json, none, csv, template, or value(name).")
+				"Use this output `format` where format is one of: table, yaml, json, ndjson, none, csv, template, or value(name).")
 			flags.BoolVar(&p.NoHeaders, "no-headers", false, "Omit headers when printing tables or csv")
 			flags.StringVar(&p.Filter, "filter", "", "Print results that match a `filter`.")
 			flags.BoolVar(&p.Wide, "wide", false, "Display more columns (table, csv)")
@@ -111,6 +111,11 @@ func (p *PrintOpts) Register(cmd *cobra.Command) {
 	p.outputSource = cmd.OutOrStdout
 }
 
+// Print results to w instead of the command's output
+func (p *PrintOpts) SetOutput(w io.Writer) {
+	p.outputSource = func() io.Writer { return w }
+}
+
 func (p *PrintOpts) GetPrinter() (print.Interface, error) {
 	outputFormat := p.OutputFormat
 	if outputFormat == "" {
@@ -145,6 +150,10 @@ func (p *PrintOpts) GetPrinter() (print.Interface, error) {
 		return &print.NonePrinter{}, nil
 	case "json":
 		return &print.JSONPrinter{}, nil
+	case "ndjson":
+		return &print.NDJSONPrinter{
+			PathSupport: p.getPathSupport(),
+		}, nil
 	case "yaml":
 		return &print.YAMLPrinter{}, nil
 	case "csv":
@@ -242,7 +251,7 @@ func (p *PrintOpts) PrintResult(result *jnode.Node) {
 		exit.Func = func() {
 			log.Errorf("Exiting with error code because there are {danger:%d} results", n)
 		}
-		exit.Code = 2
+		exit.Code = exit.FailureCode
 	}
 }
 
@@ -0,0 +1,48 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package print
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/soluble-ai/go-jnode"
+)
+
+// NDJSONPrinter prints one compact JSON object per line.  If there's
+// no path then an array result is printed one element per line, and
+// anything else is printed as a single line.
+type NDJSONPrinter struct {
+	PathSupport
+}
+
+var _ Interface = &NDJSONPrinter{}
+
+func (p *NDJSONPrinter) PrintResult(w io.Writer, result *jnode.Node) int {
+	var rows []*jnode.Node
+	switch {
+	case p.Path != nil:
+		rows = p.GetRows(result)
+	case result.IsArray():
+		rows = result.Elements()
+	default:
+		rows = []*jnode.Node{result}
+	}
+	enc := json.NewEncoder(w)
+	for _, row := range rows {
+		_ = enc.Encode(row)
+	}
+	return len(rows)
+}
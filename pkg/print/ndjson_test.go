@@ -0,0 +1,39 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package print
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNDJSON(t *testing.T) {
+	assert := assert.New(t)
+	n := jnode.NewObjectNode()
+	a := n.PutArray("data")
+	a.AppendObject().Put("a", 1)
+	a.AppendObject().Put("a", 2)
+	w := &bytes.Buffer{}
+	p := &NDJSONPrinter{PathSupport: PathSupport{Path: []string{"data"}}}
+	assert.Equal(2, p.PrintResult(w, n))
+	assert.Equal("{\"a\":1}\n{\"a\":2}\n", w.String())
+	w.Reset()
+	p = &NDJSONPrinter{PathSupport: PathSupport{Path: []string{}}}
+	assert.Equal(0, p.PrintResult(w, jnode.NewArrayNode()))
+	assert.Equal("", w.String())
+}
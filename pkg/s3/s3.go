@@ -0,0 +1,152 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package s3 writes objects to S3 using the standard AWS credential
+// resolution (environment, shared config, instance roles, etc.)
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+type Client struct {
+	Credentials aws.Credentials
+	// The region of buckets whose region can't be found
+	Region string
+	// Endpoint overrides the S3 endpoint, it's used for testing
+	Endpoint   string
+	HTTPClient *http.Client
+
+	lock          sync.Mutex
+	bucketRegions map[string]string
+}
+
+// Create a client, failing if AWS credentials can't be found.
+func NewClient(ctx context.Context) (*Client, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Credentials == nil {
+		return nil, fmt.Errorf("no AWS credentials are available")
+	}
+	creds, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get AWS credentials: %w", err)
+	}
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &Client{
+		Credentials: creds,
+		Region:      region,
+		HTTPClient:  http.DefaultClient,
+	}, nil
+}
+
+// Parse a URL in the form s3://bucket/prefix and return the bucket and prefix.
+func ParseURL(s string) (bucket string, prefix string, err error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Scheme != "s3" || u.Host == "" {
+		return "", "", fmt.Errorf("%s is not an s3://bucket/prefix URL", s)
+	}
+	return u.Host, strings.Trim(u.Path, "/"), nil
+}
+
+// Returns the region of bucket, which S3 returns in the x-amz-bucket-region
+// header of a HeadBucket request even if the request isn't authorized (or
+// is sent to the wrong region.)  If the region can't be found the client's
+// region is used.
+func (c *Client) GetBucketRegion(ctx context.Context, bucket string) string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if region, ok := c.bucketRegions[bucket]; ok {
+		return region
+	}
+	region := c.Region
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.s3.amazonaws.com", bucket)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, endpoint, nil)
+	if err == nil {
+		var resp *http.Response
+		resp, err = c.HTTPClient.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if r := resp.Header.Get("X-Amz-Bucket-Region"); r != "" {
+				region = r
+			}
+		}
+	}
+	if err != nil {
+		log.Debugf("Could not find the region of {info:%s}, using {info:%s} - {warning:%s}", bucket, region, err)
+	}
+	if c.bucketRegions == nil {
+		c.bucketRegions = map[string]string{}
+	}
+	c.bucketRegions[bucket] = region
+	return region
+}
+
+func (c *Client) Put(ctx context.Context, bucket, key string, data []byte) error {
+	region := c.GetBucketRegion(ctx, bucket)
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.s3.%s.amazonaws.com", bucket, region)
+	}
+	u := fmt.Sprintf("%s/%s", endpoint, key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	payloadHash := hex.EncodeToString(sum[:])
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	req.Header.Set("Content-Type", "application/json")
+	if err := v4.NewSigner().SignHTTP(ctx, c.Credentials, req, payloadHash, "s3", region, time.Now()); err != nil {
+		return err
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(resp.Body)
+		log.Errorf("Writing {info:s3://%s/%s} returned {danger:%d}\n{warning:%s}", bucket, key, resp.StatusCode, body)
+		return fmt.Errorf("writing s3://%s/%s returned %d", bucket, key, resp.StatusCode)
+	}
+	log.Infof("Wrote {info:s3://%s/%s}", bucket, key)
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseURL(t *testing.T) {
+	assert := assert.New(t)
+	bucket, prefix, err := ParseURL("s3://my-bucket/scans/today/")
+	assert.NoError(err)
+	assert.Equal("my-bucket", bucket)
+	assert.Equal("scans/today", prefix)
+	_, _, err = ParseURL("https://example.com/foo")
+	assert.Error(err)
+}
+
+func TestPut(t *testing.T) {
+	assert := assert.New(t)
+	var (
+		path  string
+		body  string
+		auth  string
+		heads int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			heads++
+			w.Header().Set("X-Amz-Bucket-Region", "eu-west-1")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		path = r.URL.Path
+		auth = r.Header.Get("Authorization")
+		d, _ := io.ReadAll(r.Body)
+		body = string(d)
+	}))
+	defer server.Close()
+	c := &Client{
+		Credentials: aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+		Region:      "us-west-2",
+		Endpoint:    server.URL,
+		HTTPClient:  server.Client(),
+	}
+	assert.NoError(c.Put(context.Background(), "bucket", "prefix/results.json", []byte(`{}`)))
+	assert.Equal("/prefix/results.json", path)
+	assert.Equal("{}", body)
+	assert.True(strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/"), auth)
+	// the request is signed for the bucket's region, which is only looked up once
+	assert.Contains(auth, "/eu-west-1/s3/aws4_request")
+	assert.NoError(c.Put(context.Background(), "bucket", "prefix/findings.json", []byte(`[]`)))
+	assert.Equal(1, heads)
+	assert.Equal("us-west-2", (&Client{Region: "us-west-2", Endpoint: "http://127.0.0.1:1", HTTPClient: server.Client()}).
+		GetBucketRegion(context.Background(), "bucket"))
+}
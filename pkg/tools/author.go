@@ -0,0 +1,105 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/xcp"
+)
+
+// Removes the findings in dir on lines that git blame says were last
+// changed by someone other than authors (emails or names), and returns
+// the remaining findings and the number removed.  Uncommitted lines and
+// untracked files are attributed to gitUser.  Findings without a line or
+// in files outside of the repository can't be attributed to anyone, so
+// they're always kept.
+func removeOtherAuthorsFindings(repoRoot, dir string, authors []string, gitUser xcp.BlameLine, findings assessments.Findings) (assessments.Findings, int) {
+	var kept assessments.Findings
+	removed := 0
+	for _, f := range findings {
+		repoPath := getFindingRepoPath(repoRoot, dir, f)
+		if repoPath == "" || f.Line <= 0 {
+			kept = append(kept, f)
+			continue
+		}
+		var line xcp.BlameLine
+		if blame, err := xcp.GetBlame(repoRoot, repoPath); err == nil {
+			line = blame.Get(f.Line)
+		} else {
+			log.Debugf("Could not blame {info:%s} - {warning:%s}", repoPath, err)
+		}
+		if isAuthor(line, authors, gitUser) {
+			kept = append(kept, f)
+		} else {
+			removed++
+		}
+	}
+	return kept, removed
+}
+
+func isAuthor(line xcp.BlameLine, authors []string, gitUser xcp.BlameLine) bool {
+	if line.Time.IsZero() {
+		line = gitUser
+	}
+	for _, author := range authors {
+		if strings.EqualFold(author, line.AuthorEmail) || strings.EqualFold(author, line.AuthorName) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *ToolOpts) validateAuthor() error {
+	if o.Mine && o.Author != "" {
+		return fmt.Errorf("--mine and --author cannot be used together")
+	}
+	if o.RepoRoot == "" {
+		return fmt.Errorf("--author and --mine can only be used in a git repository")
+	}
+	o.gitUser.AuthorName, o.gitUser.AuthorEmail = xcp.GetGitUser(o.RepoRoot)
+	if o.Mine {
+		for _, s := range []string{o.gitUser.AuthorEmail, o.gitUser.AuthorName} {
+			if s != "" {
+				o.authors = append(o.authors, s)
+			}
+		}
+		if len(o.authors) == 0 {
+			return fmt.Errorf("--mine requires the git user.email or user.name to be configured")
+		}
+	} else {
+		o.authors = []string{o.Author}
+	}
+	return nil
+}
+
+// Removes the findings of other authors from the local results.  This is
+// done after the upload so the assessment still has every finding.
+func (o *ToolOpts) removeOtherAuthorsFindings(result *Result) {
+	if len(o.authors) == 0 || result.Directory == "" {
+		return
+	}
+	var removed int
+	result.Findings, removed = removeOtherAuthorsFindings(o.RepoRoot, result.Directory, o.authors, o.gitUser, result.Findings)
+	if result.Assessment != nil {
+		result.Assessment.Findings, removed = removeOtherAuthorsFindings(o.RepoRoot, result.Directory, o.authors, o.gitUser, result.Assessment.Findings)
+	}
+	if removed > 0 {
+		log.Infof("Ignoring {info:%d} findings on lines last changed by someone other than {info:%s}", removed, strings.Join(o.authors, " or "))
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoveOtherAuthorsFindings(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	assert := assert.New(t)
+	repo := t.TempDir()
+	git := func(author string, args ...string) {
+		c := exec.Command("git", args...)
+		c.Dir = repo
+		c.Env = append(os.Environ(), "GIT_AUTHOR_NAME="+author, "GIT_AUTHOR_EMAIL="+author+"@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := c.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %s %s", args, err, out)
+		}
+	}
+	git("", "init", "-q")
+	git("", "config", "user.email", "jo@example.com")
+	git("", "config", "user.name", "jo")
+	assert.NoError(os.WriteFile(filepath.Join(repo, "main.tf"), []byte("one\n"), 0600))
+	git("", "add", ".")
+	git("sam", "commit", "-q", "-m", "one")
+	assert.NoError(os.WriteFile(filepath.Join(repo, "main.tf"), []byte("one\ntwo\n"), 0600))
+	git("", "add", ".")
+	git("jo", "commit", "-q", "-m", "two")
+	assert.NoError(os.WriteFile(filepath.Join(repo, "main.tf"), []byte("one\ntwo\nthree\n"), 0600))
+	assert.NoError(os.WriteFile(filepath.Join(repo, "new.tf"), []byte("new\n"), 0600))
+	findings := assessments.Findings{
+		{FilePath: "main.tf", Line: 1},
+		{FilePath: "main.tf", Line: 2},
+		{FilePath: "main.tf", Line: 3},
+		{FilePath: "new.tf", Line: 1},
+		{FilePath: "main.tf"},
+	}
+	o := &ToolOpts{Mine: true, RepoRoot: repo}
+	assert.NoError(o.validateAuthor())
+	assert.Equal([]string{"jo@example.com", "jo"}, o.authors)
+	kept, removed := removeOtherAuthorsFindings(repo, repo, o.authors, o.gitUser, findings)
+	assert.Equal(1, removed)
+	assert.Equal(findings[1:], kept)
+	for _, author := range []string{"jo", "JO@example.com"} {
+		o := &ToolOpts{Author: author, RepoRoot: repo}
+		assert.NoError(o.validateAuthor())
+		kept, removed = removeOtherAuthorsFindings(repo, repo, o.authors, o.gitUser, findings)
+		assert.Equal(1, removed, author)
+		assert.Equal(findings[1:], kept, author)
+	}
+	kept, removed = removeOtherAuthorsFindings(repo, repo, []string{"SAM"}, o.gitUser, findings)
+	assert.Equal(3, removed)
+	assert.Equal(assessments.Findings{findings[0], findings[4]}, kept)
+	assert.Error((&ToolOpts{Mine: true, Author: "sam"}).validateAuthor())
+	assert.Error((&ToolOpts{Author: "sam"}).validateAuthor())
+}
@@ -16,6 +16,7 @@ package autoscan
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/soluble-ai/soluble-cli/pkg/inventory"
@@ -23,7 +24,12 @@ import (
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	cfnpythonlint "github.com/soluble-ai/soluble-cli/pkg/tools/cfn-python-lint"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/checkov"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/compose"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/hadolint"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/iacinventory"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/iampolicy"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/polaris"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/sbom"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/secrets"
 	"github.com/soluble-ai/soluble-cli/pkg/tools/trivy"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
@@ -36,6 +42,8 @@ type Tool struct {
 	Skip             []string
 	ToolPaths        map[string]string
 	Images           []string
+	MergeResults     bool
+	SBOM             bool
 }
 
 var _ tools.Consolidated = &Tool{}
@@ -58,24 +66,36 @@ func (t *Tool) Register(cmd *cobra.Command) {
 	flags.StringToStringVar(&t.ToolPaths, "tool-paths", nil, "Explicitly specify the path to each tool in the form `tool=path`.")
 	flags.StringSliceVar(&t.Images, "image", nil, "Scan these docker images, as in the image-scan command.")
 	flags.BoolVar(&t.NoDocker, "no-docker", false, "Run all docker-based tools locally")
+	flags.BoolVar(&t.MergeResults, "merge-results", false, "Merge the results of the tools for each directory into a single assessment")
+	flags.BoolVar(&t.SBOM, "sbom", false, "Also generate a software bill of materials of the directory with syft")
 }
 
 func (t *Tool) CommandTemplate() *cobra.Command {
 	return &cobra.Command{
-		Use:   "auto-scan",
-		Short: "Find infrastructure-as-code and scan with recommended tools",
+		Use:     "auto-scan",
+		Aliases: []string{"scan"},
+		Short:   "Find infrastructure-as-code and scan with recommended tools",
 		Long: `Find infrastructure-as-code and scan with the following tools:
 
 Cloudformation templates - cfn-python-lint
 Terraform                - checkov
-Kuberentes manifests     - checkov
-Everything               - secrets		
+ARM/Bicep templates      - checkov
+Kuberentes manifests     - checkov, polaris
+Dockerfiles              - hadolint
+docker-compose files     - docker-compose
+IAM policy documents     - iam-policy
+Everything               - secrets, sbom (with --sbom)
+
+Tools are only run if the corresponding files are found.  Use --skip to
+not run particular tools.
 
 In addition, images can be scanned with trivy.
 `,
 		Example: `# To run a tool locally w/o using docker explicitly specify the tool path
-... auto-scan --tool-paths checkov=checkov,cfn-python-lint=cfn-lint`,
-		Hidden: true,
+... auto-scan --tool-paths checkov=checkov,cfn-python-lint=cfn-lint
+
+# To skip tools
+... auto-scan --skip hadolint,polaris`,
 	}
 }
 
@@ -91,7 +111,8 @@ func (t *Tool) RunAll() (tools.Results, error) {
 			Single: &checkov.Tool{
 				DirectoryBasedToolOpts: t.getDirectoryOpts(),
 			},
-			Skip: m.TerraformRootModules.Len() == 0 && m.KubernetesManifestDirectories.Len() == 0,
+			Skip: m.TerraformRootModules.Len() == 0 && m.KubernetesManifestDirectories.Len() == 0 &&
+				m.ARMTemplates.Len() == 0 && m.BicepFiles.Len() == 0,
 		},
 		{
 			Single: &cfnpythonlint.Tool{
@@ -100,11 +121,49 @@ func (t *Tool) RunAll() (tools.Results, error) {
 			},
 			Skip: m.CloudformationFiles.Len() == 0,
 		},
+		{
+			Single: &polaris.Tool{
+				DirectoryBasedToolOpts: t.getDirectoryOpts(),
+			},
+			Skip: m.KubernetesManifestDirectories.Len() == 0,
+		},
 		{
 			Single: &secrets.Tool{
 				DirectoryBasedToolOpts: t.getDirectoryOpts(),
 			},
 		},
+		{
+			Single: &sbom.Tool{
+				DirectoryBasedToolOpts: t.getDirectoryOpts(),
+				Format:                 "cyclonedx",
+			},
+			Skip: !t.SBOM,
+		},
+	}
+	if m.DockerComposeFiles.Len() > 0 {
+		subTools = append(subTools, SubordinateTool{
+			Single: &compose.Tool{
+				DirectoryBasedToolOpts: t.getDirectoryOpts(),
+				Files:                  m.DockerComposeFiles.Values(),
+			},
+		})
+	}
+	if m.IAMPolicyFiles.Len() > 0 {
+		subTools = append(subTools, SubordinateTool{
+			Single: &iampolicy.Tool{
+				DirectoryBasedToolOpts: t.getDirectoryOpts(),
+				Files:                  m.IAMPolicyFiles.Values(),
+			},
+		})
+	}
+	for _, dir := range m.DockerDirectories.Values() {
+		subTools = append(subTools, SubordinateTool{
+			Single: &hadolint.Tool{
+				DirectoryBasedToolOpts: tools.DirectoryBasedToolOpts{
+					Directory: filepath.Join(t.GetDirectory(), dir),
+				},
+			},
+		})
 	}
 	for _, image := range t.Images {
 		subTools = append(subTools, SubordinateTool{
@@ -113,24 +172,38 @@ func (t *Tool) RunAll() (tools.Results, error) {
 			},
 		})
 	}
-	count := 0
 	var (
 		errs    error
 		results []*tools.Result
+		run     []SubordinateTool
+		images  []string
 	)
 	for _, st := range subTools {
 		if st.Skip || util.StringSliceContains(t.Skip, st.Name()) {
 			continue
 		}
-		count++
 		opts := st.GetToolOptions()
 		opts.Tool = st
-		opts.UploadEnabled = t.UploadEnabled
+		// if merging, the merged results are uploaded instead
+		opts.UploadEnabled = t.UploadEnabled && !t.MergeResults
 		opts.ToolPath = t.ToolPaths[st.Name()]
 		opts.NoDocker = t.NoDocker
 		if dopts := st.GetDirectoryBasedToolOptions(); dopts != nil {
 			dopts.Exclude = t.Exclude
 		}
+		if d, ok := st.Single.(tools.HasDockerImage); ok {
+			images = append(images, d.GetDockerImage())
+		}
+		run = append(run, st)
+	}
+	// the tools are run one at a time, but their images can be
+	// pulled at the same time
+	if !t.SkipDockerPull {
+		tools.PullImages(images)
+	}
+	count := len(run)
+	for _, st := range run {
+		opts := st.GetToolOptions()
 		log.Infof("Running {info:%s}", opts.Tool.Name())
 		toolResults, toolErr := opts.RunTool()
 		for _, res := range toolResults {
@@ -143,9 +216,28 @@ func (t *Tool) RunAll() (tools.Results, error) {
 		}
 	}
 	log.Infof("Finished running {primary:%d} tools", count)
+	if t.MergeResults {
+		results = mergeResults(results, t.Name())
+	}
 	return results, errs
 }
 
+func mergeResults(results tools.Results, name string) tools.Results {
+	var dirs []string
+	byDir := map[string]tools.Results{}
+	for _, result := range results {
+		if byDir[result.Directory] == nil {
+			dirs = append(dirs, result.Directory)
+		}
+		byDir[result.Directory] = append(byDir[result.Directory], result)
+	}
+	merged := make(tools.Results, 0, len(dirs))
+	for _, dir := range dirs {
+		merged = append(merged, byDir[dir].Merge(name))
+	}
+	return merged
+}
+
 func (t *Tool) getDirectoryOpts() tools.DirectoryBasedToolOpts {
 	return tools.DirectoryBasedToolOpts{
 		Directory: t.GetDirectory(),
@@ -34,6 +34,14 @@ func (t *Tool) Name() string {
 	return "bandit"
 }
 
+func (t *Tool) Preflight() error {
+	return t.PreflightDocker()
+}
+
+func (t *Tool) Requirements() []string {
+	return []string{tools.RequiresDocker}
+}
+
 func (t *Tool) Run() (*tools.Result, error) {
 	args := []string{
 		"--exit-zero", "-f", "json", "-r", ".",
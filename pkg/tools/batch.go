@@ -0,0 +1,188 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/api"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/xcp"
+)
+
+// A batchWriter uploads findings in batches.  Each batch is tagged
+// with a scan id so the server can tie them together, and the final
+// batch (sent by Close) marks the scan complete.
+//
+// The batches have a phase.  The findings that a tool reports while it's
+// running are uploaded in the "scan" phase as they're found, so that
+// they're not lost if the scan fails.  The processed results are then
+// uploaded in the "results" phase, which has all the findings and
+// replaces the "scan" batches of the same scan id.
+type batchWriter struct {
+	Client *api.Client
+	Org    string
+	Module string
+	Values map[string]string
+	ScanID string
+	Phase  string
+	// Options added to every upload
+	Options []api.Option
+	// In the scan phase, the reported findings are uploaded once there
+	// are FlushCount of them, and every FlushInterval
+	FlushCount    int
+	FlushInterval time.Duration
+
+	lock    sync.Mutex
+	batch   int
+	pending assessments.Findings
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+func newBatchWriter(client *api.Client, org, module string, values map[string]string) *batchWriter {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return &batchWriter{
+		Client: client,
+		Org:    org,
+		Module: module,
+		Values: values,
+		ScanID: hex.EncodeToString(id),
+		Phase:  "results",
+	}
+}
+
+// Start uploading the reported findings every FlushInterval
+func (w *batchWriter) Start() {
+	if w.FlushInterval <= 0 {
+		return
+	}
+	w.stop = make(chan struct{})
+	w.stopped = make(chan struct{})
+	go func() {
+		defer close(w.stopped)
+		ticker := time.NewTicker(w.FlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				w.lock.Lock()
+				w.flushReported()
+				w.lock.Unlock()
+			}
+		}
+	}()
+}
+
+// Stop uploading reported findings.  Findings that haven't been
+// uploaded are dropped, as they're uploaded with the results.
+func (w *batchWriter) Stop() {
+	if w.stop != nil {
+		close(w.stop)
+		<-w.stopped
+		w.stop = nil
+	}
+	w.lock.Lock()
+	w.pending = nil
+	w.lock.Unlock()
+}
+
+// Report findings that a tool has found while it's running
+func (w *batchWriter) Report(findings ...*assessments.Finding) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.pending = append(w.pending, findings...)
+	if w.FlushCount > 0 && len(w.pending) >= w.FlushCount {
+		w.flushReported()
+	}
+}
+
+// A failed upload of reported findings doesn't fail the scan, the
+// findings are uploaded again with the results.
+func (w *batchWriter) flushReported() {
+	if len(w.pending) == 0 {
+		return
+	}
+	if _, err := w.post(false); err != nil {
+		log.Warnf("Could not upload {warning:%d} findings of {primary:%s}: {warning:%s}", len(w.pending), w.Module, err)
+		w.pending = nil
+	}
+}
+
+// Add findings to the next batch
+func (w *batchWriter) Add(findings ...*assessments.Finding) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.pending = append(w.pending, findings...)
+}
+
+// Upload any pending findings
+func (w *batchWriter) Flush() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if len(w.pending) == 0 {
+		return nil
+	}
+	_, err := w.post(false)
+	return err
+}
+
+// Upload the final batch of findings along with options (e.g. the
+// results file), and return the server's response.
+func (w *batchWriter) Close(options ...api.Option) (*jnode.Node, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.post(true, options...)
+}
+
+func (w *batchWriter) post(final bool, options ...api.Option) (*jnode.Node, error) {
+	w.batch++
+	values := map[string]string{}
+	for k, v := range w.Values {
+		values[k] = v
+	}
+	values["SCAN_ID"] = w.ScanID
+	values["BATCH_NUMBER"] = strconv.Itoa(w.batch)
+	values["BATCH_FINAL"] = strconv.FormatBool(final)
+	values["BATCH_PHASE"] = w.Phase
+	findings := w.pending
+	if findings == nil {
+		findings = assessments.Findings{}
+	}
+	d, err := json.Marshal(findings)
+	if err != nil {
+		return nil, err
+	}
+	options = append(append(options, w.Options...),
+		xcp.WithFileFromReader("findings_json", "findings.json", bytes.NewReader(d)))
+	log.Debugf("Uploading %s batch {info:%d} with {info:%d} findings", w.Phase, w.batch, len(w.pending))
+	n, err := w.Client.XCPPost(w.Org, w.Module, nil, values, options...)
+	if err != nil {
+		return nil, err
+	}
+	w.pending = nil
+	return n, nil
+}
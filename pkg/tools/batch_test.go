@@ -0,0 +1,193 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadChunked(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("GITHUB_RUN_ID", "1234")
+	opts := &ToolOpts{}
+	opts.APIServer = "https://api.example.com"
+	opts.APIToken = "xxx"
+	opts.Organization = "9999"
+	httpmock.ActivateNonDefault(opts.GetAPIClient().GetClient().GetClient())
+	defer httpmock.DeactivateAndReset()
+	var (
+		scanIDs  []string
+		finals   []string
+		chunks   []string
+		findings int
+		results  *jnode.Node
+	)
+	httpmock.RegisterResponder("POST", "https://api.example.com/api/v1/xcp/test/data",
+		func(h *http.Request) (*http.Response, error) {
+			assert.Nil(h.ParseMultipartForm(1 << 20))
+			scanIDs = append(scanIDs, h.FormValue("SCAN_ID"))
+			finals = append(finals, h.FormValue("BATCH_FINAL"))
+			chunks = append(chunks, h.FormValue("UPLOAD_CHUNKS"))
+			assert.Equal("1234", h.FormValue("GITHUB_RUN_ID"))
+			if f, _, err := h.FormFile("findings_json"); assert.NoError(err) {
+				d, _ := io.ReadAll(f)
+				assert.LessOrEqual(len(d), 200)
+				var fs assessments.Findings
+				assert.NoError(json.Unmarshal(d, &fs))
+				findings += len(fs)
+			}
+			if f, _, err := h.FormFile("results_json"); err == nil {
+				d, _ := io.ReadAll(f)
+				results, _ = jnode.FromJSON(d)
+			}
+			n := jnode.NewObjectNode()
+			n.PutObject("assessment").Put("appUrl", "http://app.example.com/A1")
+			return httpmock.NewJsonResponse(http.StatusOK, n)
+		})
+	result := &Result{Data: jnode.NewObjectNode()}
+	for i := 0; i < 5; i++ {
+		result.Findings = append(result.Findings, &assessments.Finding{
+			FilePath: fmt.Sprintf("dir/file%d.tf", i),
+			Title:    strings.Repeat("x", 40),
+		})
+	}
+	assert.NoError(result.Upload(opts.GetAPIClient(), "", "test", 200))
+	count := len(finals)
+	assert.Greater(count, 2)
+	for i := range finals {
+		assert.Equal(strconv.FormatBool(i == count-1), finals[i])
+		assert.Equal(scanIDs[0], scanIDs[i])
+		assert.Equal(strconv.Itoa(count), chunks[i])
+	}
+	assert.Equal(5, findings)
+	if assert.NotNil(results) {
+		assert.Equal(count, results.Path("soluble_upload_chunks").Path("count").AsInt())
+		assert.Equal(scanIDs[0], results.Path("soluble_upload_chunks").Path("scanId").AsText())
+	}
+	assert.Equal("http://app.example.com/A1", result.Assessment.URL)
+}
+
+type testReportingTool struct {
+	ToolOpts
+	run func(*testReportingTool) (*Result, error)
+}
+
+func (*testReportingTool) Name() string { return "test" }
+
+func (t *testReportingTool) Run() (*Result, error) {
+	return t.run(t)
+}
+
+func TestUploadReportedFindings(t *testing.T) {
+	assert := assert.New(t)
+	tool := &testReportingTool{}
+	tool.Tool = tool
+	tool.APIServer = "https://api.example.com"
+	tool.APIToken = "xxx"
+	tool.Organization = "9999"
+	tool.UploadEnabled = true
+	tool.UploadBatchSize = 2
+	tool.repoRootSet = true
+	httpmock.ActivateNonDefault(tool.GetAPIClient().GetClient().GetClient())
+	defer httpmock.DeactivateAndReset()
+	var (
+		phases   []string
+		scanIDs  []string
+		findings []int
+	)
+	httpmock.RegisterResponder("POST", "https://api.example.com/api/v1/xcp/test/data",
+		func(h *http.Request) (*http.Response, error) {
+			assert.Nil(h.ParseMultipartForm(1 << 20))
+			phases = append(phases, h.FormValue("BATCH_PHASE"))
+			scanIDs = append(scanIDs, h.FormValue("SCAN_ID"))
+			count := 0
+			if f, _, err := h.FormFile("findings_json"); err == nil {
+				d, _ := io.ReadAll(f)
+				var fs assessments.Findings
+				assert.NoError(json.Unmarshal(d, &fs))
+				count = len(fs)
+			}
+			findings = append(findings, count)
+			n := jnode.NewObjectNode()
+			n.PutObject("assessment").Put("appUrl", "http://app.example.com/A1")
+			return httpmock.NewJsonResponse(http.StatusOK, n)
+		})
+	tool.run = func(t *testReportingTool) (*Result, error) {
+		result := &Result{Data: jnode.NewObjectNode()}
+		for i := 0; i < 5; i++ {
+			f := &assessments.Finding{FilePath: fmt.Sprintf("file%d.tf", i)}
+			result.Findings = append(result.Findings, f)
+			t.ReportFindings(f)
+		}
+		// the 5th finding is still pending
+		assert.Equal([]string{"scan", "scan"}, phases)
+		assert.Equal([]int{2, 2}, findings)
+		return result, nil
+	}
+	results, err := tool.RunTool()
+	assert.NoError(err)
+	if assert.Len(phases, 4) {
+		assert.Equal([]string{"scan", "scan", "results", "results"}, phases)
+		// all the findings are uploaded with the results
+		assert.Equal([]int{2, 2, 5, 0}, findings)
+		for _, id := range scanIDs {
+			assert.Equal(scanIDs[0], id)
+		}
+	}
+	if assert.Len(results, 1) {
+		assert.Equal("http://app.example.com/A1", results[0].Assessment.URL)
+	}
+}
+
+func TestUploadReportedFindingsInterval(t *testing.T) {
+	assert := assert.New(t)
+	opts := &ToolOpts{}
+	opts.APIServer = "https://api.example.com"
+	opts.APIToken = "xxx"
+	opts.Organization = "9999"
+	httpmock.ActivateNonDefault(opts.GetAPIClient().GetClient().GetClient())
+	defer httpmock.DeactivateAndReset()
+	posts := make(chan string, 10)
+	httpmock.RegisterResponder("POST", "https://api.example.com/api/v1/xcp/test/data",
+		func(h *http.Request) (*http.Response, error) {
+			assert.Nil(h.ParseMultipartForm(1 << 20))
+			posts <- h.FormValue("BATCH_PHASE")
+			return httpmock.NewJsonResponse(http.StatusOK, jnode.NewObjectNode())
+		})
+	w := newBatchWriter(opts.GetAPIClient(), "", "test", nil)
+	w.Phase = "scan"
+	w.FlushInterval = 10 * time.Millisecond
+	w.Start()
+	w.Report(&assessments.Finding{FilePath: "main.tf"})
+	select {
+	case phase := <-posts:
+		assert.Equal("scan", phase)
+	case <-time.After(5 * time.Second):
+		assert.Fail("the reported finding wasn't uploaded")
+	}
+	w.Stop()
+}
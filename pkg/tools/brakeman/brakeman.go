@@ -34,6 +34,14 @@ func (t *Tool) Name() string {
 	return "brakeman"
 }
 
+func (t *Tool) Preflight() error {
+	return t.PreflightDocker()
+}
+
+func (t *Tool) Requirements() []string {
+	return []string{tools.RequiresDocker}
+}
+
 func (t *Tool) Run() (*tools.Result, error) {
 	args := []string{"-f", "json", "-q"}
 	d, err := t.RunDocker(&tools.DockerTool{
@@ -43,7 +51,7 @@ func (t *Tool) Run() (*tools.Result, error) {
 		Directory:           t.GetDirectory(),
 		Args:                args,
 	})
-	if err != nil && tools.IsDockerError(err) {
+	if err != nil && tools.IsContainerError(err) {
 		return nil, err
 	}
 	results, err := jnode.FromJSON(d)
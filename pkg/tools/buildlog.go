@@ -0,0 +1,63 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/soluble-ai/soluble-cli/pkg/xcp"
+)
+
+// At most this much of the end of the --attach-log file is uploaded
+const buildLogLimit = 1 << 20
+
+// Read the build log at path for uploading.  If the log is larger than
+// limit bytes only the last limit bytes (of whole lines) are read.
+// Values that look like secrets are redacted.
+func readBuildLog(path string, limit int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	var skipped int64
+	if fi.Size() > limit {
+		skipped = fi.Size() - limit
+		if _, err := f.Seek(skipped, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	d, err := io.ReadAll(io.LimitReader(f, limit))
+	if err != nil {
+		return nil, err
+	}
+	if skipped > 0 {
+		// drop the partial first line
+		if nl := bytes.IndexByte(d, '\n'); nl >= 0 {
+			skipped += int64(nl + 1)
+			d = d[nl+1:]
+		}
+		d = append([]byte(fmt.Sprintf("[the first %d bytes of %s were not uploaded]\n", skipped, filepath.Base(path))), d...)
+	}
+	return []byte(xcp.RedactText(string(d))), nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadBuildLog(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "build.log")
+	assert.NoError(os.WriteFile(path, []byte(strings.Join([]string{
+		"Step 1/3 : FROM alpine",
+		"export GITHUB_TOKEN=xyzzy",
+		"Step 2/3 : RUN make",
+		"done",
+		"",
+	}, "\n")), 0600))
+	d, err := readBuildLog(path, buildLogLimit)
+	assert.NoError(err)
+	assert.Equal("Step 1/3 : FROM alpine\nexport GITHUB_TOKEN=*****\nStep 2/3 : RUN make\ndone\n", string(d))
+	d, err = readBuildLog(path, 20)
+	assert.NoError(err)
+	assert.Equal("[the first 69 bytes of build.log were not uploaded]\ndone\n", string(d))
+	_, err = readBuildLog(filepath.Join(t.TempDir(), "missing.log"), buildLogLimit)
+	assert.Error(err)
+}
@@ -31,6 +31,14 @@ var _ tools.Single = (*Tool)(nil)
 
 func (t *Tool) Name() string { return "bundler-audit" }
 
+func (t *Tool) Preflight() error {
+	return t.PreflightDocker()
+}
+
+func (t *Tool) Requirements() []string {
+	return []string{tools.RequiresDocker}
+}
+
 func (t *Tool) Run() (*tools.Result, error) {
 	args := []string{
 		"check", "--quiet", "--format", "json", ".",
@@ -42,7 +50,7 @@ func (t *Tool) Run() (*tools.Result, error) {
 		Directory:           t.GetDirectory(),
 		Args:                args,
 	})
-	if err != nil && tools.IsDockerError(err) {
+	if err != nil && tools.IsContainerError(err) {
 		return nil, err
 	}
 	results, err := jnode.FromJSON(d)
@@ -20,11 +20,14 @@ import (
 
 	"github.com/soluble-ai/go-jnode"
 	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/inventory"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/spf13/cobra"
 )
 
+const cfnLintImage = "gcr.io/soluble-repo/soluble-cfn-lint:latest"
+
 type Tool struct {
 	tools.DirectoryBasedToolOpts
 	Templates []string
@@ -34,6 +37,18 @@ func (t *Tool) Name() string {
 	return "cfn-python-lint"
 }
 
+func (t *Tool) GetDockerImage() string {
+	return t.ResolveDockerImage("cfn-python-lint", cfnLintImage)
+}
+
+func (t *Tool) Preflight() error {
+	return t.PreflightDocker()
+}
+
+func (t *Tool) Requirements() []string {
+	return []string{tools.RequiresDocker}
+}
+
 func (t *Tool) Register(cmd *cobra.Command) {
 	t.DirectoryBasedToolOpts.Register(cmd)
 	cmd.Flags().StringSliceVar(&t.Templates, "template", nil, "Explicitly specific templates in the form `t1,t2,...`.  May be repeated.  Templates must be relative to --directory.")
@@ -57,11 +72,11 @@ func (t *Tool) Run() (*tools.Result, error) {
 	d, err := t.RunDocker(&tools.DockerTool{
 		Name:                "cfn-python-lint",
 		DefaultNoDockerName: "cfn-lint",
-		Image:               "gcr.io/soluble-repo/soluble-cfn-lint:latest",
+		Image:               cfnLintImage,
 		Directory:           t.GetDirectory(),
 		Args:                append([]string{"-f", "json"}, files...),
 	})
-	if err != nil && tools.IsDockerError(err) {
+	if err != nil && tools.IsContainerError(err) {
 		return nil, err
 	}
 	results, err := jnode.FromJSON(d)
@@ -100,5 +115,10 @@ func (t *Tool) findCloudformationFiles() ([]string, error) {
 	if len(t.Templates) > 0 {
 		return t.GetFilesInDirectory(t.Templates)
 	}
+	if files := t.GetFiles(); files != nil {
+		// only look at the listed files
+		m := inventory.DoFiles(t.GetDirectory(), files)
+		return t.RemoveExcluded(m.CloudformationFiles.Values()), nil
+	}
 	return t.GetInventory().CloudformationFiles.Values(), nil
 }
@@ -35,6 +35,14 @@ func (t *Tool) Name() string {
 	return "cfn-nag"
 }
 
+func (t *Tool) Preflight() error {
+	return t.PreflightDocker()
+}
+
+func (t *Tool) Requirements() []string {
+	return []string{tools.RequiresDocker}
+}
+
 func (t *Tool) CommandTemplate() *cobra.Command {
 	return &cobra.Command{
 		Use:   "cfn-nag",
@@ -62,7 +70,7 @@ func (t *Tool) Run() (*tools.Result, error) {
 		Directory: t.GetDirectory(),
 		Args:      append([]string{"--output-format=json"}, files...),
 	})
-	if err != nil && tools.IsDockerError(err) {
+	if err != nil && tools.IsContainerError(err) {
 		return nil, err
 	}
 	results, err := jnode.FromJSON(d)
@@ -9,6 +9,7 @@ import (
 
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/tracing"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/spf13/cobra"
 )
@@ -26,6 +27,13 @@ func (cdk *CDK) Name() string {
 	return "checkov-cdk"
 }
 
+func (cdk *CDK) Requirements() []string {
+	if cdk.Synth {
+		return []string{"cdk", tools.RequiresDocker}
+	}
+	return []string{tools.RequiresDocker}
+}
+
 func (cdk *CDK) Register(cmd *cobra.Command) {
 	cdk.DirectoryBasedToolOpts.Register(cmd)
 	flags := cmd.Flags()
@@ -74,7 +82,10 @@ func (cdk *CDK) Run() (*tools.Result, error) {
 		synth.Stderr = os.Stderr
 		synth.Stdout = os.Stderr
 		cdk.LogCommand(synth)
-		if err := synth.Run(); err != nil {
+		span := cdk.StartCommandSpan(synth)
+		err := synth.Run()
+		tracing.EndSpan(span, err)
+		if err != nil {
 			log.Errorf("{primary:cdk synth} failed.  Run cdk synth manually and use {primary:--cdk-synth=false}.")
 			return nil, err
 		}
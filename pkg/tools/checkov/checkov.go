@@ -30,6 +30,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const checkovImage = "bridgecrew/checkov:latest"
+
 type Tool struct {
 	tools.DirectoryBasedToolOpts
 	Framework            string
@@ -44,6 +46,18 @@ func (t *Tool) Name() string {
 	return "checkov"
 }
 
+func (t *Tool) GetDockerImage() string {
+	return t.ResolveDockerImage("checkov", checkovImage)
+}
+
+func (t *Tool) Preflight() error {
+	return t.PreflightDocker()
+}
+
+func (t *Tool) Requirements() []string {
+	return []string{tools.RequiresDocker}
+}
+
 func (t *Tool) Register(cmd *cobra.Command) {
 	t.DirectoryBasedToolOpts.Register(cmd)
 	iacbot := os.Getenv("ZODIAC_JOB_NAME") != ""
@@ -65,7 +79,9 @@ func (t *Tool) Run() (*tools.Result, error) {
 		"-o", "json", "-s",
 	}
 	if t.Framework != "" {
-		args = append(args, "--framework", t.Framework)
+		// checkov accepts more than one framework, e.g. "arm,bicep"
+		args = append(args, "--framework")
+		args = append(args, strings.Split(t.Framework, ",")...)
 	}
 	if t.Framework == "terraform" && t.EnableModuleDownload {
 		args = append(args, "--download-external-modules", "true")
@@ -82,6 +98,7 @@ func (t *Tool) Run() (*tools.Result, error) {
 	if customPoliciesDir != "" {
 		args = append(args, "--external-checks-dir", customPoliciesDir)
 	}
+	t.extraArgs.WarnOutputFlags(t.Name(), "-o", "--output")
 	args = append(args, t.extraArgs...)
 	toolDir := t.GetDirectory()
 	if t.RepoRoot != "" {
@@ -93,7 +110,7 @@ func (t *Tool) Run() (*tools.Result, error) {
 	}
 	dat, err := t.RunDocker(&tools.DockerTool{
 		Name:                "checkov",
-		Image:               "bridgecrew/checkov:latest",
+		Image:               checkovImage,
 		DefaultNoDockerName: "checkov",
 		Directory:           toolDir,
 		PolicyDirectory:     customPoliciesDir,
@@ -218,6 +235,9 @@ func (t *Tool) processChecks(result *tools.Result, checks *jnode.Node, checkType
 			// the RepoPath is the same as the path
 			finding.RepoPath = path
 		}
+		if guideline := n.Path("guideline").AsText(); guideline != "" {
+			finding.Tool["help_url"] = guideline
+		}
 		result.Findings = append(result.Findings, finding)
 	}
 	return checks
@@ -17,6 +17,7 @@ package checkov
 import (
 	"testing"
 
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/stretchr/testify/assert"
 )
@@ -53,3 +54,12 @@ func TestParseResults2(t *testing.T) {
 	result := tool.processResults(results)
 	assert.Equal("6", result.Values["RESOURCE_COUNT"])
 }
+
+func TestRequirements(t *testing.T) {
+	assert := assert.New(t)
+	cdk := &CDK{Synth: true}
+	assert.Equal([]string{"cdk", tools.RequiresDocker}, cdk.Requirements())
+	cdk.Synth = false
+	assert.Equal([]string{tools.RequiresDocker}, cdk.Requirements())
+	assert.Equal([]string{"helm", tools.RequiresDocker}, (&Helm{}).Requirements())
+}
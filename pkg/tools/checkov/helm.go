@@ -18,6 +18,10 @@ func (h *Helm) Name() string {
 	return "checkov-helm"
 }
 
+func (h *Helm) Requirements() []string {
+	return []string{"helm", tools.RequiresDocker}
+}
+
 func (h *Helm) RunAll() (tools.Results, error) {
 	var (
 		results tools.Results
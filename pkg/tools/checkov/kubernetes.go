@@ -0,0 +1,118 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checkov
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/soluble-ai/soluble-cli/pkg/download"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/tools/trivy"
+	"github.com/spf13/cobra"
+)
+
+// Scans kubernetes manifests with checkov, and with --scan-images the
+// container images that the manifests reference with trivy.  The image
+// findings are uploaded as their own assessment.
+type Kubernetes struct {
+	tools.DirectoryBasedToolOpts
+	ScanImages bool
+
+	extraArgs tools.ExtraArgs
+}
+
+var _ tools.Consolidated = &Kubernetes{}
+
+func (k *Kubernetes) Name() string {
+	return "checkov"
+}
+
+func (k *Kubernetes) GetDockerImage() string {
+	return k.ResolveDockerImage("checkov", checkovImage)
+}
+
+func (k *Kubernetes) Preflight() error {
+	if err := k.PreflightDocker(); err != nil {
+		return err
+	}
+	if k.ScanImages {
+		return k.PreflightInstall(&download.Spec{
+			URL: "github.com/aquasecurity/trivy",
+		})
+	}
+	return nil
+}
+
+func (k *Kubernetes) Requirements() []string {
+	if k.ScanImages {
+		return []string{tools.RequiresDocker, tools.RequiresDownload}
+	}
+	return []string{tools.RequiresDocker}
+}
+
+func (k *Kubernetes) Register(cmd *cobra.Command) {
+	k.DirectoryBasedToolOpts.Register(cmd)
+	cmd.Flags().BoolVar(&k.ScanImages, "scan-images", false,
+		"Also scan the container images referenced by the manifests for vulnerabilities with trivy")
+}
+
+func (k *Kubernetes) CommandTemplate() *cobra.Command {
+	return &cobra.Command{
+		Use:     "kubernetes-scan",
+		Short:   "Scan kubernetes manifests",
+		Aliases: []string{"k8s-scan"},
+		Long: `Scan kubernetes manifests with checkov
+
+With --scan-images each image referenced by the manifests is also
+scanned with trivy once, and its findings refer to the manifest that
+first references the image.`,
+		Args: k.extraArgs.ArgsValue(),
+	}
+}
+
+func (k *Kubernetes) RunAll() (tools.Results, error) {
+	checkov := &Tool{
+		DirectoryBasedToolOpts: k.DirectoryBasedToolOpts,
+		Framework:              "kubernetes",
+		extraArgs:              k.extraArgs,
+	}
+	result, err := checkov.Run()
+	if err != nil {
+		return nil, err
+	}
+	results := tools.Results{result}
+	if !k.ScanImages {
+		return results, nil
+	}
+	images := &trivy.KubernetesImages{
+		DirectoryBasedToolOpts: tools.DirectoryBasedToolOpts{
+			Directory: k.GetDirectory(),
+			Exclude:   k.Exclude,
+		},
+	}
+	opts := images.GetToolOptions()
+	opts.Tool = images
+	opts.UploadEnabled = k.UploadEnabled
+	opts.UploadBatchSize = k.UploadBatchSize
+	opts.UploadBatchInterval = k.UploadBatchInterval
+	// the image results are processed (and uploaded) as trivy's
+	imageResults, err := opts.RunTool()
+	results = append(results, imageResults...)
+	if err != nil {
+		return results, multierror.Append(nil, fmt.Errorf("%s failed - %w", images.Name(), err))
+	}
+	return results, nil
+}
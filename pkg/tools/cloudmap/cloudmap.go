@@ -7,6 +7,7 @@ import (
 	"github.com/soluble-ai/go-jnode"
 	"github.com/soluble-ai/soluble-cli/pkg/download"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/tracing"
 	"github.com/spf13/cobra"
 )
 
@@ -23,6 +24,14 @@ func (*Tool) Name() string {
 	return "cloud-map"
 }
 
+func (t *Tool) Preflight() error {
+	return t.PreflightInstall(&download.Spec{Name: "tfscore"})
+}
+
+func (t *Tool) Requirements() []string {
+	return []string{tools.RequiresDownload}
+}
+
 func (*Tool) IsNonAssessment() bool {
 	return true
 }
@@ -56,8 +65,11 @@ func (t *Tool) Run() (*tools.Result, error) {
 	// #nosec G204
 	c := exec.Command(d.GetExePath("tfscore"), args...)
 	c.Stderr = os.Stderr
+	defer tools.AcquireWorker()()
 	t.LogCommand(c)
+	span := t.StartCommandSpan(c)
 	dat, err := c.Output()
+	tracing.EndSpan(span, err)
 	if err != nil {
 		return nil, err
 	}
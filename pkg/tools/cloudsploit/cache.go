@@ -0,0 +1,87 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudsploit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/soluble-ai/soluble-cli/pkg/config"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+// Cloud scans are slow and the results don't change much from one
+// minute to the next, so the raw cloudsploit output is cached for a
+// while.  Only the results are cached, never the credentials.
+
+const defaultCacheTTL = time.Hour
+
+// Returns the cache key of a scan.  The key identifies the account
+// that was scanned, the region, the plugins that were run (i.e. the
+// extra arguments), and the image that ran them.
+func (t *Tool) getCacheKey() string {
+	if t.account == "" {
+		return ""
+	}
+	parts := []string{t.Cloud, t.account, t.region, cloudsploitImage}
+	parts = append(parts, t.extraArgs...)
+	h := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(h[:])
+}
+
+func getCachePath(key string) string {
+	return filepath.Join(config.ConfigDir, "cloudsploit-cache", key+".json")
+}
+
+// Returns the cached results for key if they're younger than ttl,
+// along with their age.
+func readCache(key string, ttl time.Duration) ([]byte, time.Duration) {
+	path := getCachePath(key)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, 0
+	}
+	age := time.Since(info.ModTime())
+	if age > ttl {
+		return nil, 0
+	}
+	dat, err := os.ReadFile(path)
+	if err != nil {
+		log.Warnf("Could not read cached cloudsploit results {warning:%s} - {warning:%s}", path, err)
+		return nil, 0
+	}
+	return dat, age
+}
+
+func writeCache(key string, dat []byte) {
+	path := getCachePath(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		log.Warnf("Could not cache cloudsploit results - {warning:%s}", err)
+		return
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, dat, 0600); err != nil {
+		log.Warnf("Could not cache cloudsploit results - {warning:%s}", err)
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		log.Warnf("Could not cache cloudsploit results - {warning:%s}", err)
+	}
+}
@@ -17,63 +17,326 @@ package cloudsploit
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
 	"github.com/soluble-ai/soluble-cli/pkg/config"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/spf13/cobra"
 )
 
-func Command() *cobra.Command {
-	opts := &tools.RunOpts{}
-	c := &cobra.Command{
-		Use:   "cloudsploit",
-		Short: "Scan cloud infrastructure with Cloudsploit",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			awscfg, err := awsconfig.LoadDefaultConfig(context.Background())
-			if err != nil {
-				return err
-			}
-			creds, err := awscfg.Credentials.Retrieve(context.Background())
-			if err != nil {
-				return err
+type Tool struct {
+	tools.ToolOpts
+	Cloud               string
+	AWSProfile          string
+	AWSRegion           string
+	GCPCredentials      string
+	AzureClientID       string
+	AzureTenantID       string
+	AzureSubscriptionID string
+	NoCache             bool
+	CacheTTL            time.Duration
+
+	extraArgs tools.ExtraArgs
+	// the account and region being scanned, used for the cache key
+	account string
+	region  string
+}
+
+// The cloudsploit --cloud name of each provider
+var clouds = map[string]string{
+	"aws":   "aws",
+	"gcp":   "google",
+	"azure": "azure",
+}
+
+// The path the GCP credentials file is mounted at in the container
+const gcpCredentialsPath = "/app/gcp-credentials.json"
+
+const cloudsploitImage = "gcr.io/soluble-repo/soluble-cloudsploit:latest"
+
+var _ tools.Single = &Tool{}
+
+func (t *Tool) Name() string {
+	return "cloudsploit"
+}
+
+// Cloudsploit findings are cloud resources rather than files, but they
+// are still pass/fail assessments, so they're printed, counted, scored
+// and suppressed like the findings of any other scanner.  They aren't
+// fingerprinted because the result has no Directory.
+func (t *Tool) IsNonAssessment() bool {
+	return false
+}
+
+func (t *Tool) Preflight() error {
+	return t.PreflightDocker()
+}
+
+func (t *Tool) Requirements() []string {
+	return []string{tools.RequiresDocker}
+}
+
+func (t *Tool) Register(cmd *cobra.Command) {
+	t.ToolOpts.Register(cmd)
+	flags := cmd.Flags()
+	flags.StringVar(&t.Cloud, "cloud", "aws", "The cloud `provider` to scan, one of aws, gcp, or azure")
+	flags.StringVar(&t.AWSProfile, "aws-profile", "", "Use the AWS credentials from this shared config `profile`")
+	flags.StringVar(&t.AWSRegion, "aws-region", "", "The default AWS `region`")
+	flags.StringVar(&t.GCPCredentials, "gcp-credentials", "", "The GCP service account key `file`.  Defaults to $GOOGLE_APPLICATION_CREDENTIALS.")
+	flags.StringVar(&t.AzureClientID, "azure-client-id", "", "The Azure application (client) `id`.  Defaults to $AZURE_CLIENT_ID.")
+	flags.StringVar(&t.AzureTenantID, "azure-tenant-id", "", "The Azure directory (tenant) `id`.  Defaults to $AZURE_TENANT_ID.")
+	flags.StringVar(&t.AzureSubscriptionID, "azure-subscription-id", "", "The Azure subscription `id`.  Defaults to $AZURE_SUBSCRIPTION_ID.  The client secret is always read from $AZURE_CLIENT_SECRET.")
+	flags.BoolVar(&t.NoCache, "no-cache", false, "Don't use cached results, always run a new scan")
+	flags.DurationVar(&t.CacheTTL, "cache-ttl", defaultCacheTTL, "Use cached results of the same scan that are younger than this `duration`, e.g. 30m")
+}
+
+func (t *Tool) Validate() error {
+	if t.Cloud == "" {
+		t.Cloud = "aws"
+	}
+	if clouds[t.Cloud] == "" {
+		return fmt.Errorf("--cloud must be one of aws, gcp, or azure")
+	}
+	switch t.Cloud {
+	case "gcp":
+		if t.GCPCredentials == "" {
+			t.GCPCredentials = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+		}
+		if t.GCPCredentials == "" {
+			return fmt.Errorf("--gcp-credentials or GOOGLE_APPLICATION_CREDENTIALS must be given to scan gcp")
+		}
+		if _, err := os.Stat(t.GCPCredentials); err != nil {
+			return fmt.Errorf("invalid --gcp-credentials: %w", err)
+		}
+		var err error
+		if t.GCPCredentials, err = filepath.Abs(t.GCPCredentials); err != nil {
+			return err
+		}
+	case "azure":
+		for _, v := range []struct {
+			value *string
+			flag  string
+			env   string
+		}{
+			{&t.AzureClientID, "--azure-client-id", "AZURE_CLIENT_ID"},
+			{&t.AzureTenantID, "--azure-tenant-id", "AZURE_TENANT_ID"},
+			{&t.AzureSubscriptionID, "--azure-subscription-id", "AZURE_SUBSCRIPTION_ID"},
+		} {
+			if *v.value == "" {
+				*v.value = os.Getenv(v.env)
 			}
-			env := map[string]string{}
-			env["AWS_ACCESS_KEY_ID"] = creds.AccessKeyID
-			env["AWS_SECRET_ACCESS_KEY"] = creds.SecretAccessKey
-			env["AWS_SESSION_TOKEN"] = creds.SessionToken
-			env["SOLUBLE_API_SERVER"] = opts.GetAPIClientConfig().APIServer
-			env["SOLUBLE_API_TOKEN"] = opts.GetAPIClientConfig().APIToken
-			envFile, err := writeEnvFile(env)
-			if err != nil {
-				return err
+			if *v.value == "" {
+				return fmt.Errorf("%s or %s must be given to scan azure", v.flag, v.env)
 			}
-			defer func() { _ = os.Remove(envFile) }()
-			docker := &tools.DockerTool{
-				Name:  "cloudsploit",
-				Image: "gcr.io/soluble-repo/soluble-cloudsploit:latest",
-				DockerArgs: []string{"--env-file", envFile,
-					"-v", fmt.Sprintf("%s:/app/.solulble:ro", config.ConfigDir)},
-				Args:   args,
-				Stdout: os.Stdout,
+		}
+		if os.Getenv("AZURE_CLIENT_SECRET") == "" {
+			return fmt.Errorf("AZURE_CLIENT_SECRET must be set to scan azure")
+		}
+	}
+	return t.ToolOpts.Validate()
+}
+
+func (t *Tool) CommandTemplate() *cobra.Command {
+	return &cobra.Command{
+		Use:     "cloudsploit",
+		Short:   "Scan cloud infrastructure with Cloudsploit",
+		Example: "Any extra arguments after -- are passed to cloudsploit",
+		Args:    t.extraArgs.ArgsValue(),
+	}
+}
+
+func (t *Tool) Run() (*tools.Result, error) {
+	env, err := t.getCloudEnv()
+	if err != nil {
+		return nil, err
+	}
+	key := t.getCacheKey()
+	if key != "" && !t.NoCache && t.CacheTTL > 0 {
+		if dat, age := readCache(key, t.CacheTTL); dat != nil {
+			if n, err := jnode.FromJSON(dat); err == nil {
+				log.Infof("Using cached cloudsploit results from {info:%s} ago, use --no-cache to run a new scan",
+					age.Truncate(time.Second))
+				return parseResults(n), nil
 			}
-			_, err = opts.RunDocker(docker)
-			return err
-		},
+		}
+	}
+	env["SOLUBLE_API_SERVER"] = t.GetAPIClientConfig().APIServer
+	env["SOLUBLE_API_TOKEN"] = t.GetAPIClientConfig().APIToken
+	envFile, err := writeEnvFile(env)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.Remove(envFile) }()
+	args := []string{"--cloud", clouds[t.Cloud], "--console", "none", "--json", "/dev/stdout"}
+	args = append(args, t.extraArgs...)
+	// credentials are passed in the env file so that they don't show
+	// up in the logged command line
+	dockerArgs := []string{"--env-file", envFile,
+		"-v", fmt.Sprintf("%s:/app/.solulble:ro", config.ConfigDir)}
+	if t.Cloud == "gcp" {
+		dockerArgs = append(dockerArgs, "-v", fmt.Sprintf("%s:%s:ro", t.GCPCredentials, gcpCredentialsPath))
+	}
+	dat, err := t.RunDocker(&tools.DockerTool{
+		Name:       "cloudsploit",
+		Image:      cloudsploitImage,
+		DockerArgs: dockerArgs,
+		Args:       args,
+	})
+	if err != nil {
+		if dat != nil {
+			_, _ = os.Stderr.Write(dat)
+		}
+		return nil, err
+	}
+	n, err := jnode.FromJSON(dat)
+	if err != nil {
+		_, _ = os.Stderr.Write(dat)
+		return nil, err
+	}
+	if key != "" && t.CacheTTL > 0 {
+		writeCache(key, dat)
+	}
+	// cloudsploit findings refer to cloud resources rather than files,
+	// so the result has no Directory and isn't fingerprinted
+	return parseResults(n), nil
+}
+
+// Returns the environment variables that cloudsploit reads the
+// credentials of the cloud provider from.
+func (t *Tool) getCloudEnv() (map[string]string, error) {
+	switch t.Cloud {
+	case "gcp":
+		t.account = getGCPProject(t.GCPCredentials)
+		return map[string]string{
+			"GOOGLE_APPLICATION_CREDENTIALS": gcpCredentialsPath,
+		}, nil
+	case "azure":
+		t.account = fmt.Sprintf("%s/%s", t.AzureTenantID, t.AzureSubscriptionID)
+		return map[string]string{
+			"AZURE_APPLICATION_ID":  t.AzureClientID,
+			"AZURE_DIRECTORY_ID":    t.AzureTenantID,
+			"AZURE_SUBSCRIPTION_ID": t.AzureSubscriptionID,
+			"AZURE_KEY_VALUE":       os.Getenv("AZURE_CLIENT_SECRET"),
+		}, nil
+	default:
+		return t.getAWSEnv()
+	}
+}
+
+func (t *Tool) getAWSEnv() (map[string]string, error) {
+	var opts []func(*awsconfig.LoadOptions) error
+	if t.AWSProfile != "" {
+		opts = append(opts, awsconfig.WithSharedConfigProfile(t.AWSProfile))
+	}
+	if t.AWSRegion != "" {
+		opts = append(opts, awsconfig.WithRegion(t.AWSRegion))
+	}
+	awscfg, err := awsconfig.LoadDefaultConfig(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := awscfg.Credentials.Retrieve(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("could not get AWS credentials: %w", err)
+	}
+	t.account = getAWSAccount(awscfg)
+	t.region = awscfg.Region
+	return map[string]string{
+		"AWS_ACCESS_KEY_ID":     creds.AccessKeyID,
+		"AWS_SECRET_ACCESS_KEY": creds.SecretAccessKey,
+		"AWS_SESSION_TOKEN":     creds.SessionToken,
+		"AWS_DEFAULT_REGION":    awscfg.Region,
+	}, nil
+}
+
+// Returns the id of the AWS account, or "" if it can't be determined
+// (in which case the results aren't cached.)
+func getAWSAccount(awscfg aws.Config) string {
+	out, err := sts.NewFromConfig(awscfg).GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+	if err != nil {
+		log.Debugf("Could not get the AWS account id - {warning:%s}", err)
+		return ""
+	}
+	return aws.ToString(out.Account)
+}
+
+// Returns the project id from a GCP service account key file.
+func getGCPProject(credentialsFile string) string {
+	n, err := util.ReadJSONFile(credentialsFile)
+	if err != nil {
+		return ""
+	}
+	return n.Path("project_id").AsText()
+}
+
+func parseResults(n *jnode.Node) *tools.Result {
+	result := &tools.Result{
+		Data: n,
+	}
+	for _, e := range n.Elements() {
+		status := e.Path("status").AsText()
+		if status == "OK" {
+			continue
+		}
+		severity := getSeverity(e)
+		resource := e.Path("resource").AsText()
+		if resource == "N/A" {
+			resource = ""
+		}
+		result.Findings = append(result.Findings, &assessments.Finding{
+			Tool: map[string]string{
+				"plugin":   e.Path("plugin").AsText(),
+				"severity": severity,
+				"category": e.Path("category").AsText(),
+				"region":   e.Path("region").AsText(),
+				"status":   status,
+			},
+			FilePath:    resource,
+			Title:       e.Path("title").AsText(),
+			Description: e.Path("message").AsText(),
+			Severity:    severity,
+		})
+	}
+	return result
+}
+
+// Newer versions of cloudsploit include a severity with each result,
+// for older versions we derive one from the status.
+func getSeverity(e *jnode.Node) string {
+	if severity := e.Path("severity").AsText(); severity != "" {
+		return strings.ToLower(severity)
+	}
+	switch e.Path("status").AsText() {
+	case "FAIL":
+		return "medium"
+	case "WARN":
+		return "low"
+	default:
+		return "info"
 	}
-	opts.Register(c)
-	return c
 }
 
 func writeEnvFile(env map[string]string) (string, error) {
 	// Write the environment variables to a tmpdir with a bindmount
 	// (because we don't want to leak sensitive keys into `ps` and logs)
-	envFile, err := ioutil.TempFile("", "soluble-cloudsploit*")
+	name, err := util.TempFile("soluble-cloudsploit*")
 	if err != nil {
 		return "", fmt.Errorf("unable to create temporary file for cloudsploit: %w", err)
 	}
+	envFile, err := os.OpenFile(name, os.O_WRONLY, 0600)
+	if err != nil {
+		return "", err
+	}
 	defer envFile.Close()
 	for k, v := range env {
 		if v == "" {
@@ -0,0 +1,100 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudsploit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/soluble-ai/soluble-cli/pkg/config"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseResults(t *testing.T) {
+	assert := assert.New(t)
+	n := util.MustReadJSONFile("testdata/results.json")
+	result := parseResults(n)
+	assert.Equal("", result.Directory)
+	if assert.Len(result.Findings, 2) {
+		f := result.Findings[0]
+		assert.Equal("arn:aws:s3:::example-logs", f.FilePath)
+		assert.Equal("Bucket has versioning disabled", f.Description)
+		assert.Equal("medium", f.Severity)
+		assert.Equal("medium", f.Tool["severity"])
+		assert.Equal("S3", f.Tool["category"])
+		assert.Equal("global", f.Tool["region"])
+		f = result.Findings[1]
+		assert.Equal("high", f.Severity)
+		assert.Equal("us-west-2", f.Tool["region"])
+	}
+}
+
+func TestValidateCloud(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+	for _, k := range []string{"AZURE_CLIENT_ID", "AZURE_TENANT_ID", "AZURE_SUBSCRIPTION_ID", "AZURE_CLIENT_SECRET"} {
+		t.Setenv(k, "")
+	}
+	assert.Error((&Tool{Cloud: "oracle"}).Validate())
+	assert.Error((&Tool{Cloud: "gcp"}).Validate())
+	assert.Error((&Tool{Cloud: "gcp", GCPCredentials: "testdata/missing.json"}).Validate())
+	tool := &Tool{Cloud: "gcp", GCPCredentials: "testdata/results.json"}
+	assert.NoError(tool.Validate())
+	assert.True(filepath.IsAbs(tool.GCPCredentials))
+	env, err := tool.getCloudEnv()
+	assert.NoError(err)
+	assert.Equal(gcpCredentialsPath, env["GOOGLE_APPLICATION_CREDENTIALS"])
+	tool = &Tool{Cloud: "azure", AzureClientID: "client", AzureTenantID: "tenant"}
+	assert.Error(tool.Validate())
+	t.Setenv("AZURE_SUBSCRIPTION_ID", "sub")
+	assert.Error(tool.Validate())
+	t.Setenv("AZURE_CLIENT_SECRET", "shh")
+	assert.NoError(tool.Validate())
+	env, err = tool.getCloudEnv()
+	assert.NoError(err)
+	assert.Equal(map[string]string{
+		"AZURE_APPLICATION_ID":  "client",
+		"AZURE_DIRECTORY_ID":    "tenant",
+		"AZURE_SUBSCRIPTION_ID": "sub",
+		"AZURE_KEY_VALUE":       "shh",
+	}, env)
+}
+
+func TestCache(t *testing.T) {
+	assert := assert.New(t)
+	defer func(dir string) { config.ConfigDir = dir }(config.ConfigDir)
+	config.ConfigDir = t.TempDir()
+	tool := &Tool{Cloud: "gcp", GCPCredentials: "testdata/gcp-credentials.json"}
+	_, err := tool.getCloudEnv()
+	assert.NoError(err)
+	assert.Equal("my-project", tool.account)
+	key := tool.getCacheKey()
+	assert.NotEmpty(key)
+	tool.extraArgs = []string{"--plugin", "bucketVersioning"}
+	assert.NotEqual(key, tool.getCacheKey())
+	assert.Empty((&Tool{Cloud: "gcp"}).getCacheKey())
+	dat, _ := readCache(key, time.Hour)
+	assert.Nil(dat)
+	writeCache(key, []byte(`[]`))
+	dat, _ = readCache(key, time.Hour)
+	assert.Equal([]byte(`[]`), dat)
+	old := time.Now().Add(-2 * time.Hour)
+	assert.NoError(os.Chtimes(getCachePath(key), old, old))
+	dat, _ = readCache(key, time.Hour)
+	assert.Nil(dat)
+}
@@ -16,8 +16,11 @@ package tools
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/exit"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/print"
 	"github.com/spf13/cobra"
@@ -45,12 +48,14 @@ func CreateCommand(tool Interface) *cobra.Command {
 		return runTool(tool)
 	}
 	tool.Register(c)
+	registerTool(tool, c)
 	if !tool.IsNonAssessment() {
 		o := tool.GetToolOptions()
 		o.Path = []string{}
 		o.Columns = []string{
-			"sid", "severity", "pass", "title", "filePath", "line",
+			"sid", "severity", "pass", "title", "filePath", "line", "tool.help_url",
 		}
+		o.WideColumns = []string{"tool.help_url"}
 	}
 	return c
 }
@@ -58,6 +63,12 @@ func CreateCommand(tool Interface) *cobra.Command {
 func runTool(tool Interface) error {
 	opts := tool.GetToolOptions()
 	opts.Tool = tool
+	if opts.Check {
+		return runPreflight(tool)
+	}
+	if d := tool.GetDirectoryBasedToolOptions(); d != nil && d.Watch {
+		return runWatch(tool)
+	}
 	results, toolErr := opts.RunTool()
 	// even if the tool had an error we may have partial
 	// results that can be displayed
@@ -66,26 +77,134 @@ func runTool(tool Interface) error {
 			log.Infof("Asessment uploaded, see {primary:%s} for more information", result.Assessment.URL)
 		}
 	}
-	if len(results) == 1 && tool.IsNonAssessment() {
+	if opts.FingerprintsOnly {
+		if err := WriteFingerprints(os.Stdout, results); err != nil {
+			return err
+		}
+		return withExitCode(toolErr)
+	}
+	if err := printAllResults(tool, results, toolErr); err != nil {
+		return err
+	}
+	if opts.SaveHTMLReport != "" && len(results) > 0 {
+		if err := saveHTMLReport(opts.SaveHTMLReport, results); err != nil {
+			return err
+		}
+	}
+	if opts.SaveCSV != "" && !tool.IsNonAssessment() {
+		if err := saveCSV(opts.SaveCSV, results, opts.Fields); err != nil {
+			return err
+		}
+	}
+	if opts.SplitOutput != "" && !tool.IsNonAssessment() {
+		if err := saveSplitOutput(opts.SplitOutput, opts.OutputFormat, opts.Fields, results); err != nil {
+			return err
+		}
+	}
+	if opts.EmitMetadata != "" && len(results) > 0 {
+		if err := saveMetadata(opts.EmitMetadata, results); err != nil {
+			return err
+		}
+	}
+	if opts.SaveGitLabReport != "" && !tool.IsNonAssessment() {
+		if err := saveGitLabCodeQuality(opts.SaveGitLabReport, results); err != nil {
+			return err
+		}
+	}
+	if toolErr != nil {
+		return withExitCode(toolErr)
+	}
+	if opts.PostScanHook != "" && !tool.IsNonAssessment() {
+		if err := runPostScanHook(opts.PostScanHook, tool.Name(), results); err != nil {
+			if opts.PostScanHookFail {
+				return err
+			}
+			log.Warnf("{warning:%s}", err)
+		}
+	}
+	if !opts.UploadEnabled {
+		log.Infof("Scan results not uploaded")
+	}
+	return checkRequiredFindings(tool, results)
+}
+
+// With --require-findings, a scan that found nothing is treated as
+// broken (e.g. it scanned no files) rather than as a pass.
+func checkRequiredFindings(tool Interface, results Results) error {
+	if !tool.GetToolOptions().RequireFindings || tool.IsNonAssessment() {
+		return nil
+	}
+	for _, result := range results {
+		if len(result.Findings) > 0 {
+			return nil
+		}
+	}
+	log.Warnf("{warning:%s} produced no findings, check that it scanned the files you expected", tool.Name())
+	return exit.WithCode(exit.NoFindingsCode, fmt.Errorf("%s produced no findings", tool.Name()))
+}
+
+// Print the results to --output-file or stdout
+func printAllResults(tool Interface, results Results, toolErr error) error {
+	opts := tool.GetToolOptions()
+	if opts.OutputFile != "" && !opts.SeverityCountOnly {
+		return printResultsToFile(tool, results, toolErr)
+	}
+	return printResults(tool, results, toolErr)
+}
+
+func printResults(tool Interface, results Results, toolErr error) error {
+	opts := tool.GetToolOptions()
+	if opts.SeverityCountOnly {
+		results.writeSeverityCounts(os.Stdout)
+	} else if len(results) == 1 && tool.IsNonAssessment() {
 		result := results[0]
 		// for non-asessment tools just print the data
 		opts.PrintResult(result.Data)
 	} else {
 		var (
-			n   *jnode.Node
-			err error
+			n       *jnode.Node
+			err     error
+			summary bool
 		)
 		// What we really want to work off here is a list of all the assessments.
 		// But the printer doesn't support a splat-like path i.e. *.findings to
 		// accumulate all the findings across the assessments.  So for the default
 		// or table output format we do that accumulation in code here.
-		if opts.OutputFormat == "" || opts.OutputFormat == "table" {
+		switch {
+		case opts.GroupBy != "" && (opts.OutputFormat == "" || opts.OutputFormat == "table"):
+			opts.Columns = groupByColumns[opts.GroupBy]
+			opts.WideColumns = nil
+			limit := 3
+			if opts.Wide {
+				limit = 0
+			}
+			n = results.getGroupedFindingsJNode(opts.GroupBy, limit)
+		case len(opts.Fields) > 0:
+			opts.Columns = opts.Fields
+			opts.WideColumns = nil
+			if !opts.Wide {
+				opts.SetFormatter("title", print.TruncateFormatter(70, false))
+				opts.SetFormatter("file", print.TruncateFormatter(65, true))
+			}
+			n = results.getFindingFieldsJNode(opts.Fields)
+		case opts.OutputFormat == "files":
+			// one line per file e.g. for pre-commit hooks
+			opts.Columns = []string{"file", "count", "severity"}
+			opts.WideColumns = nil
+			opts.OutputFormat = "table"
+			opts.NoHeaders = true
+			n = results.getFilesJNode()
+		case opts.OutputFormat == "ndjson":
+			n, err = results.getFindingsJNode()
+		case opts.OutputFormat == "" || opts.OutputFormat == "table":
 			if !opts.Wide {
 				opts.SetFormatter("title", print.TruncateFormatter(70, false))
 				opts.SetFormatter("filePath", print.TruncateFormatter(65, true))
 			}
 			n, err = results.getFindingsJNode()
-		} else {
+			// printResultsToFile writes its own summary
+			summary = opts.OutputFile == ""
+		default:
 			n, err = results.getAssessmentsJNode()
 		}
 		if err != nil {
@@ -94,12 +213,78 @@ func runTool(tool Interface) error {
 		if toolErr == nil || n.Size() > 0 {
 			opts.PrintResult(n)
 		}
+		if summary {
+			_, total := results.getSeverityCounts()
+			log.Infof("Found {primary:%d} failed findings with a scan score of {primary:%s}",
+				total, formatScore(results.getScanScore(opts.GetConfig().getScoreModel())))
+		}
 	}
-	if toolErr != nil {
-		return toolErr
+	return nil
+}
+
+// Print the results in the chosen format to the output file, and a summary
+// of the findings to stdout.
+func printResultsToFile(tool Interface, results Results, toolErr error) error {
+	opts := tool.GetToolOptions()
+	if dir := filepath.Dir(opts.OutputFile); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
 	}
-	if !opts.UploadEnabled {
-		log.Infof("Scan results not uploaded")
+	f, err := os.Create(opts.OutputFile)
+	if err != nil {
+		return err
+	}
+	opts.SetOutput(f)
+	err = printResults(tool, results, toolErr)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	log.Infof("Wrote results to {info:%s}", opts.OutputFile)
+	if !tool.IsNonAssessment() {
+		results.writeSeverityCounts(os.Stdout)
+	}
+	return nil
+}
+
+// Docker not being available exits with a distinct code so that CI can
+// tell a missing docker apart from a failed scan.  Containers that fail
+// for other reasons exit as any other error.
+func withExitCode(err error) error {
+	if IsDockerError(err) {
+		return exit.WithCode(exit.DockerErrorCode, err)
+	}
+	return err
+}
+
+func runPreflight(tool Interface) error {
+	defer tool.GetToolOptions().cleanup()
+	checks := []struct {
+		name  string
+		check func() error
+	}{
+		{"configuration", tool.Validate},
+		{"prerequisites", tool.Preflight},
+	}
+	var failed, dockerFailed bool
+	for _, c := range checks {
+		if err := c.check(); err != nil {
+			log.Errorf("{primary:%s} %s check {danger:failed} - %s", tool.Name(), c.name, err)
+			failed = true
+			dockerFailed = dockerFailed || IsDockerError(err)
+		} else {
+			log.Infof("{primary:%s} %s check {success:passed}", tool.Name(), c.name)
+		}
+	}
+	if failed {
+		err := fmt.Errorf("%s cannot run", tool.Name())
+		if dockerFailed {
+			return exit.WithCode(exit.DockerErrorCode, err)
+		}
+		return err
 	}
 	return nil
 }
@@ -0,0 +1,110 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/exit"
+	"github.com/stretchr/testify/assert"
+)
+
+type testTool struct {
+	ToolOpts
+}
+
+func (*testTool) Name() string { return "test" }
+
+func TestPrintResultsToFile(t *testing.T) {
+	assert := assert.New(t)
+	tool := &testTool{}
+	tool.OutputFormat = "json"
+	tool.OutputFile = filepath.Join(t.TempDir(), "out", "results.json")
+	results := Results{
+		{
+			Findings: assessments.Findings{{Severity: "high", FilePath: "main.tf"}},
+		},
+	}
+	assert.NoError(printResultsToFile(tool, results, nil))
+	dat, err := os.ReadFile(tool.OutputFile)
+	assert.NoError(err)
+	n, err := jnode.FromJSON(dat)
+	if assert.NoError(err) && assert.Equal(1, n.Size()) {
+		assert.Equal("main.tf", n.Get(0).Path("findings").Get(0).Path("filePath").AsText())
+	}
+}
+
+func TestCheckRequiredFindings(t *testing.T) {
+	assert := assert.New(t)
+	tool := &testTool{}
+	empty := Results{{}}
+	assert.NoError(checkRequiredFindings(tool, empty))
+	tool.RequireFindings = true
+	err := checkRequiredFindings(tool, empty)
+	assert.Error(err)
+	assert.Equal(exit.NoFindingsCode, exit.CodeOf(err))
+	assert.NoError(checkRequiredFindings(tool, Results{{}, {Findings: assessments.Findings{{FilePath: "main.tf"}}}}))
+}
+
+func TestPrintFields(t *testing.T) {
+	assert := assert.New(t)
+	tool := &testTool{}
+	tool.Fields = []string{"file", "line", "rule_id", "repo_path"}
+	tool.Path = []string{}
+	tool.OutputFile = filepath.Join(t.TempDir(), "results.csv")
+	tool.OutputFormat = "csv"
+	results := Results{
+		{
+			Findings: assessments.Findings{{Severity: "high", FilePath: "main.tf", Line: 3, RepoPath: "infra/main.tf",
+				Tool: map[string]string{"check_id": "CKV_AWS_1"}}},
+		},
+	}
+	assert.NoError(printResultsToFile(tool, results, nil))
+	dat, err := os.ReadFile(tool.OutputFile)
+	assert.NoError(err)
+	assert.Equal("FILE,LINE,RULE-ID,REPO-PATH\nmain.tf,3,CKV_AWS_1,infra/main.tf\n", string(dat))
+	assert.NoError(validateFields(tool.Fields))
+	err = validateFields([]string{"file", "colour"})
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "colour")
+		assert.Contains(err.Error(), "rule_id, severity")
+	}
+}
+
+type testConsolidatedTool struct {
+	testTool
+	results Results
+}
+
+func (t *testConsolidatedTool) RunAll() (Results, error) { return t.results, nil }
+
+func TestRunToolSkipsProcessedResults(t *testing.T) {
+	assert := assert.New(t)
+	tool := &testConsolidatedTool{
+		results: Results{{processed: true}, {}},
+	}
+	tool.Tool = tool
+	results, err := tool.RunTool()
+	assert.NoError(err)
+	if assert.Len(results, 2) {
+		assert.Empty(results[0].Values["TOOL_NAME"])
+		assert.Equal("test", results[1].Values["TOOL_NAME"])
+		assert.True(results[1].processed)
+	}
+}
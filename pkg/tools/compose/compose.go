@@ -0,0 +1,234 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compose
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/print"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+type Tool struct {
+	tools.DirectoryBasedToolOpts
+	Files []string
+}
+
+type rule struct {
+	id       string
+	severity string
+	title    string
+	// returns the node that violates the rule (or nil) given the name
+	// of a key in a service and its value
+	check func(key string, value *yaml.Node) *yaml.Node
+}
+
+// A finding in a compose file
+type Violation struct {
+	RuleID   string `json:"rule_id"`
+	Severity string `json:"severity"`
+	Title    string `json:"title"`
+	Service  string `json:"service"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+var rules = []*rule{
+	{"COMPOSE_PRIVILEGED", "high", "Service runs in privileged mode", checkValue("privileged", "true")},
+	{"COMPOSE_HOST_NETWORK", "medium", "Service uses the host network", checkValue("network_mode", "host")},
+	{"COMPOSE_HOST_PID", "medium", "Service shares the host PID namespace", checkValue("pid", "host")},
+	{"COMPOSE_HOST_IPC", "medium", "Service shares the host IPC namespace", checkValue("ipc", "host")},
+	{"COMPOSE_DOCKER_SOCKET", "high", "Service mounts the docker socket", checkDockerSocket},
+	{"COMPOSE_CAP_ADD", "high", "Service adds the ALL or SYS_ADMIN capability", checkCapAdd},
+	{"COMPOSE_UNCONFINED", "medium", "Service disables seccomp or AppArmor", checkUnconfined},
+}
+
+var _ tools.Single = &Tool{}
+
+func (*Tool) Name() string {
+	return "docker-compose"
+}
+
+func (t *Tool) Register(cmd *cobra.Command) {
+	t.DirectoryBasedToolOpts.Register(cmd)
+	cmd.Flags().StringSliceVar(&t.Files, "compose-file", nil, "Scan these compose `files` instead of the compose files found in the directory.  May be repeated.")
+}
+
+func (t *Tool) CommandTemplate() *cobra.Command {
+	return &cobra.Command{
+		Use:   "docker-compose",
+		Short: "Scan docker-compose files for insecure service configuration",
+		Long: `Scan docker-compose files for services that run privileged, use the
+host's network, PID or IPC namespaces, mount the docker socket, add
+the ALL or SYS_ADMIN capabilities, or disable seccomp or AppArmor.`,
+	}
+}
+
+func (t *Tool) Run() (*tools.Result, error) {
+	files := t.Files
+	if files == nil {
+		files = t.GetInventory().DockerComposeFiles.Values()
+	}
+	violations := []*Violation{}
+	result := &tools.Result{
+		Directory: t.GetDirectory(),
+		Findings:  assessments.Findings{},
+	}
+	for _, file := range t.RemoveExcluded(files) {
+		dat, err := os.ReadFile(filepath.Join(t.GetDirectory(), file))
+		if err != nil {
+			return nil, err
+		}
+		file = filepath.ToSlash(file)
+		vs, err := scan(file, dat)
+		if err != nil {
+			log.Warnf("Could not parse {warning:%s} - {warning:%s}", file, err)
+			continue
+		}
+		violations = append(violations, vs...)
+	}
+	for _, v := range violations {
+		result.Findings = append(result.Findings, &assessments.Finding{
+			FilePath: v.File,
+			Line:     v.Line,
+			Title:    v.Title,
+			Severity: v.Severity,
+			Tool: map[string]string{
+				"rule_id":  v.RuleID,
+				"service":  v.Service,
+				"severity": v.Severity,
+			},
+		})
+	}
+	n, err := print.ToResult(map[string]interface{}{"violations": violations})
+	if err != nil {
+		return nil, err
+	}
+	result.Data = n
+	return result, nil
+}
+
+// Scan the services of a compose file.
+func scan(file string, dat []byte) ([]*Violation, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(dat, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	services := getMapValue(doc.Content[0], "services")
+	if services == nil || services.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("no services")
+	}
+	var violations []*Violation
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		name, service := services.Content[i].Value, services.Content[i+1]
+		if service.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 0; j+1 < len(service.Content); j += 2 {
+			key, value := service.Content[j], service.Content[j+1]
+			for _, r := range rules {
+				if n := r.check(key.Value, value); n != nil {
+					violations = append(violations, &Violation{
+						RuleID:   r.id,
+						Severity: r.severity,
+						Title:    r.title,
+						Service:  name,
+						File:     file,
+						Line:     n.Line,
+					})
+				}
+			}
+		}
+	}
+	return violations, nil
+}
+
+func getMapValue(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func checkValue(name, bad string) func(string, *yaml.Node) *yaml.Node {
+	return func(key string, value *yaml.Node) *yaml.Node {
+		if key == name && value.Kind == yaml.ScalarNode && strings.EqualFold(value.Value, bad) {
+			return value
+		}
+		return nil
+	}
+}
+
+func checkDockerSocket(key string, value *yaml.Node) *yaml.Node {
+	if key != "volumes" || value.Kind != yaml.SequenceNode {
+		return nil
+	}
+	for _, v := range value.Content {
+		source := v.Value
+		if v.Kind == yaml.MappingNode {
+			// the long syntax
+			if s := getMapValue(v, "source"); s != nil {
+				source = s.Value
+			}
+		} else if colon := strings.IndexByte(source, ':'); colon >= 0 {
+			source = source[:colon]
+		}
+		if strings.HasSuffix(source, "/docker.sock") {
+			return v
+		}
+	}
+	return nil
+}
+
+func checkCapAdd(key string, value *yaml.Node) *yaml.Node {
+	if key != "cap_add" || value.Kind != yaml.SequenceNode {
+		return nil
+	}
+	for _, v := range value.Content {
+		c := strings.TrimPrefix(strings.ToUpper(v.Value), "CAP_")
+		if c == "ALL" || c == "SYS_ADMIN" {
+			return v
+		}
+	}
+	return nil
+}
+
+func checkUnconfined(key string, value *yaml.Node) *yaml.Node {
+	if key != "security_opt" || value.Kind != yaml.SequenceNode {
+		return nil
+	}
+	for _, v := range value.Content {
+		s := strings.ReplaceAll(v.Value, "=", ":")
+		if s == "seccomp:unconfined" || s == "apparmor:unconfined" {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	assert := assert.New(t)
+	tool := &Tool{}
+	tool.Directory = "testdata"
+	result, err := tool.Run()
+	if !assert.NoError(err) {
+		return
+	}
+	type finding struct {
+		rule, service, file string
+		line                int
+	}
+	var findings []finding
+	for _, f := range result.Findings {
+		findings = append(findings, finding{f.Tool["rule_id"], f.Tool["service"], f.FilePath, f.Line})
+	}
+	assert.ElementsMatch([]finding{
+		{"COMPOSE_PRIVILEGED", "agent", "docker-compose.yml", 8},
+		{"COMPOSE_HOST_NETWORK", "agent", "docker-compose.yml", 9},
+		{"COMPOSE_DOCKER_SOCKET", "agent", "docker-compose.yml", 12},
+		{"COMPOSE_CAP_ADD", "tools", "docker-compose.yml", 17},
+		{"COMPOSE_UNCONFINED", "tools", "docker-compose.yml", 19},
+		{"COMPOSE_DOCKER_SOCKET", "tools", "docker-compose.yml", 21},
+		{"COMPOSE_HOST_PID", "db", "legacy/compose.yaml", 4},
+	}, findings)
+	assert.Equal(7, result.Data.Path("violations").Size())
+
+	tool = &Tool{}
+	tool.Directory = "testdata"
+	tool.Exclude = []string{"legacy/**"}
+	assert.NoError(tool.Validate())
+	result, err = tool.Run()
+	assert.NoError(err)
+	assert.Len(result.Findings, 6)
+}
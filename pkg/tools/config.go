@@ -16,6 +16,7 @@ package tools
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 
@@ -50,21 +51,47 @@ func (c *Config) IsIgnored(path string) bool {
 	return c.ignore.MatchesPath(path)
 }
 
+// Returns the names or glob patterns of the environment variables that
+// may be included in uploads, or nil to include the variables of known
+// CI systems.  The config looks like:
+//
+//	env_allowlist:
+//	  - GITHUB_REF
+//	  - BUILD_*
+func (c *Config) GetEnvAllowlist() []string {
+	var allowlist []string
+	for _, e := range c.data.Path("env_allowlist").Elements() {
+		allowlist = append(allowlist, e.AsText())
+	}
+	return allowlist
+}
+
+// Read a config file, returning an empty config if the file can't be
+// read or parsed.
 func ReadConfigFile(path string) *Config {
-	c := &Config{}
-	d, err := ioutil.ReadFile(path)
+	c, err := LoadConfigFile(path)
 	if err != nil {
 		if !errors.Is(err, os.ErrNotExist) {
-			log.Warnf("Could not read {warning:%s} - {warning:%s}", path, err)
+			log.Warnf("{warning:%s}", err)
 		}
-		return c
+		return &Config{}
 	}
-	var m map[string]interface{}
-	err = yaml.Unmarshal(d, &m)
+	return c
+}
+
+// Load a config file, returning an error if it doesn't exist or
+// isn't valid.
+func LoadConfigFile(path string) (*Config, error) {
+	d, err := ioutil.ReadFile(path)
 	if err != nil {
-		log.Warnf("Could not parse {warning:%s} - {warning:%s}", path, err)
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
 	}
-	c.data = jnode.FromMap(m)
-	c.path = path
-	return c
+	var m map[string]interface{}
+	if err := yaml.Unmarshal(d, &m); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	return &Config{
+		data: jnode.FromMap(m),
+		path: path,
+	}, nil
 }
@@ -15,8 +15,11 @@
 package tools
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -25,3 +28,150 @@ func TestConfig(t *testing.T) {
 	o := &ToolOpts{}
 	assert.NotNil(o.GetConfig())
 }
+
+func TestValidateConfigFile(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	o := &ToolOpts{RepoRoot: dir}
+	o.ConfigFile = filepath.Join(dir, "missing.yml")
+	assert.Error(o.Validate())
+	o.ConfigFile = filepath.Join(dir, "bad.yml")
+	assert.NoError(os.WriteFile(o.ConfigFile, []byte("ignore: [\n"), 0600))
+	assert.Error(o.Validate())
+	o.ConfigFile = filepath.Join(dir, "config.yml")
+	assert.NoError(os.WriteFile(o.ConfigFile, []byte("ignore:\n  - \"*.tf\"\n"), 0600))
+	assert.NoError(o.Validate())
+	assert.True(o.GetConfig().IsIgnored("main.tf"))
+}
+
+func TestSeverityOverrides(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "config.yml")
+	assert.NoError(os.WriteFile(path, []byte(`severity_overrides:
+  hadolint:
+    DL3008: critical
+    DL9999: low
+    DL3009: bogus
+`), 0600))
+	c, err := LoadConfigFile(path)
+	assert.NoError(err)
+	overrides := c.GetSeverityOverrides("hadolint")
+	assert.Equal(map[string]string{"DL3008": "critical", "DL9999": "low"}, overrides)
+	assert.Nil(c.GetSeverityOverrides("terrascan"))
+	findings := assessments.Findings{
+		{Tool: map[string]string{"rule_id": "DL3008", "severity": "info"}},
+		{Tool: map[string]string{"rule_id": "DL3007", "severity": "info"}},
+	}
+	assert.Equal([]string{"DL9999"}, applySeverityOverrides(overrides, findings))
+	assert.Equal("critical", findings[0].GetSeverity())
+	assert.Equal("info", findings[1].GetSeverity())
+	a := &assessments.Assessment{Findings: findings}
+	a.EvaluateFailures(map[string]int{"critical": 1})
+	assert.True(a.Failed)
+}
+
+func TestRuleLabels(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "config.yml")
+	assert.NoError(os.WriteFile(path, []byte(`rule_labels:
+  terrascan:
+    "AWS.S3.*":
+      team: platform
+      rule_id: mine
+    "AWS.*":
+      cloud: aws
+      team: cloud
+    "[":
+      team: bogus
+`), 0600))
+	c, err := LoadConfigFile(path)
+	assert.NoError(err)
+	labels := c.getRuleLabels("terrascan")
+	assert.Len(labels, 2)
+	assert.Nil(c.getRuleLabels("hadolint"))
+	findings := assessments.Findings{
+		{Tool: map[string]string{"rule_id": "AWS.S3.DS.High.1043"}},
+		{Tool: map[string]string{"rule_id": "AWS.EC2.DS.High.1001"}},
+		{Tool: map[string]string{"rule_id": "GCP.GCS.1"}},
+	}
+	applyRuleLabels(labels, findings)
+	assert.Equal(map[string]string{"team": "platform", "cloud": "aws", "rule_id": "mine"}, findings[0].Labels)
+	assert.Equal(map[string]string{"team": "cloud", "cloud": "aws"}, findings[1].Labels)
+	assert.Nil(findings[2].Labels)
+	// labels don't touch the tool's own keys
+	assert.Equal("AWS.S3.DS.High.1043", findings[0].Tool["rule_id"])
+}
+
+func TestEnvironments(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "config.yml")
+	assert.NoError(os.WriteFile(path, []byte(`environments:
+  prod:
+    branches: [main, "release/*"]
+    fail: [high]
+  staging:
+    branches: ["release/*", "staging"]
+    fail: critical=2
+  dev:
+    fail: [critical=5, medium=10]
+`), 0600))
+	c, err := LoadConfigFile(path)
+	assert.NoError(err)
+	assert.Equal("prod", c.FindEnvironment("main"))
+	assert.Equal("prod", c.FindEnvironment("release/1.2"))
+	assert.Equal("staging", c.FindEnvironment("staging"))
+	assert.Equal("", c.FindEnvironment("feature/x"))
+	assert.Equal("", c.FindEnvironment(""))
+	assert.Equal([]string{"high"}, c.GetEnvironmentFailThresholds("prod"))
+	assert.Equal([]string{"critical=2"}, c.GetEnvironmentFailThresholds("staging"))
+	assert.Equal([]string{"critical=5", "medium=10"}, c.GetEnvironmentFailThresholds("dev"))
+	assert.Nil(c.GetEnvironmentFailThresholds("qa"))
+	assert.Nil((&Config{}).GetEnvironmentFailThresholds("prod"))
+	assert.True(c.HasEnvironment("dev"))
+	assert.False(c.HasEnvironment("qa"))
+	assert.False((&Config{}).HasEnvironment("prod"))
+}
+
+func TestScanScore(t *testing.T) {
+	assert := assert.New(t)
+	findings := assessments.Findings{
+		{Severity: "critical"},
+		{Severity: "high"},
+		{Severity: "high", Pass: true},
+		{Severity: "low"},
+		{Severity: "bogus"},
+	}
+	assert.Equal(float64(16), (&Config{}).getScoreModel().score(findings))
+	path := filepath.Join(t.TempDir(), "config.yml")
+	assert.NoError(os.WriteFile(path, []byte(`scan_score:
+  weights:
+    critical: 25
+    Low: 0.5
+    medium: -1
+`), 0600))
+	c, err := LoadConfigFile(path)
+	assert.NoError(err)
+	m := c.getScoreModel()
+	assert.Equal(float64(2), m.weights["medium"])
+	assert.Equal(30.5, m.score(findings))
+	assert.Equal("30.5", formatScore(m.score(findings)))
+	assert.NoError(os.WriteFile(path, []byte(`scan_score:
+  formula: max
+`), 0600))
+	c, err = LoadConfigFile(path)
+	assert.NoError(err)
+	assert.Equal(float64(10), c.getScoreModel().score(findings))
+}
+
+func TestEnvAllowlist(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "config.yml")
+	assert.NoError(os.WriteFile(path, []byte(`env_allowlist:
+  - GITHUB_REF
+  - BUILD_*
+`), 0600))
+	c, err := LoadConfigFile(path)
+	assert.NoError(err)
+	assert.Equal([]string{"GITHUB_REF", "BUILD_*"}, c.GetEnvAllowlist())
+	assert.Nil((&Config{}).GetEnvAllowlist())
+}
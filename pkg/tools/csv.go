@@ -0,0 +1,72 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+var defaultCSVFields = []string{"file", "line", "severity", "rule_id", "tool", "description"}
+
+// Write the findings as CSV with a header row, and one row for each
+// finding with its file, line, severity, rule id, tool, and description.
+func (results Results) WriteCSV(w io.Writer) error {
+	return results.WriteCSVFields(w, defaultCSVFields)
+}
+
+// Write the findings as CSV with only the chosen fields (see --fields.)
+func (results Results) WriteCSVFields(w io.Writer, fields []string) error {
+	if err := validateFields(fields); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(fields); err != nil {
+		return err
+	}
+	for _, e := range results.getFindingFieldsJNode(fields).Elements() {
+		row := make([]string, len(fields))
+		for i, name := range fields {
+			row[i] = e.Path(name).AsText()
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func saveCSV(path string, results Results, fields []string) error {
+	if len(fields) == 0 {
+		fields = defaultCSVFields
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := results.WriteCSVFields(f, fields); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	log.Infof("Wrote findings as CSV to {info:%s}", path)
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteCSV(t *testing.T) {
+	assert := assert.New(t)
+	buf := &bytes.Buffer{}
+	assert.NoError(Results{}.WriteCSV(buf))
+	assert.Equal("file,line,severity,rule_id,tool,description\n", buf.String())
+	results := Results{
+		{
+			Values: map[string]string{"TOOL_NAME": "checkov"},
+			Findings: assessments.Findings{
+				{FilePath: "main.tf", Line: 3, Severity: "High", Description: "Bucket \"logs\" is public,\nfix it",
+					Tool: map[string]string{"check_id": "CKV_AWS_20"}},
+			},
+		},
+	}
+	buf.Reset()
+	assert.NoError(results.WriteCSV(buf))
+	assert.Equal(`file,line,severity,rule_id,tool,description
+main.tf,3,high,CKV_AWS_20,checkov,"Bucket ""logs"" is public,
+fix it"
+`, buf.String())
+	buf.Reset()
+	assert.NoError(results.WriteCSVFields(buf, []string{"rule_id", "line"}))
+	assert.Equal("rule_id,line\nCKV_AWS_20,3\n", buf.String())
+	assert.Error(results.WriteCSVFields(buf, []string{"colour"}))
+}
@@ -15,7 +15,10 @@
 package tools
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -23,16 +26,30 @@ import (
 	"github.com/soluble-ai/soluble-cli/pkg/inventory"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
+	"github.com/soluble-ai/soluble-cli/pkg/xcp"
 	"github.com/spf13/cobra"
 )
 
 type DirectoryBasedToolOpts struct {
 	ToolOpts
-	Directory string
-	Exclude   []string
+	Directory   string
+	Exclude     []string
+	IncludePath []string
+	GitRef      string
+	Repo        string
+	FilesFrom   string
+	File        string
+	Watch       bool
 
-	absDirectory string
-	ignore       *ignore.GitIgnore
+	absDirectory  string
+	files         *util.StringSet
+	stdin         io.Reader
+	ignore        *ignore.GitIgnore
+	include       *ignore.GitIgnore
+	gitExport     *xcp.GitExport
+	solubleIgnore *SolubleIgnore
+	// the directory of --file, which the tool is run in
+	fileDirectory string
 }
 
 func (o *DirectoryBasedToolOpts) GetDirectoryBasedToolOptions() *DirectoryBasedToolOpts {
@@ -40,6 +57,9 @@ func (o *DirectoryBasedToolOpts) GetDirectoryBasedToolOptions() *DirectoryBasedT
 }
 
 func (o *DirectoryBasedToolOpts) GetDirectory() string {
+	if o.fileDirectory != "" {
+		return o.fileDirectory
+	}
 	if o.absDirectory == "" {
 		dir := o.Directory
 		if dir == "" {
@@ -59,6 +79,8 @@ func (o *DirectoryBasedToolOpts) GetInventory() *inventory.Manifest {
 	m := inventory.Do(o.GetDirectory())
 	m.CloudformationFiles = o.removeExcludedStringSet(m.CloudformationFiles)
 	m.DockerDirectories = o.removeExcludedStringSet(m.DockerDirectories)
+	m.DockerComposeFiles = o.removeExcludedStringSet(m.DockerComposeFiles)
+	m.IAMPolicyFiles = o.removeExcludedStringSet(m.IAMPolicyFiles)
 	m.HelmCharts = o.removeExcludedStringSet(m.HelmCharts)
 	m.KubernetesManifestDirectories = o.removeExcludedStringSet(m.KubernetesManifestDirectories)
 	m.TerraformRootModules = o.removeExcludedStringSet(m.TerraformRootModules)
@@ -102,9 +124,22 @@ func (o *DirectoryBasedToolOpts) removeExcludedStringSet(ss util.StringSet) util
 }
 
 func (o *DirectoryBasedToolOpts) IsExcluded(file string) bool {
-	if o.ignore != nil {
+	if o.ignore != nil || o.include != nil {
 		rfile := MustRel(o.GetDirectory(), file)
-		if o.ignore.MatchesPath(rfile) {
+		if o.ignore != nil && o.ignore.MatchesPath(rfile) {
+			return true
+		}
+		if o.include != nil && !o.include.MatchesPath(rfile) && !o.isDir(rfile) {
+			// --include-path only applies to files
+			return true
+		}
+	}
+	if o.files != nil {
+		rfile := filepath.Clean(file)
+		if filepath.IsAbs(rfile) {
+			rfile = MustRel(o.GetDirectory(), rfile)
+		}
+		if !o.files.Contains(rfile) && !o.isDir(rfile) {
 			return true
 		}
 	}
@@ -112,7 +147,164 @@ func (o *DirectoryBasedToolOpts) IsExcluded(file string) bool {
 		return false
 	}
 	rfile := MustRel(o.RepoRoot, file)
-	return o.GetConfig().IsIgnored(rfile)
+	if o.GetConfig().IsIgnored(rfile) {
+		return true
+	}
+	var toolName string
+	if o.Tool != nil {
+		toolName = o.Tool.Name()
+	}
+	return o.getSolubleIgnore().IsIgnored(toolName, rfile)
+}
+
+func (o *DirectoryBasedToolOpts) isDir(rfile string) bool {
+	fi, err := os.Stat(filepath.Join(o.GetDirectory(), rfile))
+	return err == nil && fi.IsDir()
+}
+
+// Read the list of files to scan from --files-from.  The files are
+// relative to the directory (absolute paths must be within it) and
+// must exist.
+func (o *DirectoryBasedToolOpts) readFilesFrom() error {
+	var r io.Reader
+	if o.FilesFrom == "-" {
+		r = o.stdin
+		if r == nil {
+			r = os.Stdin
+		}
+	} else {
+		f, err := os.Open(o.FilesFrom)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	dir := o.GetDirectory()
+	files := util.NewStringSet()
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		file := strings.TrimSpace(scanner.Text())
+		if file == "" {
+			continue
+		}
+		rfile := filepath.Clean(file)
+		if filepath.IsAbs(rfile) {
+			rfile = MustRel(dir, rfile)
+		}
+		if rfile == ".." || strings.HasPrefix(rfile, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("file %s from --files-from must be in %s", file, dir)
+		}
+		if _, err := os.Stat(filepath.Join(dir, rfile)); err != nil {
+			return fmt.Errorf("file %s from --files-from does not exist in %s", file, dir)
+		}
+		files.Add(rfile)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	log.Debugf("Restricting results to {info:%d} files from --files-from", files.Len())
+	o.files = files
+	return nil
+}
+
+// Run in the directory of --file, and only report results in it.
+func (o *DirectoryBasedToolOpts) setFile() error {
+	if o.FilesFrom != "" {
+		return fmt.Errorf("--file and --files-from cannot be used together")
+	}
+	o.fileDirectory = ""
+	path := o.File
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(o.GetDirectory(), path)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("invalid --file: %w", err)
+	}
+	if fi.IsDir() {
+		return fmt.Errorf("--file %s is a directory, use --directory instead", o.File)
+	}
+	o.fileDirectory = filepath.Dir(path)
+	o.files = util.NewStringSet()
+	o.files.Add(filepath.Base(path))
+	return nil
+}
+
+// Returns the files that the scan is restricted to by --files-from or
+// --file, relative to the directory, or nil if the whole directory is
+// being scanned.  Tools that accept a list of files should pass these
+// to the tool; otherwise findings in other files are excluded.
+func (o *DirectoryBasedToolOpts) GetFiles() []string {
+	if o.files == nil {
+		return nil
+	}
+	return o.files.Values()
+}
+
+// Returns the name of the --file in the directory, or "" if the whole
+// directory is being scanned.
+func (o *DirectoryBasedToolOpts) GetFile() string {
+	if o.File == "" {
+		return ""
+	}
+	return filepath.Base(o.File)
+}
+
+func (o *DirectoryBasedToolOpts) getSolubleIgnore() *SolubleIgnore {
+	if o.solubleIgnore == nil {
+		o.solubleIgnore = ReadSolubleIgnore(filepath.Join(o.RepoRoot, solubleIgnoreFile))
+	}
+	return o.solubleIgnore
+}
+
+// Export the tree of GitRef to a temporary directory, and run
+// there instead.
+func (o *DirectoryBasedToolOpts) exportGitRef() error {
+	dir := o.GetDirectory()
+	repoRoot, err := inventory.FindRepoRoot(dir)
+	if err != nil {
+		return err
+	}
+	if repoRoot == "" {
+		return fmt.Errorf("--git-ref requires %s to be in a git repository", dir)
+	}
+	rel, err := filepath.Rel(repoRoot, dir)
+	if err != nil {
+		return err
+	}
+	e, err := xcp.ExportGitRef(repoRoot, o.GitRef)
+	if err != nil {
+		return err
+	}
+	o.setGitExport(e, rel)
+	return nil
+}
+
+// Clone Repo to a temporary directory, and run there
+func (o *DirectoryBasedToolOpts) cloneRepo() error {
+	if filepath.IsAbs(o.Directory) {
+		return fmt.Errorf("--directory must be relative to the repository root with --repo")
+	}
+	e, err := xcp.CloneGitRepo(o.Repo, o.GitRef)
+	if err != nil {
+		return err
+	}
+	o.setGitExport(e, o.Directory)
+	return nil
+}
+
+func (o *DirectoryBasedToolOpts) setGitExport(e *xcp.GitExport, rel string) {
+	o.gitExport = e
+	o.AddCleanup(func() {
+		if err := e.Remove(); err != nil {
+			log.Warnf("Could not remove {warning:%s} - {warning:%s}", e.Dir, err)
+		}
+	})
+	o.Directory = filepath.Join(e.Dir, rel)
+	o.absDirectory = ""
+	o.RepoRoot = e.Dir
+	o.repoRootSet = true
 }
 
 // Return the directory that a docker-based tool is run in.  Normally
@@ -129,10 +321,37 @@ func (o *DirectoryBasedToolOpts) Register(cmd *cobra.Command) {
 	flags := cmd.Flags()
 	flags.StringVarP(&o.Directory, "directory", "d", "", "The directory to run in.")
 	flags.StringSliceVar(&o.Exclude, "exclude", nil, "Exclude results from file that match this glob pattern (path/**/foo.txt syntax supported.)  May be repeated.")
+	flags.StringSliceVar(&o.IncludePath, "include-path", nil, "Only include results from files that match this glob pattern (same syntax as --exclude.)  May be repeated.  Files that match --exclude are always excluded.")
+	flags.StringVar(&o.GitRef, "git-ref", "", "Scan the tree of this git `ref` (e.g. a commit sha) without checking it out.  With --repo, the ref to clone.")
+	flags.StringVar(&o.FilesFrom, "files-from", "", "Only scan the newline-separated files listed in `file` (use - to read from stdin.)  The files are relative to --directory and must exist.")
+	flags.StringVar(&o.File, "file", "", "Only scan `file`, e.g. for a quick check from an editor.  The file is relative to --directory, and the tool is run in the file's directory with the results restricted to the file.")
+	flags.BoolVar(&o.Watch, "watch", false, "Run the scan again whenever the files in --directory change, printing the findings each time.  Files ignored by .gitignore or excluded from the scan are not watched.  Implies --upload=false.")
+	flags.StringVar(&o.Repo, "repo", "", "Shallow clone the git repository at `url` to a temporary directory and scan it.  The --directory is relative to the root of the repository.")
 }
 
 func (o *DirectoryBasedToolOpts) Validate() error {
 	o.absDirectory = ""
+	if o.Watch {
+		if o.Repo != "" || o.GitRef != "" {
+			return fmt.Errorf("--watch cannot be used with --repo or --git-ref")
+		}
+		o.UploadEnabled = false
+	}
+	if o.Repo != "" && o.gitExport == nil {
+		if err := o.cloneRepo(); err != nil {
+			return err
+		}
+	}
+	if o.GitRef != "" && o.gitExport == nil {
+		if err := o.exportGitRef(); err != nil {
+			return err
+		}
+	}
+	if o.File != "" && o.files == nil {
+		if err := o.setFile(); err != nil {
+			return err
+		}
+	}
 	if o.RepoRoot == "" {
 		var err error
 		o.RepoRoot, err = inventory.FindRepoRoot(o.GetDirectory())
@@ -150,5 +369,16 @@ func (o *DirectoryBasedToolOpts) Validate() error {
 			log.Warnf("Invalid exclude pattern {warning:%s}", strings.Join(o.Exclude, ","))
 		}
 	}
+	if len(o.IncludePath) > 0 {
+		o.include = ignore.CompileIgnoreLines(o.IncludePath...)
+		if o.include == nil {
+			log.Warnf("Invalid include pattern {warning:%s}", strings.Join(o.IncludePath, ","))
+		}
+	}
+	if o.FilesFrom != "" && o.files == nil {
+		if err := o.readFilesFrom(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -18,6 +18,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -68,3 +69,75 @@ func TestGetInventory(t *testing.T) {
 	m := o.GetInventory()
 	assert.NotNil(m)
 }
+
+func TestDirectoryOptsIncludePath(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	createFile(dir, "main.tf", "")
+	createFile(dir, "modules/vpc/vpc.tf", "")
+	createFile(dir, "modules/vpc/README.md", "")
+	createFile(dir, "modules/vpc/test.tf", "")
+	o := &DirectoryBasedToolOpts{
+		Directory:   dir,
+		IncludePath: []string{"*.tf"},
+		Exclude:     []string{"test.tf"},
+	}
+	assert.Nil(o.Validate())
+	assert.Equal([]string{"main.tf", "modules/vpc/vpc.tf"}, o.RemoveExcluded([]string{
+		"main.tf", "modules/vpc/vpc.tf", "modules/vpc/README.md", "modules/vpc/test.tf",
+	}))
+	// directories aren't excluded by --include-path
+	assert.False(o.IsExcluded(filepath.Join(dir, "modules/vpc")))
+}
+
+func TestDirectoryOptsFilesFrom(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	createFile(dir, "main.tf", "")
+	createFile(dir, "modules/vpc/vpc.tf", "")
+	createFile(dir, "modules/vpc/test.tf", "")
+	o := &DirectoryBasedToolOpts{
+		Directory: dir,
+		FilesFrom: "-",
+		stdin:     strings.NewReader("main.tf\n\n" + filepath.Join(dir, "modules/vpc/vpc.tf") + "\n"),
+	}
+	assert.Nil(o.Validate())
+	assert.Equal([]string{"main.tf", "modules/vpc/vpc.tf"}, o.RemoveExcluded([]string{
+		"main.tf", "modules/vpc/vpc.tf", "modules/vpc/test.tf",
+	}))
+	assert.True(o.IsExcluded(filepath.Join(dir, "modules/vpc/test.tf")))
+	assert.False(o.IsExcluded(filepath.Join(dir, "modules/vpc")))
+	o = &DirectoryBasedToolOpts{
+		Directory: dir,
+		FilesFrom: "-",
+		stdin:     strings.NewReader("missing.tf\n"),
+	}
+	assert.Error(o.Validate())
+	o = &DirectoryBasedToolOpts{
+		Directory: dir,
+		FilesFrom: "-",
+		stdin:     strings.NewReader("../main.tf\n"),
+	}
+	assert.Error(o.Validate())
+}
+
+func TestDirectoryOptsFile(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	createFile(dir, "modules/vpc/vpc.tf", "")
+	createFile(dir, "modules/vpc/test.tf", "")
+	o := &DirectoryBasedToolOpts{Directory: dir, File: "modules/vpc/vpc.tf"}
+	assert.NoError(o.Validate())
+	assert.Equal(filepath.Join(dir, "modules", "vpc"), o.GetDirectory())
+	assert.Equal(dir, o.Directory)
+	assert.Equal("vpc.tf", o.GetFile())
+	assert.Equal([]string{"vpc.tf"}, o.RemoveExcluded([]string{"vpc.tf", "test.tf"}))
+	assert.True(o.IsExcluded(filepath.Join(dir, "modules/vpc/test.tf")))
+	// validating again (e.g. with --watch) finds the same file
+	o.files = nil
+	assert.NoError(o.Validate())
+	assert.Equal(filepath.Join(dir, "modules", "vpc"), o.GetDirectory())
+	assert.Error((&DirectoryBasedToolOpts{Directory: dir, File: "missing.tf"}).Validate())
+	assert.Error((&DirectoryBasedToolOpts{Directory: dir, File: "modules"}).Validate())
+	assert.Error((&DirectoryBasedToolOpts{Directory: dir, File: "modules/vpc/vpc.tf", FilesFrom: "-"}).Validate())
+}
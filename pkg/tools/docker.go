@@ -15,18 +15,36 @@
 package tools
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"regexp"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/tracing"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// Docker is not available, e.g. it isn't installed or the server isn't
+// running
 type DockerError string
 
+var (
+	containerNameRe = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+	envVarRe        = regexp.MustCompile(`\$\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+	containerCount  int32
+)
+
 type DockerTool struct {
 	Name                string
 	Image               string
@@ -37,6 +55,14 @@ type DockerTool struct {
 	Stdout              io.Writer
 	Stderr              io.Writer
 	Directory           string
+	// The platform of the image to run, e.g. linux/amd64
+	Platform string
+	// If the container produces no output for this long, kill it
+	IdleTimeout time.Duration
+
+	containerName   string
+	traceCtx        context.Context
+	extraDockerArgs []string
 }
 
 func (d DockerError) Error() string {
@@ -80,32 +106,200 @@ func (t *DockerTool) run(skipPull bool) ([]byte, error) {
 	if err := hasDocker(); err != nil {
 		return nil, err
 	}
-	if !skipPull {
-		// #nosec G204
-		pull := exec.Command("docker", "pull", t.Image)
-		out, err := pull.Output()
+	image := attribute.String("docker.image", t.Image)
+	if !skipPull && !isImagePulled(t.Image, t.Platform) {
+		_, span := tracing.StartSpan(t.traceCtx, "docker.pull", image)
+		out, err := pullImage(t.Image, t.Platform)
 		if err != nil {
 			os.Stderr.Write(out)
 			log.Warnf("docker pull {primary:%s} failed: {warning:%s}", t.Image, err)
 		}
+		tracing.EndSpan(span, err)
+	}
+	_, span := tracing.StartSpan(t.traceCtx, "docker.run", image)
+	out, err := t.runContainer()
+	tracing.EndSpan(span, err)
+	return out, err
+}
+
+// The images that have been pulled during this run
+var pulledImages = struct {
+	sync.Mutex
+	images map[string]bool
+}{images: map[string]bool{}}
+
+func isImagePulled(image, platform string) bool {
+	pulledImages.Lock()
+	defer pulledImages.Unlock()
+	return pulledImages.images[platform+" "+image]
+}
+
+func pullImage(image, platform string) ([]byte, error) {
+	pullArgs := []string{"pull"}
+	if platform != "" {
+		pullArgs = append(pullArgs, "--platform", platform)
 	}
+	// #nosec G204
+	pull := exec.Command("docker", append(pullArgs, image)...)
+	out, err := pull.CombinedOutput()
+	if err == nil {
+		pulledImages.Lock()
+		pulledImages.images[platform+" "+image] = true
+		pulledImages.Unlock()
+	}
+	return out, err
+}
+
+// Pull images concurrently (bounded by --parallelism) before running
+// the tools that use them, so that the tools don't each wait for their
+// own pull.  If an image can't be pulled here then the tool that runs
+// it tries again.
+func PullImages(images []string) {
+	seen := util.NewStringSet()
+	var wg sync.WaitGroup
+	for _, image := range images {
+		if image == "" || !seen.Add(image) || isImagePulled(image, "") {
+			continue
+		}
+		wg.Add(1)
+		go func(image string) {
+			defer wg.Done()
+			defer AcquireWorker()()
+			if _, err := pullImage(image, ""); err != nil {
+				log.Debugf("docker pull {primary:%s} failed: {warning:%s}", image, err)
+			}
+		}(image)
+	}
+	if seen.Len() > 0 {
+		log.Infof("Pulling {info:%d} docker images", seen.Len())
+	}
+	wg.Wait()
+}
+
+func (t *DockerTool) runContainer() ([]byte, error) {
 	args := t.getArgs(os.Getenv)
 	run := exec.Command("docker", args...)
-	log.Infof("Running {primary:%s}", strings.Join(run.Args, " "))
+	log.Infof("Running {primary:%s}", formatCommand(t.Image, run.Args))
 	run.Stdin = os.Stdin
-	run.Stderr = os.Stderr
+	stderr := &bytes.Buffer{}
+	stdout := &bytes.Buffer{}
+	activity := &activityWriter{}
+	activity.touch()
+	run.Stderr = activity.wrap(io.MultiWriter(os.Stderr, stderr))
 	if t.Stderr != nil {
-		run.Stderr = t.Stderr
+		run.Stderr = activity.wrap(io.MultiWriter(t.Stderr, stderr))
 	}
+	run.Stdout = activity.wrap(stdout)
 	if t.Stdout != nil {
-		run.Stdout = t.Stdout
-		return nil, run.Run()
+		run.Stdout = activity.wrap(t.Stdout)
+	}
+	err := run.Start()
+	if err != nil {
+		return nil, err
+	}
+	var stalled int32
+	done := make(chan struct{})
+	if t.IdleTimeout > 0 {
+		go t.watchdog(activity, done, &stalled)
+	}
+	err = run.Wait()
+	close(done)
+	var out []byte
+	if t.Stdout == nil {
+		out = stdout.Bytes()
+	}
+	if atomic.LoadInt32(&stalled) != 0 {
+		return out, &ToolStalledError{Image: t.Image, IdleTimeout: t.IdleTimeout}
+	}
+	if err != nil && isPlatformMismatch(stderr.String()) {
+		log.Errorf("The image {primary:%s} does not support the {danger:%s/%s} platform", t.Image, runtime.GOOS, runtime.GOARCH)
+		log.Infof("Use {primary:--docker-platform linux/amd64} to run the image under emulation, or {primary:--no-docker} to run the tool locally")
+		return out, &ImagePlatformError{Image: t.Image}
+	}
+	return out, err
+}
+
+// Kill the container if it hasn't produced any output for IdleTimeout.
+func (t *DockerTool) watchdog(activity *activityWriter, done <-chan struct{}, stalled *int32) {
+	interval := t.IdleTimeout / 10
+	if interval > time.Second {
+		interval = time.Second
 	}
-	return run.Output()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if activity.idle() >= t.IdleTimeout {
+				log.Errorf("{primary:%s} has produced no output for {danger:%s}, killing it", t.Image, t.IdleTimeout)
+				atomic.StoreInt32(stalled, 1)
+				// #nosec G204
+				if err := exec.Command("docker", "kill", t.containerName).Run(); err != nil {
+					log.Warnf("Could not kill {warning:%s} - {warning:%s}", t.containerName, err)
+				}
+				return
+			}
+		}
+	}
+}
+
+// Tracks the last time anything was written to any of the wrapped writers
+type activityWriter struct {
+	last int64
+}
+
+type activityWriterFunc func(p []byte) (int, error)
+
+func (f activityWriterFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
+func (a *activityWriter) touch() {
+	atomic.StoreInt64(&a.last, time.Now().UnixNano())
+}
+
+func (a *activityWriter) idle() time.Duration {
+	return time.Since(time.Unix(0, atomic.LoadInt64(&a.last)))
+}
+
+func (a *activityWriter) wrap(w io.Writer) io.Writer {
+	return activityWriterFunc(func(p []byte) (int, error) {
+		a.touch()
+		return w.Write(p)
+	})
+}
+
+// Returns true if docker's error output indicates that the image
+// was built for a different architecture
+func isPlatformMismatch(stderr string) bool {
+	for _, s := range []string{
+		"exec format error",
+		"does not match the detected host platform",
+		"no matching manifest for",
+	} {
+		if strings.Contains(stderr, s) {
+			return true
+		}
+	}
+	return false
 }
 
 func (t *DockerTool) getArgs(getenv func(string) string) []string {
-	args := []string{"run", "--rm"}
+	if t.containerName == "" {
+		// name the container so it can be killed if it stalls
+		name := t.Name
+		if name == "" {
+			name = t.Image
+		}
+		t.containerName = fmt.Sprintf("soluble-%s-%d-%d", containerNameRe.ReplaceAllString(name, "-"),
+			os.Getpid(), atomic.AddInt32(&containerCount, 1))
+	}
+	args := []string{"run", "--rm", "--name", t.containerName}
+	if t.Platform != "" {
+		args = append(args, "--platform", t.Platform)
+	}
 	if t.Directory != "" {
 		args = append(args, "-v", fmt.Sprintf("%s:/src", t.Directory),
 			"-w", "/src")
@@ -119,13 +313,35 @@ func (t *DockerTool) getArgs(getenv func(string) string) []string {
 			}
 		}
 	}
-	args = append(args, t.DockerArgs...)
+	args = append(args, expandEnvArgs(getenv, t.DockerArgs)...)
+	args = append(args, t.extraDockerArgs...)
 	args = appendProxyEnv(getenv, args)
 	args = append(args, t.Image)
 	args = append(args, t.Args...)
 	return args
 }
 
+// Expand ${VAR} in args, except for volume specs.  Unknown variables
+// expand to "".
+func expandEnvArgs(getenv func(string) string, args []string) []string {
+	result := make([]string, len(args))
+	for i, arg := range args {
+		if i > 0 && (args[i-1] == "-v" || args[i-1] == "--volume") || strings.HasPrefix(arg, "--volume=") {
+			result[i] = arg
+			continue
+		}
+		result[i] = envVarRe.ReplaceAllStringFunc(arg, func(s string) string {
+			name := s[2 : len(s)-1]
+			v := getenv(name)
+			if v == "" {
+				log.Warnf("The environment variable {warning:%s} is not set", name)
+			}
+			return v
+		})
+	}
+	return result
+}
+
 func appendProxyEnv(getenv func(string) string, args []string) []string {
 	for _, k := range []string{
 		"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY",
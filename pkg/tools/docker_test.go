@@ -16,10 +16,16 @@ package tools
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/stretchr/testify/assert"
 )
@@ -94,3 +100,93 @@ func TestDockerGetArgs(t *testing.T) {
 	assert.True(mem)
 	assert.True(dir)
 }
+
+func TestPreflightDocker(t *testing.T) {
+	assert := assert.New(t)
+	o := &RunOpts{NoDocker: true}
+	assert.Nil(o.PreflightDocker())
+	assert.Nil((&ToolOpts{}).Preflight())
+}
+
+func TestDockerGetArgsPlatform(t *testing.T) {
+	assert := assert.New(t)
+	dt := &DockerTool{
+		Image:    "test",
+		Platform: "linux/amd64",
+	}
+	args := dt.getArgs(func(string) string { return "" })
+	assert.Equal([]string{"run", "--rm", "--name", dt.containerName, "--platform", "linux/amd64", "test"}, args)
+	assert.Regexp(`^soluble-test-\d+-\d+$`, dt.containerName)
+	assert.True(isPlatformMismatch("standard_init_linux.go:228: exec user process caused: exec format error"))
+	assert.False(isPlatformMismatch("permission denied"))
+}
+
+func TestDockerWatchdog(t *testing.T) {
+	assert := assert.New(t)
+	dt := &DockerTool{
+		IdleTimeout:   10 * time.Millisecond,
+		containerName: "soluble-test-watchdog",
+	}
+	activity := &activityWriter{}
+	activity.touch()
+	var stalled int32
+	done := make(chan struct{})
+	dt.watchdog(activity, done, &stalled)
+	assert.Equal(int32(1), stalled)
+	assert.GreaterOrEqual(activity.idle(), dt.IdleTimeout)
+	_, _ = activity.wrap(io.Discard).Write([]byte("x"))
+	assert.Less(activity.idle(), dt.IdleTimeout)
+}
+
+func TestDockerGetArgsExpandEnv(t *testing.T) {
+	assert := assert.New(t)
+	dt := &DockerTool{
+		Image:      "test",
+		DockerArgs: []string{"-e", "CACHE=${CACHE_DIR}/tool", "-v", "${HOME}:/home", "-e", "TOKEN=$NOT_EXPANDED${UNKNOWN}"},
+		Args:       []string{"${foo}.tf"},
+		Directory:  "/tmp/${foo}",
+
+		extraDockerArgs: []string{"-e", "USER=${foo}"},
+	}
+	env := map[string]string{"CACHE_DIR": "/cache", "HOME": "/home/me", "foo": "bar"}
+	args := dt.getArgs(func(k string) string { return env[k] })
+	assert.Equal([]string{"run", "--rm", "--name", dt.containerName,
+		"-v", "/tmp/${foo}:/src", "-w", "/src",
+		"-e", "CACHE=/cache/tool", "-v", "${HOME}:/home", "-e", "TOKEN=$NOT_EXPANDED",
+		"-e", "USER=${foo}", "test", "${foo}.tf"}, args)
+}
+
+func TestFormatCommand(t *testing.T) {
+	assert := assert.New(t)
+	args := []string{"docker", "run", "--rm", "-e", "FOO=bar", "hadolint/hadolint"}
+	func() {
+		defer log.SetTempLevel(log.Info).Restore()
+		assert.Equal("hadolint/hadolint", formatCommand("hadolint/hadolint", args))
+	}()
+	defer log.SetTempLevel(log.Debug).Restore()
+	assert.Equal("docker run --rm -e FOO=bar hadolint/hadolint", formatCommand("hadolint/hadolint", args))
+}
+
+func TestPullImages(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a shell script")
+	}
+	assert := assert.New(t)
+	dir := t.TempDir()
+	pulls := filepath.Join(dir, "pulls")
+	assert.NoError(os.WriteFile(filepath.Join(dir, "docker"),
+		[]byte("#!/bin/sh\n[ \"$2\" = bad/image ] && exit 1\necho \"$2\" >> "+pulls+"\n"), 0700))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	PullImages([]string{"test/a:latest", "test/b:latest", "", "test/a:latest", "bad/image"})
+	d, err := os.ReadFile(pulls)
+	assert.NoError(err)
+	lines := strings.Fields(string(d))
+	assert.ElementsMatch([]string{"test/a:latest", "test/b:latest"}, lines)
+	assert.True(isImagePulled("test/a:latest", ""))
+	assert.False(isImagePulled("bad/image", ""))
+	assert.False(isImagePulled("test/a:latest", "linux/amd64"))
+	// already pulled images aren't pulled again
+	PullImages([]string{"test/a:latest"})
+	d, _ = os.ReadFile(pulls)
+	assert.Equal(lines, strings.Fields(string(d)))
+}
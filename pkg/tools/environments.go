@@ -0,0 +1,88 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"path"
+	"sort"
+
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+// Environments let the same scan be gated differently depending on
+// where it runs, e.g.:
+//
+//	environments:
+//	  prod:
+//	    branches: [main, "release/*"]
+//	    fail: [high]
+//	  dev:
+//	    fail: [critical=5]
+//
+// The environment is given explicitly or selected by matching the
+// git branch against each environment's branch patterns.
+func (c *Config) GetEnvironmentFailThresholds(environment string) []string {
+	n := c.data.Path("environments").Path(environment).Path("fail")
+	if n.IsArray() {
+		thresholds := make([]string, 0, n.Size())
+		for _, e := range n.Elements() {
+			thresholds = append(thresholds, e.AsText())
+		}
+		return thresholds
+	}
+	if s := n.AsText(); s != "" {
+		return []string{s}
+	}
+	return nil
+}
+
+// Returns true if the config defines any environments.
+func (c *Config) HasEnvironments() bool {
+	return c.data.Path("environments").Size() > 0
+}
+
+// Returns true if the config defines environment.
+func (c *Config) HasEnvironment(environment string) bool {
+	_, ok := c.data.Path("environments").Entries()[environment]
+	return ok
+}
+
+// Returns the first environment (by name) with a branch pattern that
+// matches branch, or "" if there isn't one.
+func (c *Config) FindEnvironment(branch string) string {
+	if branch == "" {
+		return ""
+	}
+	environments := c.data.Path("environments")
+	var names []string
+	for name := range environments.Entries() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, p := range environments.Path(name).Path("branches").Elements() {
+			ok, err := path.Match(p.AsText(), branch)
+			if err != nil {
+				log.Warnf("Ignoring invalid branch pattern {warning:%s} for environment {info:%s} in {secondary:%s}",
+					p.AsText(), name, c.path)
+				continue
+			}
+			if ok {
+				return name
+			}
+		}
+	}
+	return ""
+}
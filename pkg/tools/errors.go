@@ -0,0 +1,144 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+)
+
+// The program for a tool could not be found or installed
+type ToolNotInstalledError struct {
+	Tool string
+	Err  error
+}
+
+// A tool ran but failed
+type ScanExecError struct {
+	Tool     string
+	ExitCode int
+	// What the tool wrote to stderr
+	Stderr string
+	Err    error
+}
+
+func (e *ToolNotInstalledError) Error() string {
+	return fmt.Sprintf("%s is not installed - %s", e.Tool, e.Err)
+}
+
+func (e *ToolNotInstalledError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ToolNotInstalledError) Is(err error) bool {
+	_, ok := err.(*ToolNotInstalledError)
+	return ok
+}
+
+func IsToolNotInstalledError(err error) bool {
+	return errors.Is(err, &ToolNotInstalledError{})
+}
+
+// The docker container of a tool was killed because it stopped producing
+// output
+type ToolStalledError struct {
+	Image       string
+	IdleTimeout time.Duration
+}
+
+func (e *ToolStalledError) Error() string {
+	return fmt.Sprintf("%s produced no output for %s and was killed", e.Image, e.IdleTimeout)
+}
+
+func (e *ToolStalledError) Is(err error) bool {
+	_, ok := err.(*ToolStalledError)
+	return ok
+}
+
+func IsToolStalledError(err error) bool {
+	return errors.Is(err, &ToolStalledError{})
+}
+
+// The docker image of a tool can't run on this platform
+type ImagePlatformError struct {
+	Image string
+}
+
+func (e *ImagePlatformError) Error() string {
+	return fmt.Sprintf("the image %s cannot run on this platform", e.Image)
+}
+
+func (e *ImagePlatformError) Is(err error) bool {
+	_, ok := err.(*ImagePlatformError)
+	return ok
+}
+
+func IsImagePlatformError(err error) bool {
+	return errors.Is(err, &ImagePlatformError{})
+}
+
+// Returns true if err means that a tool's container didn't run to
+// completion, either because docker isn't available or because the
+// container failed, so the output of the tool can't be used.
+func IsContainerError(err error) bool {
+	return IsDockerError(err) || IsToolStalledError(err) || IsImagePlatformError(err)
+}
+
+func (e *ScanExecError) Error() string {
+	s := fmt.Sprintf("%s failed with exit code %d", e.Tool, e.ExitCode)
+	// the last line of stderr is usually the most informative
+	lines := strings.Split(strings.TrimSpace(e.Stderr), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		s = fmt.Sprintf("%s - %s", s, last)
+	}
+	return s
+}
+
+func (e *ScanExecError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ScanExecError) Is(err error) bool {
+	_, ok := err.(*ScanExecError)
+	return ok
+}
+
+func IsScanExecError(err error) bool {
+	return errors.Is(err, &ScanExecError{})
+}
+
+// Returns the error for running a tool that failed with err, which is a
+// ToolNotInstalledError if the program could not be found and a
+// ScanExecError otherwise.  Container errors are returned as is.
+func NewScanExecError(tool string, err error, stderr []byte) error {
+	if IsContainerError(err) {
+		return err
+	}
+	if errors.Is(err, exec.ErrNotFound) || errors.Is(err, fs.ErrNotExist) {
+		return &ToolNotInstalledError{Tool: tool, Err: err}
+	}
+	return &ScanExecError{
+		Tool:     tool,
+		ExitCode: util.ExitCode(err),
+		Stderr:   string(stderr),
+		Err:      err,
+	}
+}
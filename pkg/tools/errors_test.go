@@ -0,0 +1,57 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/soluble-ai/soluble-cli/pkg/exit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewScanExecError(t *testing.T) {
+	assert := assert.New(t)
+	err := exec.Command("sh", "-c", "exit 2").Run()
+	serr := NewScanExecError("test", err, []byte("starting\nsomething went wrong\n"))
+	assert.True(IsScanExecError(serr))
+	assert.False(IsToolNotInstalledError(serr))
+	var se *ScanExecError
+	if assert.True(errors.As(serr, &se)) {
+		assert.Equal(2, se.ExitCode)
+	}
+	assert.Equal("test failed with exit code 2 - something went wrong", serr.Error())
+	var ee *exec.ExitError
+	assert.True(errors.As(serr, &ee))
+	err = exec.Command("this-program-does-not-exist").Run()
+	serr = NewScanExecError("test", err, nil)
+	assert.True(IsToolNotInstalledError(serr))
+	assert.False(IsScanExecError(serr))
+	derr := DockerError("the docker server is not running")
+	assert.Equal(derr, NewScanExecError("test", derr, nil))
+	var stalled error = &ToolStalledError{Image: "test", IdleTimeout: time.Minute}
+	assert.Equal(stalled, NewScanExecError("test", stalled, nil))
+	assert.True(IsToolStalledError(stalled))
+	assert.True(IsContainerError(stalled))
+	assert.False(IsDockerError(stalled))
+	assert.Equal(exit.ErrorCode, exit.CodeOf(withExitCode(stalled)))
+	var platform error = &ImagePlatformError{Image: "test"}
+	assert.True(IsContainerError(platform))
+	assert.False(IsDockerError(platform))
+	assert.Equal(exit.ErrorCode, exit.CodeOf(withExitCode(platform)))
+	assert.Equal(exit.DockerErrorCode, exit.CodeOf(withExitCode(derr)))
+}
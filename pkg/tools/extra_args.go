@@ -1,6 +1,11 @@
 package tools
 
-import "github.com/spf13/cobra"
+import (
+	"strings"
+
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/spf13/cobra"
+)
 
 // ExtraArgs captures extra arguments to a command
 type ExtraArgs []string
@@ -11,3 +16,19 @@ func (ex *ExtraArgs) ArgsValue() func(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 }
+
+// Warns about any extra args that would change the output of the tool,
+// and returns true if there were any.  Flags are matched both as "-o json"
+// and "-o=json".
+func (ex ExtraArgs) WarnOutputFlags(toolName string, flags ...string) bool {
+	warned := false
+	for _, arg := range ex {
+		for _, flag := range flags {
+			if arg == flag || strings.HasPrefix(arg, flag+"=") {
+				log.Warnf("The argument {warning:%s} may change the output format of {primary:%s}, which the results depend on", arg, toolName)
+				warned = true
+			}
+		}
+	}
+	return warned
+}
@@ -18,3 +18,11 @@ func TestExtraArgs(t *testing.T) {
 	assert.NoError(c.Execute())
 	assert.Equal([]string{"hello", "world"}, []string(ex))
 }
+
+func TestExtraArgsWarnOutputFlags(t *testing.T) {
+	assert := assert.New(t)
+	assert.False(ExtraArgs{"--skip-rules", "AC_AWS_0001"}.WarnOutputFlags("terrascan", "-o", "--output"))
+	assert.True(ExtraArgs{"-o", "human"}.WarnOutputFlags("terrascan", "-o", "--output"))
+	assert.True(ExtraArgs{"--output=yaml"}.WarnOutputFlags("terrascan", "-o", "--output"))
+	assert.False(ExtraArgs{"--output-dir"}.WarnOutputFlags("terrascan", "-o", "--output"))
+}
@@ -0,0 +1,92 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+)
+
+type findingField struct {
+	name  string
+	value func(toolName string, f *assessments.Finding) interface{}
+}
+
+// The fields that --fields can choose, in the order they're listed in
+// errors.
+var findingFields = []*findingField{
+	{"tool", func(toolName string, f *assessments.Finding) interface{} { return toolName }},
+	{"sid", func(_ string, f *assessments.Finding) interface{} { return f.SID }},
+	{"rule_id", func(_ string, f *assessments.Finding) interface{} { return f.GetRuleID() }},
+	{"severity", func(_ string, f *assessments.Finding) interface{} { return f.GetSeverity() }},
+	{"pass", func(_ string, f *assessments.Finding) interface{} { return f.Pass }},
+	{"title", func(_ string, f *assessments.Finding) interface{} { return f.Title }},
+	{"description", func(_ string, f *assessments.Finding) interface{} { return f.Description }},
+	{"file", func(_ string, f *assessments.Finding) interface{} { return f.FilePath }},
+	{"line", func(_ string, f *assessments.Finding) interface{} { return f.Line }},
+	{"repo_path", func(_ string, f *assessments.Finding) interface{} { return f.RepoPath }},
+	{"fingerprint", func(_ string, f *assessments.Finding) interface{} { return f.PartialFingerprint }},
+	{"owners", func(_ string, f *assessments.Finding) interface{} { return strings.Join(f.Owners, " ") }},
+	{"help_url", func(_ string, f *assessments.Finding) interface{} { return f.Tool["help_url"] }},
+}
+
+func getFindingFieldNames() []string {
+	names := make([]string, len(findingFields))
+	for i, ff := range findingFields {
+		names[i] = ff.name
+	}
+	return names
+}
+
+func getFindingField(name string) *findingField {
+	for _, ff := range findingFields {
+		if ff.name == name {
+			return ff
+		}
+	}
+	return nil
+}
+
+func validateFields(fields []string) error {
+	for _, name := range fields {
+		if getFindingField(name) == nil {
+			return fmt.Errorf("unknown --fields %s, must be one of %s", name,
+				strings.Join(getFindingFieldNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// Returns an array of the findings with only the chosen fields.
+func (results Results) getFindingFieldsJNode(fields []string) *jnode.Node {
+	n := jnode.NewArrayNode()
+	for _, result := range results {
+		findings := result.Findings
+		if result.Assessment != nil {
+			findings = result.Assessment.Findings
+		}
+		toolName := result.Values["TOOL_NAME"]
+		for _, f := range findings {
+			e := n.AppendObject()
+			for _, name := range fields {
+				e.Put(name, getFindingField(name).value(toolName, f))
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,33 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Write the fingerprints of results as a JSON array.  UpdateFileFingerprints
+// sorts the fingerprints of each result by path and line, so the output of
+// scans of different commits can be diffed.
+func WriteFingerprints(w io.Writer, results Results) error {
+	fingerprints := []*FileFingerprint{}
+	for _, result := range results {
+		fingerprints = append(fingerprints, result.FileFingerprints...)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(fingerprints)
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteFingerprints(t *testing.T) {
+	assert := assert.New(t)
+	var buf bytes.Buffer
+	assert.NoError(WriteFingerprints(&buf, Results{{}}))
+	assert.Equal("[]\n", buf.String())
+	buf.Reset()
+	results := Results{
+		{FileFingerprints: []*FileFingerprint{
+			{FilePath: "main.tf", RepoPath: "b/main.tf", Line: 1, PartialFingerprint: "1"},
+			{FilePath: "main.tf", RepoPath: "b/main.tf", Line: 3, PartialFingerprint: "3"},
+		}},
+		{FileFingerprints: []*FileFingerprint{
+			{FilePath: "vars.tf", RepoPath: "a/vars.tf", Line: 7, PartialFingerprint: "7"},
+		}},
+	}
+	assert.NoError(WriteFingerprints(&buf, results))
+	n, err := jnode.FromJSON(buf.Bytes())
+	if assert.NoError(err) && assert.Equal(3, n.Size()) {
+		assert.Equal(1, n.Get(0).Path("line").AsInt())
+		assert.Equal("3", n.Get(1).Path("partialFingerprint").AsText())
+		assert.Equal("a/vars.tf", n.Get(2).Path("repoPath").AsText())
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+// A GitLab code quality issue, see
+// https://docs.gitlab.com/ee/ci/testing/code_quality.html#implement-a-custom-tool
+type gitLabIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name,omitempty"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    gitLabIssueLocation `json:"location"`
+}
+
+type gitLabIssueLocation struct {
+	Path  string `json:"path"`
+	Lines struct {
+		Begin int `json:"begin"`
+	} `json:"lines"`
+}
+
+var gitLabSeverities = map[string]string{
+	"critical": "blocker",
+	"high":     "critical",
+	"medium":   "major",
+	"low":      "minor",
+}
+
+// Write the failed findings as a GitLab code quality report.  The
+// fingerprint of each issue is derived from the partial fingerprint of
+// the finding's line (rather than the line number) so that it stays the
+// same when lines are added above it.
+func (results Results) WriteGitLabCodeQuality(w io.Writer) error {
+	issues := []*gitLabIssue{}
+	for _, result := range results {
+		for _, f := range result.Findings {
+			if f.Pass {
+				continue
+			}
+			issues = append(issues, getGitLabIssue(result.Values["TOOL_NAME"], f))
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(issues)
+}
+
+func getGitLabIssue(toolName string, f *assessments.Finding) *gitLabIssue {
+	issue := &gitLabIssue{
+		Description: f.Title,
+		CheckName:   f.GetRuleID(),
+		Severity:    gitLabSeverities[f.GetSeverity()],
+	}
+	if issue.Description == "" {
+		issue.Description = f.Description
+	}
+	if issue.Severity == "" {
+		issue.Severity = "info"
+	}
+	issue.Location.Path = f.RepoPath
+	if issue.Location.Path == "" {
+		issue.Location.Path = f.FilePath
+	}
+	issue.Location.Lines.Begin = f.Line
+	if issue.Location.Lines.Begin <= 0 {
+		issue.Location.Lines.Begin = 1
+	}
+	// several rules can fail on the same line, so the fingerprint
+	// includes the rule too
+	location := f.PartialFingerprint
+	if location == "" {
+		location = strconv.Itoa(f.Line)
+	}
+	h := sha256.Sum256([]byte(strings.Join([]string{
+		toolName, issue.CheckName, issue.Location.Path, location,
+	}, "\x00")))
+	issue.Fingerprint = hex.EncodeToString(h[:16])
+	return issue
+}
+
+func saveGitLabCodeQuality(path string, results Results) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := results.WriteGitLabCodeQuality(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	log.Infof("Wrote GitLab code quality report to {info:%s}", path)
+	return nil
+}
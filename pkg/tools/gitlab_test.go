@@ -0,0 +1,64 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteGitLabCodeQuality(t *testing.T) {
+	assert := assert.New(t)
+	results := Results{
+		{
+			Values: map[string]string{"TOOL_NAME": "checkov"},
+			Findings: assessments.Findings{
+				{FilePath: "main.tf", RepoPath: "infra/main.tf", Line: 10, Title: "Bucket is public", PartialFingerprint: "abc",
+					Tool: map[string]string{"check_id": "CKV_AWS_20", "severity": "low"}},
+				{FilePath: "main.tf", RepoPath: "infra/main.tf", Line: 10, Title: "Bucket is not encrypted", PartialFingerprint: "abc",
+					Severity: "critical", Tool: map[string]string{"check_id": "CKV_AWS_19"}},
+				{FilePath: "vpc.tf", Title: "Ok", Pass: true},
+				{FilePath: "Dockerfile", Description: "Pin versions", Severity: "weird"},
+			},
+		},
+	}
+	buf := &bytes.Buffer{}
+	assert.NoError(results.WriteGitLabCodeQuality(buf))
+	var issues []*gitLabIssue
+	assert.NoError(json.Unmarshal(buf.Bytes(), &issues))
+	if assert.Len(issues, 3) {
+		assert.Equal("Bucket is public", issues[0].Description)
+		assert.Equal("CKV_AWS_20", issues[0].CheckName)
+		assert.Equal("minor", issues[0].Severity)
+		assert.Equal("infra/main.tf", issues[0].Location.Path)
+		assert.Equal(10, issues[0].Location.Lines.Begin)
+		assert.Equal("blocker", issues[1].Severity)
+		assert.NotEqual(issues[0].Fingerprint, issues[1].Fingerprint)
+		assert.Equal("Pin versions", issues[2].Description)
+		assert.Equal("info", issues[2].Severity)
+		assert.Equal(1, issues[2].Location.Lines.Begin)
+	}
+	// the fingerprint doesn't depend on the line number
+	f := *results[0].Findings[0]
+	f.Line = 20
+	assert.Equal(issues[0].Fingerprint, getGitLabIssue("checkov", &f).Fingerprint)
+	buf.Reset()
+	assert.NoError(Results{}.WriteGitLabCodeQuality(buf))
+	assert.Equal("[]\n", buf.String())
+}
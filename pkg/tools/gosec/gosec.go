@@ -22,6 +22,7 @@ import (
 	"github.com/soluble-ai/soluble-cli/pkg/assessments"
 	"github.com/soluble-ai/soluble-cli/pkg/download"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/tracing"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/spf13/cobra"
 )
@@ -36,6 +37,16 @@ func (t *Tool) Name() string {
 	return "gosec"
 }
 
+func (t *Tool) Preflight() error {
+	return t.PreflightInstall(&download.Spec{
+		URL: "github.com/securego/gosec",
+	})
+}
+
+func (t *Tool) Requirements() []string {
+	return []string{tools.RequiresDownload}
+}
+
 func (t *Tool) Run() (*tools.Result, error) {
 	d, err := t.InstallTool(&download.Spec{
 		URL: "github.com/securego/gosec",
@@ -47,11 +58,14 @@ func (t *Tool) Run() (*tools.Result, error) {
 	// #nosec G204
 	c := exec.Command(d.GetExePath("gosec"), args...)
 	c.Stderr = os.Stderr
+	defer tools.AcquireWorker()()
 	t.LogCommand(c)
+	span := t.StartCommandSpan(c)
 	output, err := c.Output()
 	if util.ExitCode(err) == 1 {
 		err = nil
 	}
+	tracing.EndSpan(span, err)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,146 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+)
+
+var groupByColumns = map[string][]string{
+	"rule": {"rule", "severity", "count", "locations"},
+	"file": {"filePath", "severity", "count", "rules"},
+}
+
+type findingGroup struct {
+	key      string
+	severity string
+	findings []*assessments.Finding
+}
+
+func validateGroupBy(groupBy string) error {
+	if groupBy != "" && groupByColumns[groupBy] == nil {
+		return fmt.Errorf("--group-by must be either rule or file")
+	}
+	return nil
+}
+
+func getLocation(f *assessments.Finding) string {
+	if f.Line > 0 {
+		return fmt.Sprintf("%s:%d", f.FilePath, f.Line)
+	}
+	return f.FilePath
+}
+
+// Groups the failed findings of the results by the key that getKey
+// returns, skipping findings without a key if skipEmpty is set.  Each
+// group has the highest severity of its findings.
+func (results Results) groupFailedFindings(getKey func(*assessments.Finding) string, skipEmpty bool) []*findingGroup {
+	groups := map[string]*findingGroup{}
+	var sorted []*findingGroup
+	for _, result := range results {
+		findings := result.Findings
+		if result.Assessment != nil {
+			findings = result.Assessment.Findings
+		}
+		for _, f := range findings {
+			if f.Pass {
+				continue
+			}
+			key := getKey(f)
+			if key == "" && skipEmpty {
+				continue
+			}
+			g := groups[key]
+			if g == nil {
+				g = &findingGroup{key: key}
+				groups[key] = g
+				sorted = append(sorted, g)
+			}
+			g.findings = append(g.findings, f)
+			if s := f.GetSeverity(); assessments.SeverityLevel(s) > assessments.SeverityLevel(g.severity) {
+				g.severity = s
+			}
+		}
+	}
+	return sorted
+}
+
+// Returns one row per file with failed findings, with the number of
+// findings and their highest severity.  The most severe files are
+// listed first.
+func (results Results) getFilesJNode() *jnode.Node {
+	sorted := results.groupFailedFindings(func(f *assessments.Finding) string {
+		if f.FilePath == "" {
+			return ""
+		}
+		if f.RepoPath != "" {
+			return f.RepoPath
+		}
+		return f.FilePath
+	}, true)
+	sort.Slice(sorted, func(i, j int) bool {
+		li, lj := assessments.SeverityLevel(sorted[i].severity), assessments.SeverityLevel(sorted[j].severity)
+		if li != lj {
+			return li > lj
+		}
+		return sorted[i].key < sorted[j].key
+	})
+	n := jnode.NewArrayNode()
+	for _, g := range sorted {
+		n.AppendObject().Put("file", g.key).Put("count", len(g.findings)).Put("severity", g.severity)
+	}
+	return n
+}
+
+// Group the failed findings of the results by rule or by file, with the
+// largest groups first.  If limit > 0 then at most limit locations (or
+// rules) are listed per group.
+func (results Results) getGroupedFindingsJNode(groupBy string, limit int) *jnode.Node {
+	getKey := (*assessments.Finding).GetRuleID
+	if groupBy == "file" {
+		getKey = func(f *assessments.Finding) string { return f.FilePath }
+	}
+	sorted := results.groupFailedFindings(getKey, false)
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i].findings) != len(sorted[j].findings) {
+			return len(sorted[i].findings) > len(sorted[j].findings)
+		}
+		return sorted[i].key < sorted[j].key
+	})
+	n := jnode.NewArrayNode()
+	for _, g := range sorted {
+		e := n.AppendObject().
+			Put(groupByColumns[groupBy][0], g.key).
+			Put("severity", g.severity).
+			Put("count", len(g.findings))
+		name, describe := "locations", getLocation
+		if groupBy == "file" {
+			name, describe = "rules", (*assessments.Finding).GetRuleID
+		}
+		a := e.PutArray(name)
+		for i, f := range g.findings {
+			if limit > 0 && i == limit {
+				a.Append(fmt.Sprintf("(%d more)", len(g.findings)-limit))
+				break
+			}
+			a.Append(describe(f))
+		}
+	}
+	return n
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupFindings(t *testing.T) {
+	assert := assert.New(t)
+	results := Results{
+		{
+			Findings: assessments.Findings{
+				{FilePath: "a.tf", Line: 1, Tool: map[string]string{"rule_id": "R1", "severity": "low"}},
+				{FilePath: "b.tf", Line: 2, Tool: map[string]string{"rule_id": "R1", "severity": "high"}},
+				{FilePath: "a.tf", Line: 3, Tool: map[string]string{"rule_id": "R2", "severity": "medium"}},
+				{FilePath: "c.tf", Line: 4, Tool: map[string]string{"rule_id": "R1", "severity": "low"}},
+				{FilePath: "a.tf", Line: 5, Tool: map[string]string{"check_id": "C1"}, Pass: true},
+			},
+		},
+	}
+	n := results.getGroupedFindingsJNode("rule", 2)
+	assert.Equal(2, n.Size())
+	r1 := n.Get(0)
+	assert.Equal("R1", r1.Path("rule").AsText())
+	assert.Equal(3, r1.Path("count").AsInt())
+	assert.Equal("high", r1.Path("severity").AsText())
+	assert.Equal([]interface{}{"a.tf:1", "b.tf:2", "(1 more)"}, r1.Path("locations").Unwrap())
+	n = results.getGroupedFindingsJNode("file", 0)
+	assert.Equal("a.tf", n.Get(0).Path("filePath").AsText())
+	assert.Equal([]interface{}{"R1", "R2"}, n.Get(0).Path("rules").Unwrap())
+	assert.Error(validateGroupBy("severity"))
+	assert.NoError(validateGroupBy("file"))
+}
+
+func TestFilesFormat(t *testing.T) {
+	assert := assert.New(t)
+	results := Results{
+		{
+			Findings: assessments.Findings{
+				{FilePath: "a.tf", RepoPath: "infra/a.tf", Tool: map[string]string{"severity": "low"}},
+				{FilePath: "b.tf", Tool: map[string]string{"severity": "high"}},
+				{FilePath: "a.tf", RepoPath: "infra/a.tf", Tool: map[string]string{"severity": "medium"}},
+				{FilePath: "c.tf", Tool: map[string]string{"severity": "medium"}},
+				{FilePath: "d.tf", Tool: map[string]string{"severity": "critical"}, Pass: true},
+			},
+		},
+	}
+	tool := &testTool{}
+	tool.Path = []string{}
+	tool.OutputFormat = "files"
+	w := &bytes.Buffer{}
+	tool.SetOutput(w)
+	assert.NoError(printResults(tool, results, nil))
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+	if assert.Len(lines, 3) {
+		assert.Equal([]string{"b.tf", "1", "high"}, strings.Fields(lines[0]))
+		assert.Equal([]string{"c.tf", "1", "medium"}, strings.Fields(lines[1]))
+		assert.Equal([]string{"infra/a.tf", "2", "medium"}, strings.Fields(lines[2]))
+	}
+}
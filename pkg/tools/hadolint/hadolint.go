@@ -15,48 +15,153 @@
 package hadolint
 
 import (
+	"bytes"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/soluble-ai/go-jnode"
 	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/inventory"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/spf13/cobra"
 )
 
+const hadolintImage = "ghcr.io/hadolint/hadolint:latest"
+
 type Tool struct {
 	tools.DirectoryBasedToolOpts
+	HadolintConfig string
+
+	extraArgs tools.ExtraArgs
 }
 
 var _ tools.Single = (*Tool)(nil)
 
 func (t *Tool) Name() string { return "hadolint" }
 
+func (t *Tool) GetDockerImage() string {
+	return t.ResolveDockerImage("hadolint", hadolintImage)
+}
+
+func (t *Tool) Preflight() error {
+	return t.PreflightDocker()
+}
+
+func (t *Tool) Requirements() []string {
+	return []string{tools.RequiresDocker}
+}
+
+func (t *Tool) Register(cmd *cobra.Command) {
+	t.DirectoryBasedToolOpts.Register(cmd)
+	cmd.Flags().StringVar(&t.HadolintConfig, "hadolint-config", "", "Use the hadolint config `file` instead of .hadolint.yaml in the directory")
+}
+
+func (t *Tool) Validate() error {
+	if t.HadolintConfig != "" {
+		if _, err := os.Stat(t.HadolintConfig); err != nil {
+			return fmt.Errorf("invalid --hadolint-config: %w", err)
+		}
+	}
+	return t.DirectoryBasedToolOpts.Validate()
+}
+
 func (t *Tool) Run() (*tools.Result, error) {
-	// This might be a problem if we have multiple dockerfiles and they have extensions like Dockerfile.xyz
-	dockerFilePath := "./Dockerfile"
-	args := []string{"hadolint", "-f", "json", "-", dockerFilePath}
-	d, err := t.RunDocker(&tools.DockerTool{
+	dockerfiles := t.getDockerfiles()
+	if len(dockerfiles) == 0 {
+		log.Infof("No Dockerfiles in the files to scan")
+		return &tools.Result{
+			Directory: t.GetDirectory(),
+			Data:      jnode.NewArrayNode(),
+			Findings:  assessments.Findings{},
+		}, nil
+	}
+	dockerArgs, configArgs := t.getConfigArgs()
+	args := []string{"hadolint", "-f", "json"}
+	args = append(args, configArgs...)
+	t.extraArgs.WarnOutputFlags(t.Name(), "-f", "--format")
+	args = append(args, t.extraArgs...)
+	args = append(args, "-")
+	args = append(args, dockerfiles...)
+	stderr := &bytes.Buffer{}
+	d, runErr := t.RunDocker(&tools.DockerTool{
 		Name:                "hadolint",
-		Image:               "ghcr.io/hadolint/hadolint:latest",
+		Image:               hadolintImage,
 		DefaultNoDockerName: "hadolint",
 		Directory:           t.GetDirectory(),
+		DockerArgs:          dockerArgs,
 		Args:                args,
+		Stderr:              io.MultiWriter(os.Stderr, stderr),
 	})
-	if err != nil && tools.IsDockerError(err) {
-		return nil, err
+	if runErr != nil && (tools.IsContainerError(runErr) || util.ExitCode(runErr) < 0) {
+		return nil, tools.NewScanExecError(t.Name(), runErr, stderr.Bytes())
 	}
 	results, err := jnode.FromJSON(d)
 	if err != nil {
 		if d != nil {
 			os.Stderr.Write(d)
 		}
+		// hadolint exits with 1 when it finds problems, so only
+		// report a failure if it didn't produce results
+		if runErr != nil {
+			return nil, tools.NewScanExecError(t.Name(), runErr, stderr.Bytes())
+		}
 		return nil, err
 	}
 	result := t.parseResults(results)
 	return result, nil
 }
 
+// Returns the Dockerfiles to lint.  This might be a problem if we have
+// multiple dockerfiles and they have extensions like Dockerfile.xyz,
+// unless they're listed with --files-from.
+func (t *Tool) getDockerfiles() []string {
+	if file := t.GetFile(); file != "" {
+		return []string{"./" + file}
+	}
+	files := t.GetFiles()
+	if files == nil {
+		return []string{"./Dockerfile"}
+	}
+	var dockerfiles []string
+	for _, file := range files {
+		if inventory.IsDockerfileName(file) {
+			dockerfiles = append(dockerfiles, "./"+filepath.ToSlash(file))
+		}
+	}
+	return dockerfiles
+}
+
+// Returns the docker and hadolint arguments for the hadolint config.  A
+// config in the directory is found through the directory's mount, otherwise
+// the config is mounted separately.
+func (t *Tool) getConfigArgs() (dockerArgs []string, args []string) {
+	dir := t.GetDirectory()
+	if t.HadolintConfig == "" {
+		for _, name := range []string{".hadolint.yaml", ".hadolint.yml"} {
+			if util.FileExists(filepath.Join(dir, name)) {
+				return nil, []string{"--config", name}
+			}
+		}
+		return nil, nil
+	}
+	config, err := filepath.Abs(t.HadolintConfig)
+	if err != nil {
+		config = t.HadolintConfig
+	}
+	if rel, err := filepath.Rel(dir, config); err == nil && !strings.HasPrefix(rel, "..") {
+		return nil, []string{"--config", filepath.ToSlash(rel)}
+	}
+	if t.NoDocker || t.ToolPath != "" {
+		return nil, []string{"--config", config}
+	}
+	return []string{"-v", fmt.Sprintf("%s:/hadolint.yaml:ro", config)}, []string{"--config", "/hadolint.yaml"}
+}
+
 func (t *Tool) parseResults(results *jnode.Node) *tools.Result {
 	findings := assessments.Findings{}
 	for _, data := range results.Elements() {
@@ -64,11 +169,13 @@ func (t *Tool) parseResults(results *jnode.Node) *tools.Result {
 		if t.IsExcluded(file) {
 			continue
 		}
+		code := data.Path("code").AsText()
 		findings = append(findings, &assessments.Finding{
 			FilePath: file,
 			Line:     data.Path("line").AsInt(),
 			Tool: map[string]string{
-				"rule_id":  data.Path("code").AsText(),
+				"rule_id":  code,
+				"help_url": getHelpURL(code),
 				"message":  data.Path("message").AsText(),
 				"severity": data.Path("level").AsText(),
 				"file":     data.Path("file").AsText(),
@@ -88,9 +195,26 @@ func (t *Tool) parseResults(results *jnode.Node) *tools.Result {
 	return result
 }
 
+// hadolint doesn't include links in its output, but its own rules
+// (DLxxxx) are documented in the wiki and the shellcheck rules (SCxxxx)
+// are documented in shellcheck's wiki.
+func getHelpURL(code string) string {
+	switch {
+	case strings.HasPrefix(code, "DL"):
+		return fmt.Sprintf("https://github.com/hadolint/hadolint/wiki/%s", code)
+	case strings.HasPrefix(code, "SC"):
+		return fmt.Sprintf("https://github.com/koalaman/shellcheck/wiki/%s", code)
+	}
+	return ""
+}
+
 func (t *Tool) CommandTemplate() *cobra.Command {
 	return &cobra.Command{
 		Use:   "hadolint",
 		Short: "Run hadolint to lint your Dockerfile",
+		Long: `Run hadolint to lint your Dockerfile
+
+Extra arguments after -- are passed to hadolint.`,
+		Args: t.extraArgs.ArgsValue(),
 	}
 }
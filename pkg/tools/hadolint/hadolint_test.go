@@ -15,6 +15,8 @@
 package hadolint
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/soluble-ai/soluble-cli/pkg/util"
@@ -31,5 +33,50 @@ func TestParseResults(t *testing.T) {
 	assert.Equal(2, result.Data.Size())
 	f := result.Findings[0].Tool
 	assert.Equal("DL3027", f["rule_id"])
+	assert.Equal("https://github.com/hadolint/hadolint/wiki/DL3027", f["help_url"])
 	assert.Equal(results.Unwrap(), result.Data.Unwrap())
 }
+
+func TestGetConfigArgs(t *testing.T) {
+	assert := assert.New(t)
+	tool := &Tool{}
+	tool.Directory = "testdata/config"
+	dockerArgs, args := tool.getConfigArgs()
+	assert.Nil(dockerArgs)
+	assert.Equal([]string{"--config", ".hadolint.yaml"}, args)
+	tool = &Tool{HadolintConfig: "testdata/config/.hadolint.yaml"}
+	tool.Directory = "testdata"
+	dockerArgs, args = tool.getConfigArgs()
+	assert.Nil(dockerArgs)
+	assert.Equal([]string{"--config", "config/.hadolint.yaml"}, args)
+	tool = &Tool{HadolintConfig: "testdata/config/.hadolint.yaml"}
+	tool.Directory = t.TempDir()
+	dockerArgs, args = tool.getConfigArgs()
+	config, _ := filepath.Abs("testdata/config/.hadolint.yaml")
+	assert.Equal([]string{"-v", config + ":/hadolint.yaml:ro"}, dockerArgs)
+	assert.Equal([]string{"--config", "/hadolint.yaml"}, args)
+	tool = &Tool{}
+	tool.Directory = "testdata"
+	dockerArgs, args = tool.getConfigArgs()
+	assert.Nil(dockerArgs)
+	assert.Nil(args)
+}
+
+func TestGetDockerfiles(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	for _, name := range []string{"Dockerfile", "Dockerfile.prod", "main.tf"} {
+		assert.NoError(os.WriteFile(filepath.Join(dir, name), []byte("FROM alpine\n"), 0600))
+	}
+	tool := &Tool{}
+	tool.Directory = dir
+	assert.NoError(tool.Validate())
+	assert.Equal([]string{"./Dockerfile"}, tool.getDockerfiles())
+	list := filepath.Join(t.TempDir(), "files.txt")
+	assert.NoError(os.WriteFile(list, []byte("Dockerfile.prod\nmain.tf\n"), 0600))
+	tool = &Tool{}
+	tool.Directory = dir
+	tool.FilesFrom = list
+	assert.NoError(tool.Validate())
+	assert.Equal([]string{"./Dockerfile.prod"}, tool.getDockerfiles())
+}
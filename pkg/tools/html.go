@@ -0,0 +1,176 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Scan Report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+h1 { margin-bottom: 0.2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; width: 100%; }
+th, td { text-align: left; padding: 0.3em 0.6em; border-bottom: 1px solid #ddd; vertical-align: top; }
+th { background: #f4f4f4; }
+.meta td:first-child { font-weight: bold; width: 8em; }
+.severity { display: inline-block; padding: 0.1em 0.5em; border-radius: 3px; color: #fff; background: #888; }
+.critical { background: #7b1fa2; }
+.high { background: #d32f2f; }
+.medium { background: #f57c00; }
+.low { background: #1976d2; }
+.info { background: #689f38; }
+.file { font-family: monospace; margin: 1em 0 0.3em 0; }
+</style>
+</head>
+<body>
+<h1>Scan Report</h1>
+<table class="meta">
+<tr><td>Repository</td><td>{{ .Repo }}</td></tr>
+<tr><td>Branch</td><td>{{ .Branch }}</td></tr>
+<tr><td>Commit</td><td>{{ .Commit }}</td></tr>
+<tr><td>Generated</td><td>{{ .Generated }}</td></tr>
+<tr><td>Findings</td><td>{{ .Failed }} failed, {{ .Passed }} passed</td></tr>
+</table>
+{{ range .Severities }}
+<h2><span class="severity {{ .Name }}">{{ .Name }}</span> {{ .Count }}</h2>
+{{ range .Files }}
+<div class="file">{{ .Path }}</div>
+<table>
+<tr><th>Line</th><th>Rule</th><th>Title</th></tr>
+{{ range .Findings }}<tr><td>{{ .Line }}</td><td>{{ .Rule }}</td><td>{{ if .HelpURL }}<a href="{{ .HelpURL }}">{{ .Title }}</a>{{ else }}{{ .Title }}{{ end }}</td></tr>
+{{ end }}</table>
+{{ end }}{{ end }}</body>
+</html>
+`))
+
+type htmlReport struct {
+	Repo, Branch, Commit, Generated string
+	Failed, Passed                  int
+	Severities                      []*htmlSeverity
+}
+
+type htmlSeverity struct {
+	Name  string
+	Count int
+	Files []*htmlFile
+}
+
+type htmlFile struct {
+	Path     string
+	Findings []*htmlFinding
+}
+
+type htmlFinding struct {
+	Line                 int
+	Rule, Title, HelpURL string
+}
+
+// Write a self-contained HTML report of the failed findings, grouped
+// by severity and then by file.  The repository details come from the
+// git metadata that was collected when the results were processed.
+func (results Results) WriteHTML(w io.Writer) error {
+	var env map[string]string
+	for _, result := range results {
+		if result.CIEnv != nil {
+			env = result.CIEnv
+			break
+		}
+	}
+	report := &htmlReport{
+		Repo:      env["SOLUBLE_METADATA_GIT_REMOTE"],
+		Branch:    env["SOLUBLE_METADATA_GIT_BRANCH"],
+		Commit:    env["SOLUBLE_METADATA_GIT_COMMIT"],
+		Generated: time.Now().Format(time.RFC1123),
+	}
+	report.Severities = getHTMLSeverities(report, results.getFindings())
+	return htmlReportTemplate.Execute(w, report)
+}
+
+func getHTMLSeverities(report *htmlReport, findings []*assessments.Finding) []*htmlSeverity {
+	severities := map[string]*htmlSeverity{}
+	files := map[string]*htmlFile{}
+	for _, f := range findings {
+		if f.Pass {
+			report.Passed++
+			continue
+		}
+		report.Failed++
+		severity := f.GetSeverity()
+		if severity == "" {
+			severity = "unknown"
+		}
+		s := severities[severity]
+		if s == nil {
+			s = &htmlSeverity{Name: severity}
+			severities[severity] = s
+		}
+		s.Count++
+		path := f.FilePath
+		file := files[severity+"\x00"+path]
+		if file == nil {
+			file = &htmlFile{Path: path}
+			files[severity+"\x00"+path] = file
+			s.Files = append(s.Files, file)
+		}
+		file.Findings = append(file.Findings, &htmlFinding{
+			Line:    f.Line,
+			Rule:    f.GetRuleID(),
+			Title:   f.Title,
+			HelpURL: f.Tool["help_url"],
+		})
+	}
+	result := make([]*htmlSeverity, 0, len(severities))
+	for _, s := range severities {
+		sort.Slice(s.Files, func(i, j int) bool { return s.Files[i].Path < s.Files[j].Path })
+		for _, file := range s.Files {
+			sort.SliceStable(file.Findings, func(i, j int) bool {
+				return file.Findings[i].Line < file.Findings[j].Line
+			})
+		}
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return assessments.SeverityLevel(result[i].Name) > assessments.SeverityLevel(result[j].Name)
+	})
+	return result
+}
+
+func saveHTMLReport(path string, results Results) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := results.WriteHTML(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	log.Infof("Wrote HTML report to {info:%s}", path)
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteHTML(t *testing.T) {
+	assert := assert.New(t)
+	results := Results{
+		{
+			Directory: ".",
+			CIEnv:     map[string]string{"SOLUBLE_METADATA_GIT_BRANCH": "feature/html"},
+			Findings: assessments.Findings{
+				{FilePath: "main.tf", Line: 10, Title: "Bucket is <public>", Tool: map[string]string{"check_id": "CKV_AWS_20", "severity": "low"}},
+				{FilePath: "main.tf", Line: 2, Title: "Bucket is not encrypted", Severity: "high", Tool: map[string]string{"check_id": "CKV_AWS_19"}},
+				{FilePath: "vpc.tf", Line: 5, Title: "Ok", Pass: true},
+			},
+		},
+	}
+	buf := &bytes.Buffer{}
+	assert.NoError(results.WriteHTML(buf))
+	s := buf.String()
+	assert.Contains(s, "2 failed, 1 passed")
+	assert.Contains(s, "<td>feature/html</td>")
+	assert.Contains(s, "Bucket is &lt;public&gt;")
+	assert.Contains(s, "CKV_AWS_19")
+	assert.NotContains(s, "<td>Ok</td>")
+	high := strings.Index(s, `class="severity high"`)
+	low := strings.Index(s, `class="severity low"`)
+	assert.True(high > 0 && low > high)
+}
@@ -0,0 +1,250 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iampolicy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/print"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+type Tool struct {
+	tools.DirectoryBasedToolOpts
+	Files []string
+}
+
+type rule struct {
+	id       string
+	severity string
+	title    string
+	// returns true if an Allow statement violates the rule
+	check func(statement *yaml.Node) bool
+}
+
+// A finding in a policy document
+type Violation struct {
+	RuleID   string `json:"rule_id"`
+	Severity string `json:"severity"`
+	Title    string `json:"title"`
+	Sid      string `json:"sid,omitempty"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+var rules = []*rule{
+	{"IAM_WILDCARD_ACTION", "high", "Statement allows all actions", checkWildcardAction},
+	{"IAM_WILDCARD_RESOURCE", "medium", "Statement allows actions on all resources", checkWildcardResource},
+	{"IAM_ALLOW_NOT_ACTION", "medium", "Statement allows all actions except those listed", checkNotAction},
+	{"IAM_WILDCARD_NO_CONDITION", "low", "Statement allows wildcard access without a condition", checkNoCondition},
+}
+
+var _ tools.Single = &Tool{}
+
+func (*Tool) Name() string {
+	return "iam-policy"
+}
+
+func (t *Tool) Register(cmd *cobra.Command) {
+	t.DirectoryBasedToolOpts.Register(cmd)
+	cmd.Flags().StringSliceVar(&t.Files, "policy-file", nil, "Scan these IAM policy `files` instead of the policy documents found in the directory.  May be repeated.")
+}
+
+func (t *Tool) CommandTemplate() *cobra.Command {
+	return &cobra.Command{
+		Use:   "iam-policy",
+		Short: "Scan AWS IAM policy documents for over-permissive statements",
+		Long: `Scan AWS IAM policy documents (JSON files with a Version and
+Statement) for statements that allow all actions, allow actions on all
+resources, use NotAction to allow everything but a few actions, or grant
+wildcard access without a condition.`,
+	}
+}
+
+func (t *Tool) Run() (*tools.Result, error) {
+	files := t.Files
+	if files == nil {
+		files = t.GetInventory().IAMPolicyFiles.Values()
+	}
+	violations := []*Violation{}
+	result := &tools.Result{
+		Directory: t.GetDirectory(),
+		Findings:  assessments.Findings{},
+	}
+	for _, file := range t.RemoveExcluded(files) {
+		dat, err := os.ReadFile(filepath.Join(t.GetDirectory(), file))
+		if err != nil {
+			return nil, err
+		}
+		file = filepath.ToSlash(file)
+		vs, err := scan(file, dat)
+		if err != nil {
+			log.Warnf("Could not scan {warning:%s} - {warning:%s}", file, err)
+			continue
+		}
+		violations = append(violations, vs...)
+	}
+	for _, v := range violations {
+		result.Findings = append(result.Findings, &assessments.Finding{
+			FilePath: v.File,
+			Line:     v.Line,
+			Title:    v.Title,
+			Severity: v.Severity,
+			Tool: map[string]string{
+				"rule_id":  v.RuleID,
+				"sid":      v.Sid,
+				"severity": v.Severity,
+			},
+		})
+	}
+	n, err := print.ToResult(map[string]interface{}{"violations": violations})
+	if err != nil {
+		return nil, err
+	}
+	result.Data = n
+	return result, nil
+}
+
+// Scan the statements of a policy document.
+func scan(file string, dat []byte) ([]*Violation, error) {
+	// JSON is (nearly) YAML, which gives us line numbers, but YAML doesn't
+	// allow tabs for indentation
+	dat = bytes.ReplaceAll(dat, []byte("\t"), []byte(" "))
+	var doc yaml.Node
+	if err := yaml.Unmarshal(dat, &doc); err != nil {
+		return nil, err
+	}
+	statements, err := getStatements(&doc)
+	if err != nil {
+		return nil, err
+	}
+	var violations []*Violation
+	for _, statement := range statements {
+		if getScalar(statement, "Effect") != "Allow" {
+			continue
+		}
+		for _, r := range rules {
+			if r.check(statement) {
+				violations = append(violations, &Violation{
+					RuleID:   r.id,
+					Severity: r.severity,
+					Title:    r.title,
+					Sid:      getScalar(statement, "Sid"),
+					File:     file,
+					Line:     statement.Line,
+				})
+			}
+		}
+	}
+	return violations, nil
+}
+
+// Returns the statements of a policy document, or an error if the
+// document isn't a policy.
+func getStatements(doc *yaml.Node) ([]*yaml.Node, error) {
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("not an IAM policy document")
+	}
+	root := doc.Content[0]
+	version := getScalar(root, "Version")
+	if version != "2012-10-17" && version != "2008-10-17" {
+		return nil, fmt.Errorf("not an IAM policy document (no policy Version)")
+	}
+	var statements []*yaml.Node
+	switch s := getMapValue(root, "Statement"); {
+	case s == nil:
+		return nil, fmt.Errorf("not an IAM policy document (no Statement)")
+	case s.Kind == yaml.MappingNode:
+		statements = []*yaml.Node{s}
+	case s.Kind == yaml.SequenceNode:
+		statements = s.Content
+	default:
+		return nil, fmt.Errorf("not an IAM policy document (invalid Statement)")
+	}
+	for _, s := range statements {
+		if s.Kind != yaml.MappingNode || getScalar(s, "Effect") == "" ||
+			(getMapValue(s, "Action") == nil && getMapValue(s, "NotAction") == nil) {
+			return nil, fmt.Errorf("not an IAM policy document (statement at line %d has no Effect or Action)", s.Line)
+		}
+	}
+	return statements, nil
+}
+
+func getMapValue(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func getScalar(n *yaml.Node, key string) string {
+	if v := getMapValue(n, key); v != nil && v.Kind == yaml.ScalarNode {
+		return v.Value
+	}
+	return ""
+}
+
+// Returns true if the value of key in the statement (a string or a
+// list of strings) includes one of values.
+func hasValue(statement *yaml.Node, key string, values ...string) bool {
+	v := getMapValue(statement, key)
+	if v == nil {
+		return false
+	}
+	elements := []*yaml.Node{v}
+	if v.Kind == yaml.SequenceNode {
+		elements = v.Content
+	}
+	for _, e := range elements {
+		if e.Kind != yaml.ScalarNode {
+			continue
+		}
+		for _, value := range values {
+			if e.Value == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func checkWildcardAction(statement *yaml.Node) bool {
+	return hasValue(statement, "Action", "*", "*:*")
+}
+
+func checkWildcardResource(statement *yaml.Node) bool {
+	return hasValue(statement, "Resource", "*")
+}
+
+func checkNotAction(statement *yaml.Node) bool {
+	return getMapValue(statement, "NotAction") != nil
+}
+
+func checkNoCondition(statement *yaml.Node) bool {
+	return (checkWildcardAction(statement) || checkWildcardResource(statement)) &&
+		getMapValue(statement, "Condition") == nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iampolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	assert := assert.New(t)
+	tool := &Tool{}
+	tool.Directory = "testdata"
+	result, err := tool.Run()
+	if !assert.NoError(err) {
+		return
+	}
+	type finding struct {
+		rule, sid, file string
+		line            int
+	}
+	var findings []finding
+	for _, f := range result.Findings {
+		findings = append(findings, finding{f.Tool["rule_id"], f.Tool["sid"], f.FilePath, f.Line})
+	}
+	assert.ElementsMatch([]finding{
+		{"IAM_WILDCARD_ACTION", "Admin", "admin.json", 4},
+		{"IAM_WILDCARD_RESOURCE", "Admin", "admin.json", 4},
+		{"IAM_WILDCARD_NO_CONDITION", "Admin", "admin.json", 4},
+		{"IAM_WILDCARD_RESOURCE", "Describe", "policies/s3-read.json", 10},
+		{"IAM_ALLOW_NOT_ACTION", "", "policies/poweruser.json", 3},
+	}, findings)
+	assert.Equal(5, result.Data.Path("violations").Size())
+
+	tool = &Tool{}
+	tool.Directory = "testdata"
+	tool.Exclude = []string{"policies/**"}
+	assert.NoError(tool.Validate())
+	result, err = tool.Run()
+	assert.NoError(err)
+	assert.Len(result.Findings, 3)
+}
+
+func TestScanNotAPolicy(t *testing.T) {
+	assert := assert.New(t)
+	for _, doc := range []string{
+		`{"name": "policies", "Statement": [{"Effect": "Allow", "Action": "*", "Resource": "*"}]}`,
+		`{"Version": "2012-10-17"}`,
+		`{"Version": "2012-10-17", "Statement": "*"}`,
+		`{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Resource": "*"}]}`,
+		`["Version", "2012-10-17"]`,
+	} {
+		vs, err := scan("doc.json", []byte(doc))
+		assert.Error(err, doc)
+		assert.Empty(vs, doc)
+	}
+}
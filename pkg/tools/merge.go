@@ -0,0 +1,95 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+// Merge the results that share the directory of the first result into a
+// single result, so that one assessment represents the whole scan of that
+// directory.  The data of each result is kept under the name of the tool
+// that produced it, and findings for the same rule at the same location
+// (by partial fingerprint if available, otherwise by line) are only
+// included once.  Results for other directories are not included.
+func (results Results) Merge(name string) *Result {
+	if len(results) == 0 {
+		return nil
+	}
+	merged := &Result{
+		Data:      jnode.NewObjectNode(),
+		Directory: results[0].Directory,
+		Findings:  assessments.Findings{},
+	}
+	var tools []string
+	seen := map[string]bool{}
+	for i, result := range results {
+		if result.Directory != merged.Directory {
+			log.Warnf("Not merging results of {warning:%s} from {warning:%s}", result.Values["TOOL_NAME"], result.Directory)
+			continue
+		}
+		toolName := result.Values["TOOL_NAME"]
+		if toolName == "" {
+			toolName = strconv.Itoa(i)
+		}
+		tools = append(tools, toolName)
+		if result.Data != nil {
+			merged.Data.Put(toolName, result.Data)
+		}
+		for k, v := range result.Values {
+			merged.AddValue(k, v)
+		}
+		for _, a := range result.Artifacts {
+			merged.AddArtifact(a.Name, a.Data)
+		}
+		if result.Files != nil {
+			for _, f := range result.Files.Values() {
+				merged.AddFile(f)
+			}
+		}
+		if result.Findings != nil && result.FileFingerprints == nil {
+			result.UpdateFileFingerprints()
+		}
+		for _, f := range result.Findings {
+			key := getMergeKey(f)
+			if !seen[key] {
+				seen[key] = true
+				merged.Findings = append(merged.Findings, f)
+			}
+		}
+	}
+	merged.AddValue("TOOL_NAME", name).
+		AddValue("MERGED_TOOLS", strings.Join(tools, ","))
+	merged.UpdateFileFingerprints()
+	return merged
+}
+
+func getMergeKey(f *assessments.Finding) string {
+	rule := f.GetRuleID()
+	if rule == "" {
+		rule = f.Title
+	}
+	location := f.PartialFingerprint
+	if location == "" {
+		location = strconv.Itoa(f.Line)
+	}
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%t", rule, f.FilePath, location, f.Pass)
+}
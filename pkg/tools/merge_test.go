@@ -0,0 +1,61 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"testing"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	createFile(dir, "main.tf", "resource \"aws_s3_bucket\" \"b\" {\n  acl = \"public-read\"\n}\n")
+	checkov := &Result{
+		Data:      jnode.NewObjectNode().Put("check_type", "terraform"),
+		Directory: dir,
+		Findings: assessments.Findings{
+			{FilePath: "main.tf", Line: 1, Tool: map[string]string{"check_id": "CKV_AWS_20"}},
+			{FilePath: "main.tf", Line: 1, Tool: map[string]string{"check_id": "CKV_AWS_20"}},
+			{FilePath: "main.tf", Line: 2, Tool: map[string]string{"check_id": "CKV_AWS_21"}},
+		},
+	}
+	checkov.AddValue("TOOL_NAME", "checkov").AddValue("CHECKOV_VERSION", "2.0.1")
+	tfsec := &Result{
+		Data:      jnode.NewObjectNode().Put("results", 1),
+		Directory: dir,
+		Findings: assessments.Findings{
+			{FilePath: "main.tf", Line: 2, Tool: map[string]string{"rule_id": "aws-s3-no-public-buckets"}},
+		},
+	}
+	tfsec.AddValue("TOOL_NAME", "tfsec")
+	other := &Result{
+		Directory: t.TempDir(),
+		Findings:  assessments.Findings{{FilePath: "Dockerfile", Line: 1}},
+	}
+	m := Results{checkov, tfsec, other}.Merge("autoscan")
+	assert.Equal(dir, m.Directory)
+	assert.Len(m.Findings, 3)
+	assert.Equal("terraform", m.Data.Path("checkov").Path("check_type").AsText())
+	assert.Equal(1, m.Data.Path("tfsec").Path("results").AsInt())
+	assert.Equal("autoscan", m.Values["TOOL_NAME"])
+	assert.Equal("checkov,tfsec", m.Values["MERGED_TOOLS"])
+	assert.Equal("2.0.1", m.Values["CHECKOV_VERSION"])
+	assert.Len(m.FileFingerprints, 2)
+	assert.Nil(Results{}.Merge("autoscan"))
+}
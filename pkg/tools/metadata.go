@@ -0,0 +1,53 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"os"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+// Returns the metadata of the result as a JSON object.  This is the
+// CI environment and git information that's uploaded with the result,
+// and the result's values e.g. TOOL_NAME and CLI_VERSION.
+func (r *Result) GetMetadata() *jnode.Node {
+	n := jnode.NewObjectNode()
+	for k, v := range r.getCIEnv() {
+		n.Put(k, v)
+	}
+	for k, v := range r.Values {
+		n.Put(k, v)
+	}
+	return n
+}
+
+// Write the metadata of each result as a JSON array to path.
+func saveMetadata(path string, results Results) error {
+	n := jnode.NewArrayNode()
+	for _, result := range results {
+		n.Append(result.GetMetadata())
+	}
+	d, err := n.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, d, 0600); err != nil {
+		return err
+	}
+	log.Infof("Wrote scan metadata to {info:%s}", path)
+	return nil
+}
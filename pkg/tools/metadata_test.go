@@ -0,0 +1,52 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveMetadata(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("GITHUB_RUN_ID", "1234")
+	result := &Result{Directory: "."}
+	result.AddValue("TOOL_NAME", "checkov").AddValue("CLI_VERSION", "1.0.0")
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	assert.NoError(saveMetadata(path, Results{result}))
+	n, err := util.ReadJSONFile(path)
+	if assert.NoError(err) && assert.Equal(1, n.Size()) {
+		m := n.Get(0)
+		assert.Equal("checkov", m.Path("TOOL_NAME").AsText())
+		assert.Equal("1.0.0", m.Path("CLI_VERSION").AsText())
+		assert.Equal("1234", m.Path("GITHUB_RUN_ID").AsText())
+		assert.Equal("GITHUB", m.Path("SOLUBLE_METADATA_CI_SYSTEM").AsText())
+	}
+}
+
+func TestMetadataUsesCollectedCIEnv(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("GITHUB_RUN_ID", "1234")
+	result := &Result{
+		Directory: ".",
+		CIEnv:     map[string]string{"SOLUBLE_METADATA_GIT_BRANCH": "feature"},
+	}
+	m := result.GetMetadata()
+	assert.Equal("feature", m.Path("SOLUBLE_METADATA_GIT_BRANCH").AsText())
+	assert.True(m.Path("GITHUB_RUN_ID").IsMissing())
+}
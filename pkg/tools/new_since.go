@@ -0,0 +1,96 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/xcp"
+)
+
+// Parse a --new-since date, either a day e.g. 2022-01-31 or an RFC3339
+// time.
+func parseNewSince(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return t, fmt.Errorf("invalid --new-since %s, must be a date like 2022-01-31 or an RFC3339 time", s)
+	}
+	return t, nil
+}
+
+// Removes the findings in dir on lines that git blame says were last
+// changed before since, and returns the remaining findings and the
+// number removed.  Findings without a line, in files outside of the
+// repository, in untracked files, or on uncommitted lines are always
+// kept.
+func removeFindingsBefore(repoRoot, dir string, since time.Time, findings assessments.Findings) (assessments.Findings, int) {
+	var kept assessments.Findings
+	removed := 0
+	for _, f := range findings {
+		repoPath := getFindingRepoPath(repoRoot, dir, f)
+		if repoPath == "" || f.Line <= 0 {
+			kept = append(kept, f)
+			continue
+		}
+		blame, err := xcp.GetBlame(repoRoot, repoPath)
+		if err != nil {
+			log.Debugf("Could not blame {info:%s} - {warning:%s}", repoPath, err)
+			kept = append(kept, f)
+			continue
+		}
+		changed := blame.Get(f.Line).Time
+		if !changed.IsZero() && changed.Before(since) {
+			removed++
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept, removed
+}
+
+// The RepoPath of findings is filled in when the findings are
+// fingerprinted, which happens later.
+func getFindingRepoPath(repoRoot, dir string, f *assessments.Finding) string {
+	if f.RepoPath != "" {
+		return f.RepoPath
+	}
+	if f.FilePath == "" || f.GeneratedFile {
+		return ""
+	}
+	if path, ok := assessments.GetRepoPath(repoRoot, dir, f.FilePath); ok {
+		return path
+	}
+	return ""
+}
+
+func (o *ToolOpts) removeOldFindings(result *Result) {
+	if o.newSince.IsZero() || result.Directory == "" || o.RepoRoot == "" {
+		return
+	}
+	var removed int
+	result.Findings, removed = removeFindingsBefore(o.RepoRoot, result.Directory, o.newSince, result.Findings)
+	result.AddValue("NEW_SINCE", o.newSince.Format(time.RFC3339))
+	if removed > 0 {
+		log.Infof("Ignoring {info:%d} findings on lines last changed before {info:%s}", removed, o.NewSince)
+		result.AddValue("FINDINGS_BEFORE_NEW_SINCE", strconv.Itoa(removed))
+	}
+}
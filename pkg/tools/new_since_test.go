@@ -0,0 +1,69 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoveFindingsBefore(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	assert := assert.New(t)
+	repo := t.TempDir()
+	git := func(args ...string) {
+		c := exec.Command("git", args...)
+		c.Dir = repo
+		c.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := c.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %s %s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	dir := filepath.Join(repo, "infra")
+	assert.NoError(os.MkdirAll(dir, 0755))
+	assert.NoError(os.WriteFile(filepath.Join(dir, "main.tf"), []byte("one\n"), 0600))
+	git("add", ".")
+	t.Setenv("GIT_AUTHOR_DATE", "2020-01-01T00:00:00Z")
+	git("commit", "-q", "-m", "one")
+	assert.NoError(os.WriteFile(filepath.Join(dir, "main.tf"), []byte("one\ntwo\n"), 0600))
+	git("add", ".")
+	t.Setenv("GIT_AUTHOR_DATE", "2022-06-01T00:00:00Z")
+	git("commit", "-q", "-m", "two")
+	assert.NoError(os.WriteFile(filepath.Join(dir, "main.tf"), []byte("one\ntwo\nthree\n"), 0600))
+	assert.NoError(os.WriteFile(filepath.Join(dir, "new.tf"), []byte("new\n"), 0600))
+	_, err := parseNewSince("January")
+	assert.Error(err)
+	since, err := parseNewSince("2021-01-01")
+	assert.NoError(err)
+	findings := assessments.Findings{
+		{FilePath: "main.tf", Line: 1},
+		{FilePath: "main.tf", Line: 2},
+		{FilePath: "main.tf", Line: 3},
+		{FilePath: "new.tf", Line: 1},
+		{FilePath: "main.tf"},
+	}
+	kept, removed := removeFindingsBefore(repo, dir, since, findings)
+	assert.Equal(1, removed)
+	assert.Equal(findings[1:], kept)
+}
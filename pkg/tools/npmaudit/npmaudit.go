@@ -33,6 +33,14 @@ func (t *Tool) Name() string {
 	return "npm-audit"
 }
 
+func (t *Tool) Preflight() error {
+	return t.PreflightDocker()
+}
+
+func (t *Tool) Requirements() []string {
+	return []string{tools.RequiresDocker}
+}
+
 func (t *Tool) Run() (*tools.Result, error) {
 	args := []string{"audit", "--json"}
 	d, err := t.RunDocker(&tools.DockerTool{
@@ -42,7 +50,7 @@ func (t *Tool) Run() (*tools.Result, error) {
 		Directory:           t.GetDirectory(),
 		Args:                args,
 	})
-	if err != nil && tools.IsDockerError(err) {
+	if err != nil && tools.IsContainerError(err) {
 		return nil, err
 	}
 	results, err := jnode.FromJSON(d)
@@ -0,0 +1,166 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	ignore "github.com/sabhiram/go-gitignore"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+// Maps files that match a gitignore-style pattern to their owners
+type ownerRule struct {
+	pattern string
+	matcher *ignore.GitIgnore
+	owners  []string
+}
+
+func newOwnerRule(pattern string, owners []string) *ownerRule {
+	return &ownerRule{
+		pattern: pattern,
+		matcher: ignore.CompileIgnoreLines(pattern),
+		owners:  owners,
+	}
+}
+
+// Returns the owners of path from the last matching rule, or nil if
+// no rule matches.
+func findOwners(rules []*ownerRule, path string) []string {
+	var owners []string
+	for _, r := range rules {
+		if r.matcher.MatchesPath(path) {
+			owners = r.owners
+		}
+	}
+	return owners
+}
+
+// Returns the owners of paths in the repo from the config.  Each
+// pattern maps to an owner (e.g. a service or team) or a list of
+// owners, and the longest matching pattern wins.  The config looks
+// like:
+//
+//	owners:
+//	  services/billing/: billing
+//	  "services/*/api/": [api-team, platform]
+func (c *Config) getOwnerRules() []*ownerRule {
+	n := c.data.Path("owners")
+	if !n.IsObject() {
+		return nil
+	}
+	var rules []*ownerRule
+	for pattern, v := range n.Entries() {
+		var owners []string
+		if v.IsArray() {
+			for _, e := range v.Elements() {
+				owners = append(owners, e.AsText())
+			}
+		} else if s := v.AsText(); s != "" {
+			owners = []string{s}
+		}
+		if len(owners) == 0 {
+			log.Warnf("Ignoring owners pattern {warning:%s} without owners in {secondary:%s}", pattern, c.path)
+			continue
+		}
+		rules = append(rules, newOwnerRule(pattern, owners))
+	}
+	// since the last match wins, put the longest patterns last
+	sort.Slice(rules, func(i, j int) bool {
+		if len(rules[i].pattern) != len(rules[j].pattern) {
+			return len(rules[i].pattern) < len(rules[j].pattern)
+		}
+		return rules[i].pattern < rules[j].pattern
+	})
+	return rules
+}
+
+// Parse a CODEOWNERS file.  As with github, the last matching line
+// wins, and a line without owners means its files have no owner.
+func parseCodeOwners(r io.Reader) []*ownerRule {
+	var rules []*ownerRule
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		var owners []string
+		for _, owner := range fields[1:] {
+			if strings.HasPrefix(owner, "#") {
+				break
+			}
+			owners = append(owners, owner)
+		}
+		rules = append(rules, newOwnerRule(fields[0], owners))
+	}
+	return rules
+}
+
+// Read the CODEOWNERS file of the repo that's uploaded with the
+// results, or return nil if there isn't one.
+func readCodeOwners(repoRoot string) []*ownerRule {
+	for _, path := range repoFiles {
+		if filepath.Base(path) != "CODEOWNERS" {
+			continue
+		}
+		f, err := os.Open(filepath.Join(repoRoot, filepath.FromSlash(path)))
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+		return parseCodeOwners(f)
+	}
+	return nil
+}
+
+// Set the owners of findings from the owners config, falling back to
+// the CODEOWNERS file.
+func applyOwners(configRules, codeOwners []*ownerRule, repoRoot, dir string, findings assessments.Findings) {
+	for _, f := range findings {
+		path := getFindingRepoPath(repoRoot, dir, f)
+		if path == "" {
+			continue
+		}
+		owners := findOwners(configRules, path)
+		if owners == nil {
+			owners = findOwners(codeOwners, path)
+		}
+		if len(owners) > 0 {
+			f.Owners = owners
+		}
+	}
+}
+
+func (o *ToolOpts) applyOwners(result *Result) {
+	if result.Directory == "" || o.RepoRoot == "" {
+		return
+	}
+	if !o.ownerRulesRead {
+		o.ownerRules = o.GetConfig().getOwnerRules()
+		o.codeOwners = readCodeOwners(o.RepoRoot)
+		o.ownerRulesRead = true
+	}
+	if len(o.ownerRules) == 0 && len(o.codeOwners) == 0 {
+		return
+	}
+	applyOwners(o.ownerRules, o.codeOwners, o.RepoRoot, result.Directory, result.Findings)
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCodeOwners(t *testing.T) {
+	assert := assert.New(t)
+	rules := parseCodeOwners(strings.NewReader(`# default owners
+*       @acme/everyone
+/services/billing/  @acme/billing @jo   # billing team
+*.md    @acme/docs
+/services/billing/vendor/
+`))
+	assert.Len(rules, 4)
+	assert.Equal([]string{"@acme/everyone"}, findOwners(rules, "main.tf"))
+	assert.Equal([]string{"@acme/billing", "@jo"}, findOwners(rules, "services/billing/main.tf"))
+	assert.Equal([]string{"@acme/docs"}, findOwners(rules, "services/billing/README.md"))
+	assert.Nil(findOwners(rules, "services/billing/vendor/x/main.tf"))
+	assert.Nil(findOwners(nil, "main.tf"))
+}
+
+func TestOwners(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	assert.NoError(os.MkdirAll(filepath.Join(dir, ".github"), 0700))
+	assert.NoError(os.WriteFile(filepath.Join(dir, ".github", "CODEOWNERS"),
+		[]byte("* @acme/everyone\n"), 0600))
+	path := filepath.Join(dir, "config.yml")
+	assert.NoError(os.WriteFile(path, []byte(`owners:
+  services/: platform
+  services/billing/: [billing, finance]
+  services/empty/: []
+`), 0600))
+	c, err := LoadConfigFile(path)
+	assert.NoError(err)
+	rules := c.getOwnerRules()
+	assert.Len(rules, 2)
+	findings := assessments.Findings{
+		{FilePath: "billing/main.tf"},
+		{FilePath: "search/main.tf"},
+		{FilePath: "../main.tf"},
+		{FilePath: "plan.json", GeneratedFile: true},
+	}
+	applyOwners(rules, readCodeOwners(dir), dir, filepath.Join(dir, "services"), findings)
+	assert.Equal([]string{"billing", "finance"}, findings[0].Owners)
+	assert.Equal([]string{"platform"}, findings[1].Owners)
+	assert.Equal([]string{"@acme/everyone"}, findings[2].Owners)
+	assert.Nil(findings[3].Owners)
+}
+
+func TestReadCodeOwnersOrder(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	assert.NoError(os.MkdirAll(filepath.Join(dir, "docs"), 0700))
+	assert.NoError(os.WriteFile(filepath.Join(dir, "docs", "CODEOWNERS"), []byte("* @docs\n"), 0600))
+	assert.Equal([]string{"@docs"}, findOwners(readCodeOwners(dir), "main.tf"))
+	assert.NoError(os.WriteFile(filepath.Join(dir, "CODEOWNERS"), []byte("* @root\n"), 0600))
+	assert.Equal([]string{"@root"}, findOwners(readCodeOwners(dir), "main.tf"))
+	assert.NoError(os.MkdirAll(filepath.Join(dir, ".github"), 0700))
+	assert.NoError(os.WriteFile(filepath.Join(dir, ".github", "CODEOWNERS"), []byte("* @github\n"), 0600))
+	assert.Equal([]string{"@github"}, findOwners(readCodeOwners(dir), "main.tf"))
+}
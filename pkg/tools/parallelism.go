@@ -0,0 +1,63 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"runtime"
+	"sync"
+)
+
+// The worker pool bounds how many tools (or tool processes) run at the same
+// time across all concurrent execution paths.
+type workerPool struct {
+	slots chan struct{}
+}
+
+var (
+	workers     = newWorkerPool(runtime.GOMAXPROCS(0))
+	workersLock sync.Mutex
+)
+
+func newWorkerPool(n int) *workerPool {
+	if n <= 0 {
+		return &workerPool{}
+	}
+	return &workerPool{slots: make(chan struct{}, n)}
+}
+
+func (p *workerPool) acquire() func() {
+	if p.slots == nil {
+		return func() {}
+	}
+	p.slots <- struct{}{}
+	return func() { <-p.slots }
+}
+
+// Limit the number of concurrently running processes to n.  If n <= 0
+// then concurrency is unbounded.
+func SetParallelism(n int) {
+	workersLock.Lock()
+	defer workersLock.Unlock()
+	workers = newWorkerPool(n)
+}
+
+// Wait for a worker to become available and return a function that
+// releases it, e.g. defer AcquireWorker()()
+func AcquireWorker() func() {
+	workersLock.Lock()
+	p := workers
+	workersLock.Unlock()
+	return p.acquire()
+}
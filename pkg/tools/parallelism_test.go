@@ -0,0 +1,84 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerPool(t *testing.T) {
+	assert := assert.New(t)
+	for _, n := range []int{1, 3} {
+		p := newWorkerPool(n)
+		var active, max int32
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer p.acquire()()
+				a := atomic.AddInt32(&active, 1)
+				for {
+					m := atomic.LoadInt32(&max)
+					if a <= m || atomic.CompareAndSwapInt32(&max, m, a) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&active, -1)
+			}()
+		}
+		wg.Wait()
+		assert.LessOrEqual(int(max), n)
+		assert.Greater(int(max), 0)
+	}
+	// unbounded
+	p := newWorkerPool(0)
+	for i := 0; i < 10; i++ {
+		p.acquire()
+	}
+}
+
+func TestParallelismLimitsExec(t *testing.T) {
+	assert := assert.New(t)
+	SetParallelism(1)
+	defer SetParallelism(runtime.GOMAXPROCS(0))
+	log := filepath.Join(t.TempDir(), "log")
+	opts := &RunOpts{ToolPath: "sh"}
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := opts.RunDocker(&DockerTool{
+				Args: []string{"-c", `echo start >> "$0"; sleep 0.05; echo end >> "$0"`, log},
+			})
+			assert.NoError(err)
+		}()
+	}
+	wg.Wait()
+	d, err := os.ReadFile(log)
+	assert.NoError(err)
+	assert.Equal(strings.Repeat("start\nend\n", 4), string(d))
+}
@@ -0,0 +1,247 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package polaris
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/download"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/tracing"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+type Tool struct {
+	tools.DirectoryBasedToolOpts
+
+	manifests map[string]*manifestLocation
+}
+
+type manifestLocation struct {
+	file string
+	line int
+}
+
+var _ tools.Single = &Tool{}
+
+func (t *Tool) Name() string {
+	return "polaris"
+}
+
+func (t *Tool) Preflight() error {
+	return t.PreflightInstall(&download.Spec{
+		URL: "github.com/FairwindsOps/polaris",
+	})
+}
+
+func (t *Tool) Requirements() []string {
+	return []string{tools.RequiresDownload}
+}
+
+func (t *Tool) CommandTemplate() *cobra.Command {
+	return &cobra.Command{
+		Use:   "polaris",
+		Short: "Check kubernetes manifests for workload best-practices with polaris",
+	}
+}
+
+func (t *Tool) Run() (*tools.Result, error) {
+	m := t.GetInventory()
+	if m.KubernetesManifestDirectories.Len() == 0 {
+		log.Infof("No kubernetes manifests found in {info:%s}", t.GetDirectory())
+		return &tools.Result{
+			Directory: t.GetDirectory(),
+			Data:      jnode.NewObjectNode(),
+			Findings:  assessments.Findings{},
+		}, nil
+	}
+	t.manifests = t.findManifests(m.KubernetesManifestDirectories.Values())
+	d, err := t.InstallTool(&download.Spec{
+		URL: "github.com/FairwindsOps/polaris",
+	})
+	if err != nil {
+		return nil, err
+	}
+	// #nosec G204
+	c := exec.Command(d.GetExePath("polaris"), "audit", "--format", "json", "--audit-path", t.GetDirectory())
+	c.Stderr = os.Stderr
+	defer tools.AcquireWorker()()
+	t.LogCommand(c)
+	span := t.StartCommandSpan(c)
+	output, err := c.Output()
+	tracing.EndSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	n, err := jnode.FromJSON(output)
+	if err != nil {
+		return nil, err
+	}
+	result := t.parseResults(n)
+	if d.Version != "" {
+		result.AddValue("POLARIS_VERSION", d.Version)
+	}
+	return result, nil
+}
+
+func (t *Tool) parseResults(n *jnode.Node) *tools.Result {
+	findings := assessments.Findings{}
+	results := n.Path("Results")
+	if results.Size() > 0 {
+		results = util.RemoveJNodeElementsIf(results, func(e *jnode.Node) bool {
+			loc := t.getManifestLocation(e)
+			return loc != nil && t.IsExcluded(loc.file)
+		})
+		n.Put("Results", results)
+	}
+	for _, r := range results.Elements() {
+		loc := t.getManifestLocation(r)
+		if loc == nil {
+			loc = &manifestLocation{}
+		}
+		checks := []*jnode.Node{r.Path("Results"), r.Path("PodResult").Path("Results")}
+		for _, cr := range r.Path("PodResult").Path("ContainerResults").Elements() {
+			checks = append(checks, cr.Path("Results"))
+		}
+		for _, check := range checks {
+			findings = append(findings, getFailedChecks(r, loc, check)...)
+		}
+	}
+	return &tools.Result{
+		Directory: t.GetDirectory(),
+		Data:      n,
+		Findings:  findings,
+	}
+}
+
+func getFailedChecks(r *jnode.Node, loc *manifestLocation, checks *jnode.Node) assessments.Findings {
+	var findings assessments.Findings
+	ids := make([]string, 0, checks.Size())
+	for id := range checks.Entries() {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		check := checks.Path(id)
+		if check.Path("Success").AsBool() {
+			continue
+		}
+		findings = append(findings, &assessments.Finding{
+			FilePath:    loc.file,
+			Line:        loc.line,
+			Description: check.Path("Message").AsText(),
+			Tool: map[string]string{
+				"rule_id":  check.Path("ID").AsText(),
+				"severity": mapSeverity(check.Path("Severity").AsText()),
+				"category": check.Path("Category").AsText(),
+				"kind":     r.Path("Kind").AsText(),
+				"name":     r.Path("Name").AsText(),
+			},
+		})
+	}
+	return findings
+}
+
+func mapSeverity(s string) string {
+	switch s {
+	case "danger":
+		return "high"
+	case "warning":
+		return "medium"
+	default:
+		return "info"
+	}
+}
+
+func (t *Tool) getManifestLocation(r *jnode.Node) *manifestLocation {
+	return t.manifests[manifestKey(r.Path("Kind").AsText(), r.Path("Namespace").AsText(),
+		r.Path("Name").AsText())]
+}
+
+func manifestKey(kind, namespace, name string) string {
+	return strings.Join([]string{kind, namespace, name}, "/")
+}
+
+// polaris doesn't report which file a resource came from, so we index the
+// manifests by kind, namespace, and name to find it.
+func (t *Tool) findManifests(dirs []string) map[string]*manifestLocation {
+	manifests := map[string]*manifestLocation{}
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(filepath.Join(t.GetDirectory(), dir))
+		if err != nil {
+			log.Warnf("Could not read {warning:%s}", err)
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || !(strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml")) {
+				continue
+			}
+			file := filepath.Join(dir, name)
+			if err := indexManifest(manifests, t.GetDirectory(), file); err != nil {
+				log.Warnf("Could not read {warning:%s} - {warning:%s}", file, err)
+			}
+		}
+	}
+	return manifests
+}
+
+func indexManifest(manifests map[string]*manifestLocation, dir, file string) error {
+	f, err := os.Open(filepath.Join(dir, file))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	dec := yaml.NewDecoder(f)
+	for {
+		var doc struct {
+			Kind     string `yaml:"kind"`
+			Metadata struct {
+				Name      string `yaml:"name"`
+				Namespace string `yaml:"namespace"`
+			} `yaml:"metadata"`
+		}
+		var node yaml.Node
+		err := dec.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := node.Decode(&doc); err != nil || doc.Kind == "" {
+			continue
+		}
+		line := node.Line
+		if len(node.Content) > 0 {
+			line = node.Content[0].Line
+		}
+		manifests[manifestKey(doc.Kind, doc.Metadata.Namespace, doc.Metadata.Name)] = &manifestLocation{
+			file: file,
+			line: line,
+		}
+	}
+}
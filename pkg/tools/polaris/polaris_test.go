@@ -0,0 +1,60 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package polaris
+
+import (
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseResults(t *testing.T) {
+	assert := assert.New(t)
+	results, err := util.ReadJSONFile("testdata/results.json")
+	assert.Nil(err)
+	tool := &Tool{
+		DirectoryBasedToolOpts: tools.DirectoryBasedToolOpts{
+			Directory: "testdata",
+		},
+	}
+	tool.manifests = tool.findManifests([]string{"manifests"})
+	result := tool.parseResults(results)
+	assert.Equal(3, len(result.Findings))
+	f := result.Findings[0]
+	assert.Equal("manifests/deployment.yaml", f.FilePath)
+	assert.Equal(9, f.Line)
+	assert.Equal("runAsRootAllowed", f.Tool["rule_id"])
+	assert.Equal("medium", f.Tool["severity"])
+	assert.Equal("high", result.Findings[1].Tool["severity"])
+}
+
+func TestExcluded(t *testing.T) {
+	assert := assert.New(t)
+	results, err := util.ReadJSONFile("testdata/results.json")
+	assert.Nil(err)
+	tool := &Tool{
+		DirectoryBasedToolOpts: tools.DirectoryBasedToolOpts{
+			Directory: "testdata",
+			Exclude:   []string{"deployment.yaml"},
+		},
+	}
+	assert.Nil(tool.Validate())
+	tool.manifests = tool.findManifests([]string{"manifests"})
+	result := tool.parseResults(results)
+	assert.Empty(result.Findings)
+	assert.Equal(0, result.Data.Path("Results").Size())
+}
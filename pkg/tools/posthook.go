@@ -0,0 +1,59 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/xcp"
+)
+
+// Run the --post-scan-hook command with sh, passing it the findings as
+// a JSON array on stdin and the counts of failed findings in the
+// environment e.g. SOLUBLE_FINDINGS_HIGH.  Variables that look like
+// secrets are not passed on.  The output of the command is logged.
+func runPostScanHook(command, toolName string, results Results) error {
+	findings, err := results.getFindingsJNode()
+	if err != nil {
+		return err
+	}
+	counts, total := results.getSeverityCounts()
+	env := append(xcp.RedactEnv(os.Environ()),
+		fmt.Sprintf("SOLUBLE_TOOL=%s", toolName),
+		fmt.Sprintf("SOLUBLE_FINDINGS_TOTAL=%d", total))
+	for _, name := range severityCountNames {
+		env = append(env, fmt.Sprintf("SOLUBLE_FINDINGS_%s=%d", strings.ToUpper(name), counts[name]))
+	}
+	// #nosec G204
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Env = env
+	cmd.Stdin = strings.NewReader(findings.String())
+	log.Infof("Running post-scan hook {primary:%s}", command)
+	output, err := cmd.CombinedOutput()
+	s := bufio.NewScanner(bytes.NewReader(output))
+	for s.Scan() {
+		log.Infof("{secondary:post-scan-hook:} %s", s.Text())
+	}
+	if err != nil {
+		return fmt.Errorf("post-scan hook %s failed: %w", command, err)
+	}
+	return nil
+}
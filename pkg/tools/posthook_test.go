@@ -0,0 +1,62 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunPostScanHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a shell")
+	}
+	assert := assert.New(t)
+	dir := t.TempDir()
+	t.Setenv("MY_API_TOKEN", "xyzzy")
+	t.Setenv("TEAM", "platform")
+	results := Results{
+		{
+			Findings: assessments.Findings{
+				{Severity: "high", FilePath: "main.tf"},
+				{Severity: "low", FilePath: "vars.tf", Pass: true},
+			},
+		},
+	}
+	command := `cat > findings.json; env > env.txt`
+	assert.NoError(runPostScanHook("cd "+dir+" && "+command, "checkov", results))
+	d, err := os.ReadFile(filepath.Join(dir, "findings.json"))
+	assert.NoError(err)
+	var findings assessments.Findings
+	if assert.NoError(json.Unmarshal(d, &findings)) {
+		assert.Len(findings, 2)
+	}
+	d, err = os.ReadFile(filepath.Join(dir, "env.txt"))
+	assert.NoError(err)
+	env := strings.Split(string(d), "\n")
+	assert.Contains(env, "SOLUBLE_TOOL=checkov")
+	assert.Contains(env, "SOLUBLE_FINDINGS_HIGH=1")
+	assert.Contains(env, "SOLUBLE_FINDINGS_TOTAL=1")
+	assert.Contains(env, "TEAM=platform")
+	assert.NotContains(string(d), "xyzzy")
+	assert.Error(runPostScanHook("exit 1", "checkov", results))
+}
@@ -0,0 +1,60 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// A tool and the command that runs it
+type RegisteredTool struct {
+	Tool    Interface
+	Command *cobra.Command
+}
+
+var registeredTools []*RegisteredTool
+
+// Every command created for a tool is registered here so that the
+// available tools can be discovered.
+func registerTool(tool Interface, cmd *cobra.Command) {
+	registeredTools = append(registeredTools, &RegisteredTool{
+		Tool:    tool,
+		Command: cmd,
+	})
+}
+
+// Returns all the registered tools, sorted by command path.
+func GetRegisteredTools() []*RegisteredTool {
+	result := make([]*RegisteredTool, len(registeredTools))
+	copy(result, registeredTools)
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Command.CommandPath() < result[j].Command.CommandPath()
+	})
+	return result
+}
+
+// Returns the names of the visible flags of the tool's command.
+func (rt *RegisteredTool) GetFlags() []string {
+	var names []string
+	rt.Command.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		if !f.Hidden {
+			names = append(names, f.Name)
+		}
+	})
+	return names
+}
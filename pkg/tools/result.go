@@ -16,11 +16,16 @@ package tools
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/soluble-ai/go-jnode"
@@ -28,24 +33,53 @@ import (
 	"github.com/soluble-ai/soluble-cli/pkg/assessments"
 	"github.com/soluble-ai/soluble-cli/pkg/inventory"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/s3"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/soluble-ai/soluble-cli/pkg/xcp"
+	"gopkg.in/yaml.v3"
 )
 
 type Result struct {
-	Data             *jnode.Node
-	Findings         assessments.Findings
-	Values           map[string]string
-	Directory        string
+	Data      *jnode.Node
+	Findings  assessments.Findings
+	Values    map[string]string
+	Directory string
+	// The CI environment and git metadata of the result, collected
+	// once and then used for the upload and --emit-metadata
+	CIEnv            map[string]string
 	Files            *util.StringSet
 	FileFingerprints []*FileFingerprint
+	// Additional files uploaded with the results, e.g. a software bill
+	// of materials
+	Artifacts []*Artifact
 
 	Assessment    *assessments.Assessment
 	AssessmentRaw *jnode.Node
+
+	// set once the result has been processed, so that the results of
+	// tools run by a consolidated tool aren't processed twice
+	processed bool
+	// the writer of the findings that were uploaded while the tool ran,
+	// which the results are then uploaded with
+	batches *batchWriter
+	// the results.json as it was last uploaded
+	uploadedResultsJSON []byte
 }
 
 type Results []*Result
 
+type Artifact struct {
+	// The file name, e.g. sbom.json
+	Name string
+	Data []byte
+}
+
+type uploadFile struct {
+	param    string
+	filename string
+	data     []byte
+}
+
 type FileFingerprint struct {
 	Line               int    `json:"line"`
 	RepoPath           string `json:"repoPath,omitempty"`
@@ -54,12 +88,14 @@ type FileFingerprint struct {
 	MultiDocumentFile  bool   `json:"multiDocumentFile,omitempty"`
 }
 
+// Only the first of the files with the same name is uploaded.  The
+// CODEOWNERS files are in the order that github looks for them.
 var repoFiles = []string{
 	".lacework/config.yml",
 	".soluble/config.yml",
+	".github/CODEOWNERS",
 	"CODEOWNERS",
 	"docs/CODEOWNERS",
-	".github/CODEOWNERS",
 }
 
 func (r *Result) AddFile(path string) *Result {
@@ -78,11 +114,169 @@ func (r *Result) AddValue(name, value string) *Result {
 	return r
 }
 
-func (r *Result) Upload(client *api.Client, org, name string) error {
-	rr := bytes.NewReader([]byte(r.Data.String()))
+// Add an artifact to be uploaded with the results, replacing any
+// artifact with the same name.  The artifact is uploaded as a file
+// whose parameter is the name with "." replaced by "_".
+func (r *Result) AddArtifact(name string, data []byte) *Result {
+	for _, a := range r.Artifacts {
+		if a.Name == name {
+			a.Data = data
+			return r
+		}
+	}
+	r.Artifacts = append(r.Artifacts, &Artifact{Name: name, Data: data})
+	return r
+}
+
+// Upload the results.  If sizeLimit > 0 and the findings and fingerprints
+// together are larger than sizeLimit bytes, or findings were uploaded
+// while the tool ran, the findings are uploaded in chunks instead.
+func (r *Result) Upload(client *api.Client, org, name string, sizeLimit int, options ...api.Option) error {
+	if r.batches != nil {
+		return r.uploadChunked(client, org, name, sizeLimit, options)
+	}
+	if sizeLimit > 0 && r.Findings != nil {
+		size := r.getFindingsSize() + r.getFingerprintsSize()
+		if size > sizeLimit {
+			return r.uploadChunked(client, org, name, sizeLimit, options)
+		}
+	}
 	log.Infof("Uploading results of {primary:%s}", name)
-	options := []api.Option{
-		xcp.WithCIEnv(r.Directory), xcp.WithFileFromReader("results_json", "results.json", rr),
+	files := r.getUploadFiles()
+	if r.Findings != nil {
+		if rf := r.attachFindings(); rf != nil {
+			d, _ := io.ReadAll(rf)
+			files = append(files, &uploadFile{param: "findings_json", filename: "findings.json", data: d})
+		}
+	}
+	if getUploadFilesSize(files) >= resumableUploadThreshold {
+		n, err := r.uploadResumable(client, org, name, files, options)
+		if err == nil {
+			r.setAssessment(n, name)
+			return nil
+		}
+		if !errors.Is(err, errUploadSessionsNotSupported) {
+			return err
+		}
+		log.Debugf("Resumable uploads are not supported, uploading all at once")
+	}
+	options = append(options, xcp.WithCIEnvValues(r.getCIEnv()))
+	options = append(options, withUploadFiles(files)...)
+	n, err := client.XCPPost(org, name, nil, r.Values, options...)
+	if err != nil {
+		return err
+	}
+	r.setAssessment(n, name)
+	return nil
+}
+
+// Upload the findings in chunks of at most sizeLimit bytes, tied together
+// by a scan id.  The results and fingerprints are sent in a final upload,
+// and the number of uploads is recorded in the results.
+func (r *Result) uploadChunked(client *api.Client, org, name string, sizeLimit int, options []api.Option) error {
+	var chunks []assessments.Findings
+	if sizeLimit > 0 {
+		chunks = getFindingChunks(getSortedFindings(r.Findings), sizeLimit)
+	} else if len(r.Findings) > 0 {
+		chunks = []assessments.Findings{getSortedFindings(r.Findings)}
+	}
+	if fs := r.getFingerprintsSize(); sizeLimit > 0 && fs > sizeLimit {
+		log.Warnf("The fingerprints of {primary:%s} are {warning:%d} bytes which is more than the upload limit of {warning:%d} bytes",
+			name, fs, sizeLimit)
+	}
+	count := len(chunks) + 1
+	r.AddValue("UPLOAD_CHUNKS", strconv.Itoa(count))
+	w := r.batches
+	if w == nil {
+		w = newBatchWriter(client, org, name, r.Values)
+	} else {
+		// the results replace the findings uploaded while the tool ran
+		w.Values = r.Values
+		w.Phase = "results"
+	}
+	// every chunk has the CI environment so it can be attributed on its own
+	w.Options = append(options, xcp.WithCIEnvValues(r.getCIEnv()))
+	log.Infof("Uploading results of {primary:%s} in {info:%d} chunks as scan {info:%s}", name, count, w.ScanID)
+	if r.Data != nil && r.Data.IsObject() {
+		r.Data.PutObject("soluble_upload_chunks").
+			Put("count", count).
+			Put("scanId", w.ScanID)
+	}
+	for _, chunk := range chunks {
+		w.Add(chunk...)
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	n, err := w.Close(withUploadFiles(r.getUploadFiles())...)
+	if err != nil {
+		return err
+	}
+	r.setAssessment(n, name)
+	return nil
+}
+
+// Split findings into chunks whose JSON encoding is at most sizeLimit
+// bytes.  A single finding larger than sizeLimit gets a chunk of its own.
+func getFindingChunks(findings assessments.Findings, sizeLimit int) []assessments.Findings {
+	var (
+		chunks []assessments.Findings
+		chunk  assessments.Findings
+		size   int
+	)
+	for _, f := range findings {
+		d, err := json.Marshal(f)
+		if err != nil {
+			log.Warnf("Could not marshal finding: {warning:%s}", err)
+			continue
+		}
+		// "[" + "]" or ","
+		fsize := len(d) + 1
+		if len(chunk) > 0 && size+fsize > sizeLimit {
+			chunks = append(chunks, chunk)
+			chunk = nil
+		}
+		if len(chunk) == 0 {
+			size = 1
+		}
+		chunk = append(chunk, f)
+		size += fsize
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+func (r *Result) getFindingsSize() int {
+	d, err := json.Marshal(r.Findings)
+	if err != nil {
+		return 0
+	}
+	return len(d)
+}
+
+func (r *Result) getFingerprintsSize() int {
+	d, err := json.Marshal(r.FileFingerprints)
+	if err != nil {
+		return 0
+	}
+	return len(d)
+}
+
+func (r *Result) getCIEnv() map[string]string {
+	if r.CIEnv == nil {
+		r.CIEnv = xcp.GetCIEnv(r.Directory)
+	}
+	return r.CIEnv
+}
+
+// Returns the files uploaded with every result, and keeps the
+// results.json for --save-results.  The findings are uploaded separately.
+func (r *Result) getUploadFiles() []*uploadFile {
+	r.uploadedResultsJSON = r.getResultsJSON()
+	files := []*uploadFile{
+		{param: "results_json", filename: "results.json", data: r.uploadedResultsJSON},
 	}
 	dir, _ := inventory.FindRepoRoot(r.Directory)
 	if dir != "" {
@@ -95,28 +289,36 @@ func (r *Result) Upload(client *api.Client, org, name string) error {
 				// don't include 0 length files
 				continue
 			}
-			if f, err := os.Open(p); err == nil {
-				defer f.Close()
+			if d, err := os.ReadFile(p); err == nil {
 				name := filepath.Base(path)
 				if names.Add(name) {
 					// only include one
-					options = append(options, xcp.WithFileFromReader(name, name, f))
+					files = append(files, &uploadFile{param: name, filename: name, data: d})
 				}
 			}
 		}
 	}
 	if r.Findings != nil {
-		if rf := r.attachFindings(); rf != nil {
-			options = append(options, xcp.WithFileFromReader("findings_json", "findings.json", rf))
-		}
 		if rf := r.attachFingerprints(); rf != nil {
-			options = append(options, xcp.WithFileFromReader("fingerprints_json", "fingerprints.json", rf))
+			d, _ := io.ReadAll(rf)
+			files = append(files, &uploadFile{param: "fingerprints_json", filename: "fingerprints.json", data: d})
 		}
 	}
-	n, err := client.XCPPost(org, name, nil, r.Values, options...)
-	if err != nil {
-		return err
+	for _, a := range r.Artifacts {
+		files = append(files, &uploadFile{param: strings.ReplaceAll(a.Name, ".", "_"), filename: a.Name, data: a.Data})
 	}
+	return files
+}
+
+func withUploadFiles(files []*uploadFile) []api.Option {
+	options := make([]api.Option, 0, len(files))
+	for _, f := range files {
+		options = append(options, xcp.WithFileFromReader(f.param, f.filename, bytes.NewReader(f.data)))
+	}
+	return options
+}
+
+func (r *Result) setAssessment(n *jnode.Node, name string) {
 	if n.Path("assessment").IsObject() {
 		r.AssessmentRaw = n.Path("assessment")
 		r.Assessment = &assessments.Assessment{}
@@ -128,9 +330,57 @@ func (r *Result) Upload(client *api.Client, org, name string) error {
 	if r.Assessment == nil {
 		log.Infof("No assessment for {warning:%s} was returned", name)
 	}
+}
+
+// Write the results, findings, and fingerprints to an S3 bucket under
+// prefix, using the same serialization as Upload.
+func (r *Result) WriteS3(client *s3.Client, bucket, prefix string) error {
+	names := []string{"results.json"}
+	readers := []io.Reader{bytes.NewReader(r.getResultsJSON())}
+	if r.Findings != nil {
+		if rf := r.attachFindings(); rf != nil {
+			names = append(names, "findings.json")
+			readers = append(readers, rf)
+		}
+		names = append(names, "fingerprints.json")
+		readers = append(readers, r.attachFingerprints())
+	}
+	for _, a := range r.Artifacts {
+		names = append(names, a.Name)
+		readers = append(readers, bytes.NewReader(a.Data))
+	}
+	for i, name := range names {
+		d, err := io.ReadAll(readers[i])
+		if err != nil {
+			return err
+		}
+		if err := client.Put(context.Background(), bucket, path.Join(prefix, name), d); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// Limit the findings to at most max, keeping the most severe ones.  If
+// findings are dropped then the original count is recorded in the values
+// and in the data (if the data is an object) so that the result is known to
+// be partial.
+func (r *Result) TruncateFindings(max int) {
+	count := len(r.Findings)
+	if max <= 0 || count <= max {
+		return
+	}
+	r.Findings.SortBySeverity()
+	r.Findings = r.Findings[:max]
+	log.Warnf("Truncating {warning:%d} findings to {warning:%d}", count, max)
+	r.AddValue("FINDINGS_TRUNCATED", strconv.Itoa(count))
+	if r.Data != nil && r.Data.IsObject() {
+		r.Data.PutObject("soluble_findings_truncated").
+			Put("count", count).
+			Put("max", max)
+	}
+}
+
 func (r *Result) UpdateFileFingerprints() {
 	if r.Directory == "" {
 		return
@@ -162,6 +412,13 @@ func (r *Result) UpdateFileFingerprints() {
 				MultiDocumentFile:  md != nil && *md,
 			})
 	}
+	sort.Slice(r.FileFingerprints, func(i, j int) bool {
+		a, b := r.FileFingerprints[i], r.FileFingerprints[j]
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		return a.Line < b.Line
+	})
 }
 
 func (r *Result) isMultiDocument(path string) bool {
@@ -169,28 +426,89 @@ func (r *Result) isMultiDocument(path string) bool {
 		path = filepath.Join(r.Directory, path)
 	}
 	if strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") {
-		var (
-			multiDocument bool
-			lineNo        int
-		)
-		err := util.ForEachLine(path, func(line string) bool {
-			lineNo++
-			if lineNo > 1 && line == "---" {
-				multiDocument = true
-				return false
-			}
-			return true
-		})
+		f, err := os.Open(path)
 		if err != nil {
 			log.Warnf("{warning:%s}", err)
+			return false
 		}
-		return multiDocument
+		defer f.Close()
+		count, err := countYAMLDocuments(f)
+		if err != nil {
+			// not valid YAML (e.g. a helm template) so just look for separators
+			return hasDocumentSeparator(path)
+		}
+		return count > 1
 	}
 	return false
 }
 
+// Count the non-empty documents in r, stopping after the second.
+func countYAMLDocuments(r io.Reader) (int, error) {
+	dec := yaml.NewDecoder(r)
+	count := 0
+	for count < 2 {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		if len(doc.Content) > 0 && doc.Content[0].Tag != "!!null" {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func hasDocumentSeparator(path string) bool {
+	var (
+		multiDocument bool
+		lineNo        int
+	)
+	err := util.ForEachLine(path, func(line string) bool {
+		lineNo++
+		if lineNo > 1 && line == "---" {
+			multiDocument = true
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		log.Warnf("{warning:%s}", err)
+	}
+	return multiDocument
+}
+
+// Returns the results.json content exactly as it's uploaded
+func (r *Result) getResultsJSON() []byte {
+	return []byte(r.Data.String())
+}
+
+// Returns a copy of the findings sorted by file, line, and rule so that
+// the same findings are always uploaded in the same order.
+func getSortedFindings(findings assessments.Findings) assessments.Findings {
+	sorted := make(assessments.Findings, len(findings))
+	copy(sorted, findings)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if ra, rb := a.GetRuleID(), b.GetRuleID(); ra != rb {
+			return ra < rb
+		}
+		return a.Title < b.Title
+	})
+	return sorted
+}
+
 func (r *Result) attachFindings() io.Reader {
-	fd, err := json.Marshal(r.Findings)
+	fd, err := json.Marshal(getSortedFindings(r.Findings))
 	if err != nil {
 		log.Warnf("Could not marshal findings: {warning:%s}", err)
 		return nil
@@ -206,7 +524,9 @@ func (r *Result) attachFingerprints() io.Reader {
 	return bytes.NewReader(d)
 }
 
-func (results Results) getFindingsJNode() (*jnode.Node, error) {
+// Returns the findings of all the results, preferring the findings of
+// the uploaded assessments.
+func (results Results) getFindings() []*assessments.Finding {
 	var findings []*assessments.Finding
 	for _, result := range results {
 		if result.Assessment != nil {
@@ -215,33 +535,67 @@ func (results Results) getFindingsJNode() (*jnode.Node, error) {
 			findings = append(findings, result.Findings...)
 		}
 	}
-	d, err := json.Marshal(findings)
+	return findings
+}
+
+func (results Results) getFindingsJNode() (*jnode.Node, error) {
+	d, err := json.Marshal(results.getFindings())
 	if err != nil {
 		return nil, err
 	}
 	return jnode.FromJSON(d)
 }
 
+// Returns the directory of the result relative to the root of its
+// repository, or "" if it isn't in one.
+func (r *Result) getRepoDirectory() string {
+	if dir := r.Values["ASSESSMENT_DIRECTORY"]; dir != "" {
+		return dir
+	}
+	if r.Directory == "" {
+		return ""
+	}
+	repoRoot, err := inventory.FindRepoRoot(r.Directory)
+	if err != nil || repoRoot == "" {
+		return ""
+	}
+	dir, ok := assessments.GetRepoPath(repoRoot, r.Directory, ".")
+	if !ok {
+		return ""
+	}
+	return dir
+}
+
+// Returns an array of assessments in the same order as the results.  Each
+// assessment is marked with the tool and directory it came from, and whether
+// it was uploaded.
 func (results Results) getAssessmentsJNode() (*jnode.Node, error) {
 	assmts := jnode.NewArrayNode()
 	for _, result := range results {
+		var a *jnode.Node
 		if result.AssessmentRaw != nil {
-			assmts.Append(result.AssessmentRaw)
+			// copy the assessment so the markers don't end up in it
+			a = jnode.NewObjectNode()
+			for k, v := range result.AssessmentRaw.Entries() {
+				a.Put(k, v)
+			}
 		} else {
 			// If we didn't upload we're going to fake it
-			a := &assessments.Assessment{
+			d, err := json.Marshal(&assessments.Assessment{
 				Findings: result.Findings,
-			}
-			d, err := json.Marshal(a)
+			})
 			if err != nil {
 				return nil, err
 			}
-			n, err := jnode.FromJSON(d)
+			a, err = jnode.FromJSON(d)
 			if err != nil {
 				return nil, err
 			}
-			assmts.Append(n)
 		}
+		a.Put("tool", result.Values["TOOL_NAME"]).
+			Put("directory", result.getRepoDirectory()).
+			Put("uploaded", result.AssessmentRaw != nil)
+		assmts.Append(a)
 	}
 	return assmts, nil
 }
@@ -15,6 +15,8 @@
 package tools
 
 import (
+	"encoding/json"
+	"io"
 	"io/ioutil"
 	"mime/multipart"
 	"net/http"
@@ -24,6 +26,7 @@ import (
 
 	"github.com/jarcoal/httpmock"
 	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/stretchr/testify/assert"
 )
@@ -49,10 +52,11 @@ func TestUpload(t *testing.T) {
 	createFile(tempdir, "README", "hello world\n")
 	createFile(tempdir, "empty.txt", "")
 	result := &Result{
-		Data:      jnode.NewObjectNode(),
+		Data:      jnode.NewObjectNode().Put("greeting", "hello"),
 		Directory: tempdir,
 		Files:     util.NewStringSetWithValues([]string{"README", "empty.txt"}),
 	}
+	var uploaded []byte
 	result.AddValue("FOO", "hello")
 	opts := &ToolOpts{}
 	opts.APIServer = "https://api.example.com"
@@ -68,13 +72,18 @@ func TestUpload(t *testing.T) {
 			assert.Nil(h.ParseMultipartForm(1 << 20))
 			checkFile(assert, h, "CODEOWNERS", nil)
 			checkFile(assert, h, "config.yml", nil)
+			if f, _, err := h.FormFile("results_json"); assert.NoError(err) {
+				uploaded, _ = io.ReadAll(f)
+			}
 			_, _, e := h.FormFile("empty.txt")
 			assert.NotNil(e)
 			assert.Equal(h.FormValue("FOO"), "hello")
 			return resp, err
 		})
-	assert.Nil(result.Upload(opts.GetAPIClient(), "", "test"))
+	assert.Nil(result.Upload(opts.GetAPIClient(), "", "test", 0))
 	assert.Equal("http://app.example.com/A1", result.Assessment.URL)
+	// --save-results saves exactly what's uploaded
+	assert.Equal(string(uploaded), string(result.uploadedResultsJSON))
 }
 
 func checkFile(assert *assert.Assertions, h *http.Request, name string, fn func(*assert.Assertions, multipart.File)) {
@@ -95,4 +104,174 @@ func TestIsMultiDocument(t *testing.T) {
 	assert.True(r.isMultiDocument("testdata/multi_document.yaml"))
 	assert.True(r.isMultiDocument("testdata/multi_document2.yaml"))
 	assert.False(r.isMultiDocument("testdata/single_document.yaml"))
+	assert.False(r.isMultiDocument("testdata/anchors_document.yaml"))
+	assert.False(r.isMultiDocument("testdata/empty_documents.yaml"))
+	// not valid YAML, so look for separators
+	assert.True(r.isMultiDocument("testdata/template_document.yaml"))
+}
+
+func TestTruncateFindings(t *testing.T) {
+	assert := assert.New(t)
+	r := &Result{
+		Data: jnode.NewObjectNode(),
+		Findings: assessments.Findings{
+			{FilePath: "a", Tool: map[string]string{"severity": "low"}},
+			{FilePath: "b", Tool: map[string]string{"severity": "high"}},
+			{FilePath: "c", Tool: map[string]string{"severity": "medium"}},
+		},
+	}
+	r.TruncateFindings(5)
+	assert.Equal(3, len(r.Findings))
+	assert.Empty(r.Values["FINDINGS_TRUNCATED"])
+	r.TruncateFindings(2)
+	if assert.Equal(2, len(r.Findings)) {
+		assert.Equal("b", r.Findings[0].FilePath)
+		assert.Equal("c", r.Findings[1].FilePath)
+	}
+	assert.Equal("3", r.Values["FINDINGS_TRUNCATED"])
+	assert.Equal(3, r.Data.Path("soluble_findings_truncated").Path("count").AsInt())
+}
+
+func TestGetAssessmentsJNode(t *testing.T) {
+	assert := assert.New(t)
+	uploaded := &Result{
+		Directory:     "/src/repo/infra",
+		AssessmentRaw: jnode.NewObjectNode().Put("appUrl", "http://app.example.com/A1"),
+	}
+	uploaded.AddValue("TOOL_NAME", "checkov").AddValue("ASSESSMENT_DIRECTORY", "infra")
+	repo := t.TempDir()
+	assert.NoError(os.MkdirAll(filepath.Join(repo, ".git"), 0700))
+	assert.NoError(os.WriteFile(filepath.Join(repo, ".git", "config"), nil, 0600))
+	assert.NoError(os.MkdirAll(filepath.Join(repo, "docker"), 0700))
+	local := &Result{
+		Directory: filepath.Join(repo, "docker"),
+		Findings:  assessments.Findings{{FilePath: "Dockerfile"}},
+	}
+	local.AddValue("TOOL_NAME", "hadolint")
+	outside := &Result{Directory: t.TempDir()}
+	n, err := Results{uploaded, local, outside}.getAssessmentsJNode()
+	assert.NoError(err)
+	assert.True(uploaded.AssessmentRaw.Path("tool").IsMissing())
+	if assert.Equal(3, n.Size()) {
+		a := n.Get(0)
+		assert.Equal("checkov", a.Path("tool").AsText())
+		assert.Equal("infra", a.Path("directory").AsText())
+		assert.True(a.Path("uploaded").AsBool())
+		assert.Equal("http://app.example.com/A1", a.Path("appUrl").AsText())
+		a = n.Get(1)
+		assert.Equal("hadolint", a.Path("tool").AsText())
+		assert.Equal("docker", a.Path("directory").AsText())
+		assert.False(a.Path("uploaded").AsBool())
+		assert.Equal("Dockerfile", a.Path("findings").Get(0).Path("filePath").AsText())
+		assert.Equal("", n.Get(2).Path("directory").AsText())
+	}
+}
+
+func TestStableUpload(t *testing.T) {
+	assert := assert.New(t)
+	newResult := func(reverse bool) *Result {
+		findings := assessments.Findings{
+			{FilePath: "testdata/single_document.yaml", Line: 2, SID: "b"},
+			{FilePath: "testdata/single_document.yaml", Line: 2, SID: "a"},
+			{FilePath: "testdata/multi_document.yaml", Line: 3, Title: "y"},
+			{FilePath: "testdata/multi_document.yaml", Line: 1, Title: "x"},
+			{FilePath: "testdata/anchors_document.yaml", Line: 1, Tool: map[string]string{"rule_id": "r"}},
+		}
+		if reverse {
+			for i, j := 0, len(findings)-1; i < j; i, j = i+1, j-1 {
+				findings[i], findings[j] = findings[j], findings[i]
+			}
+		}
+		return &Result{Directory: ".", Findings: findings}
+	}
+	var findings, fingerprints []byte
+	for i := 0; i < 5; i++ {
+		r := newResult(i%2 == 1)
+		r.UpdateFileFingerprints()
+		fd, err := io.ReadAll(r.attachFindings())
+		assert.NoError(err)
+		fp, err := io.ReadAll(r.attachFingerprints())
+		assert.NoError(err)
+		if i == 0 {
+			findings, fingerprints = fd, fp
+		} else {
+			assert.Equal(string(findings), string(fd))
+			assert.Equal(string(fingerprints), string(fp))
+		}
+	}
+	var sorted assessments.Findings
+	assert.NoError(json.Unmarshal(findings, &sorted))
+	assert.Equal("testdata/anchors_document.yaml", sorted[0].FilePath)
+	assert.Equal("a", sorted[3].SID)
+	// the findings themselves are left in their original order
+	r := newResult(false)
+	r.attachFindings()
+	assert.Equal("b", r.Findings[0].SID)
+}
+
+func TestUploadSBOM(t *testing.T) {
+	assert := assert.New(t)
+	opts := &ToolOpts{}
+	opts.APIServer = "https://api.example.com"
+	opts.APIToken = "xxx"
+	opts.Organization = "9999"
+	httpmock.ActivateNonDefault(opts.GetAPIClient().GetClient().GetClient())
+	defer httpmock.DeactivateAndReset()
+	var sbom []byte
+	httpmock.RegisterResponder("POST", "https://api.example.com/api/v1/xcp/sbom/data",
+		func(h *http.Request) (*http.Response, error) {
+			assert.Nil(h.ParseMultipartForm(1 << 20))
+			if f, _, err := h.FormFile("sbom_json"); assert.NoError(err) {
+				sbom, _ = io.ReadAll(f)
+			}
+			return httpmock.NewJsonResponse(http.StatusOK, jnode.NewObjectNode())
+		})
+	result := &Result{Data: jnode.NewObjectNode()}
+	result.AddArtifact("sbom.json", []byte(`{"bomFormat":"CycloneDX"}`))
+	assert.NoError(result.Upload(opts.GetAPIClient(), "", "sbom", 0))
+	assert.Equal(`{"bomFormat":"CycloneDX"}`, string(sbom))
+}
+
+func TestSaveResults(t *testing.T) {
+	assert := assert.New(t)
+	tool := &testTool{}
+	tool.Tool = tool
+	tool.APIServer = "https://api.example.com"
+	tool.APIToken = "xxx"
+	tool.Organization = "9999"
+	tool.UploadEnabled = true
+	// upload in chunks, which adds to the results
+	tool.UploadSizeLimit = 10
+	dir := t.TempDir()
+	tool.SaveResults = filepath.Join(dir, "results.json")
+	savedResults.paths = map[string]bool{}
+	httpmock.ActivateNonDefault(tool.GetAPIClient().GetClient().GetClient())
+	defer httpmock.DeactivateAndReset()
+	var uploaded []string
+	httpmock.RegisterResponder("POST", "https://api.example.com/api/v1/xcp/test/data",
+		func(h *http.Request) (*http.Response, error) {
+			assert.NoError(h.ParseMultipartForm(1 << 20))
+			if f, _, err := h.FormFile("results_json"); err == nil {
+				d, _ := io.ReadAll(f)
+				uploaded = append(uploaded, string(d))
+			}
+			return httpmock.NewJsonResponse(http.StatusOK, jnode.NewObjectNode())
+		})
+	for _, dir := range []string{"infra", "app"} {
+		result := &Result{
+			Data:     jnode.NewObjectNode().Put("dir", dir),
+			Findings: assessments.Findings{{FilePath: "main.tf", Title: "a finding"}},
+		}
+		result.AddValue("ASSESSMENT_DIRECTORY", dir)
+		assert.NoError(tool.processResult(result))
+	}
+	if assert.Len(uploaded, 2) {
+		assert.Contains(uploaded[0], "soluble_upload_chunks")
+		for i, name := range []string{"results.json", "results-test-app.json"} {
+			d, err := os.ReadFile(filepath.Join(dir, name))
+			if assert.NoError(err) {
+				assert.Equal(uploaded[i], string(d))
+			}
+		}
+	}
 }
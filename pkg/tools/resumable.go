@@ -0,0 +1,191 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/api"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/xcp"
+)
+
+// Large uploads are sent in parts in an upload session, so that when
+// the upload fails part way through only the parts that the server
+// hasn't acknowledged are sent again:
+//
+//  1. POST /api/v1/xcp/<module>/uploads with the size and sha256 of each
+//     file starts a session, and returns its uploadId and partSize
+//  2. PUT /api/v1/xcp/<module>/uploads/<id>/<file>/<part> uploads a part
+//  3. GET /api/v1/xcp/<module>/uploads/<id> returns the number of parts
+//     of each file the server has, to resume from
+//  4. The usual xcp post with UPLOAD_SESSION=<id> and no files completes
+//     the upload
+//
+// If the server doesn't support sessions (i.e. starting one returns 404,
+// 405 or 501) the upload is sent all at once, and sessions aren't tried
+// again for the rest of the run.  Other errors fail the upload.
+const (
+	resumableUploadThreshold = 4 << 20
+	defaultUploadPartSize    = 1 << 20
+	uploadPartAttempts       = 3
+)
+
+var errUploadSessionsNotSupported = errors.New("upload sessions are not supported")
+
+// set once the server has said that it doesn't support sessions
+var uploadSessionsNotSupported int32
+
+type uploadSession struct {
+	client   *api.Client
+	id       string
+	path     string
+	partSize int
+	options  []api.Option
+}
+
+func getUploadFilesSize(files []*uploadFile) int {
+	size := 0
+	for _, f := range files {
+		size += len(f.data)
+	}
+	return size
+}
+
+func (r *Result) uploadResumable(client *api.Client, org, name string, files []*uploadFile, options []api.Option) (*jnode.Node, error) {
+	options = append(options, func(req *resty.Request) {
+		req.SetHeader("X-SOLUBLE-ORG-ID", org)
+	})
+	s, err := startUploadSession(client, name, files, options)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Uploading {info:%d} bytes in parts of {info:%d} bytes as session {info:%s}",
+		getUploadFilesSize(files), s.partSize, s.id)
+	if err := s.uploadFiles(files); err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	for k, v := range r.Values {
+		values[k] = v
+	}
+	values["UPLOAD_SESSION"] = s.id
+	return client.XCPPost(org, name, nil, values, append(options, xcp.WithCIEnvValues(r.getCIEnv()))...)
+}
+
+func startUploadSession(client *api.Client, module string, files []*uploadFile, options []api.Option) (*uploadSession, error) {
+	if atomic.LoadInt32(&uploadSessionsNotSupported) != 0 {
+		return nil, errUploadSessionsNotSupported
+	}
+	body := jnode.NewObjectNode()
+	fs := body.PutArray("files")
+	for _, f := range files {
+		sum := sha256.Sum256(f.data)
+		fs.AppendObject().
+			Put("name", f.param).
+			Put("filename", f.filename).
+			Put("size", len(f.data)).
+			Put("sha256", hex.EncodeToString(sum[:]))
+	}
+	path := fmt.Sprintf("/api/v1/xcp/%s/uploads", module)
+	// older servers don't know about sessions, which isn't worth
+	// complaining about
+	temp := log.SetTempLevel(log.Error - 1)
+	n, err := client.Post(path, body, options...)
+	temp.Restore()
+	if err != nil {
+		switch api.GetStatusCode(err) {
+		case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+			atomic.StoreInt32(&uploadSessionsNotSupported, 1)
+			return nil, fmt.Errorf("%w: %s", errUploadSessionsNotSupported, err)
+		}
+		return nil, err
+	}
+	id := n.Path("uploadId").AsText()
+	if id == "" {
+		atomic.StoreInt32(&uploadSessionsNotSupported, 1)
+		return nil, errUploadSessionsNotSupported
+	}
+	s := &uploadSession{
+		client:   client,
+		id:       id,
+		path:     fmt.Sprintf("%s/%s", path, id),
+		partSize: n.Path("partSize").AsInt(),
+		options:  options,
+	}
+	if s.partSize <= 0 {
+		s.partSize = defaultUploadPartSize
+	}
+	return s, nil
+}
+
+func (s *uploadSession) uploadFiles(files []*uploadFile) error {
+	received := map[string]int{}
+	for attempt := 1; ; attempt++ {
+		err := s.uploadParts(files, received)
+		if err == nil {
+			return nil
+		}
+		if attempt == uploadPartAttempts {
+			return err
+		}
+		log.Warnf("Upload session {info:%s} failed, resuming - {warning:%s}", s.id, err)
+		if received, err = s.getReceivedParts(files); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *uploadSession) uploadParts(files []*uploadFile, received map[string]int) error {
+	options := append([]api.Option{}, s.options...)
+	options = append(options, func(req *resty.Request) {
+		req.SetHeader("Content-Type", "application/octet-stream")
+	})
+	for _, f := range files {
+		parts := (len(f.data) + s.partSize - 1) / s.partSize
+		for part := received[f.param]; part < parts; part++ {
+			start := part * s.partSize
+			end := start + s.partSize
+			if end > len(f.data) {
+				end = len(f.data)
+			}
+			path := fmt.Sprintf("%s/%s/%d", s.path, f.param, part)
+			if _, err := s.client.Put(path, f.data[start:end], options...); err != nil {
+				return err
+			}
+			received[f.param] = part + 1
+		}
+	}
+	return nil
+}
+
+func (s *uploadSession) getReceivedParts(files []*uploadFile) (map[string]int, error) {
+	n, err := s.client.Get(s.path, s.options...)
+	if err != nil {
+		return nil, err
+	}
+	received := map[string]int{}
+	for _, f := range files {
+		received[f.param] = n.Path("files").Path(f.param).Path("parts").AsInt()
+	}
+	return received, nil
+}
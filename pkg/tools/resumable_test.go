@@ -0,0 +1,162 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func newUploadTestOpts() *ToolOpts {
+	opts := &ToolOpts{}
+	opts.APIServer = "https://api.example.com"
+	opts.APIToken = "xxx"
+	opts.Organization = "9999"
+	return opts
+}
+
+func newLargeResult(t *testing.T) *Result {
+	uploadSessionsNotSupported = 0
+	t.Cleanup(func() { uploadSessionsNotSupported = 0 })
+	data := jnode.NewObjectNode().Put("padding", strings.Repeat("x", resumableUploadThreshold))
+	return &Result{Data: data, Directory: t.TempDir()}
+}
+
+func TestUploadResumable(t *testing.T) {
+	assert := assert.New(t)
+	opts := newUploadTestOpts()
+	httpmock.ActivateNonDefault(opts.GetAPIClient().GetClient().GetClient())
+	defer httpmock.DeactivateAndReset()
+	const partSize = 1 << 20
+	var (
+		parts    = map[string][]byte{}
+		puts     int
+		failed   bool
+		statuses int
+		session  string
+		files    *http.Request
+	)
+	httpmock.RegisterResponder("POST", "https://api.example.com/api/v1/xcp/test/uploads",
+		func(h *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(h.Body)
+			n, _ := jnode.FromJSON(body)
+			assert.Equal("results_json", n.Path("files").Get(0).Path("name").AsText())
+			return httpmock.NewJsonResponse(http.StatusOK, jnode.NewObjectNode().
+				Put("uploadId", "u1").Put("partSize", partSize))
+		})
+	httpmock.RegisterResponder("PUT", `=~^https://api.example.com/api/v1/xcp/test/uploads/u1/results_json/\d+`,
+		func(h *http.Request) (*http.Response, error) {
+			puts++
+			part := h.URL.Path[strings.LastIndex(h.URL.Path, "/")+1:]
+			if part == "2" && !failed {
+				failed = true
+				return httpmock.NewStringResponse(http.StatusBadGateway, "oops"), nil
+			}
+			parts[part], _ = io.ReadAll(h.Body)
+			return httpmock.NewJsonResponse(http.StatusOK, jnode.NewObjectNode())
+		})
+	httpmock.RegisterResponder("GET", "https://api.example.com/api/v1/xcp/test/uploads/u1",
+		func(h *http.Request) (*http.Response, error) {
+			statuses++
+			n := jnode.NewObjectNode()
+			n.PutObject("files").PutObject("results_json").Put("parts", len(parts))
+			return httpmock.NewJsonResponse(http.StatusOK, n)
+		})
+	httpmock.RegisterResponder("POST", "https://api.example.com/api/v1/xcp/test/data",
+		func(h *http.Request) (*http.Response, error) {
+			assert.NoError(h.ParseMultipartForm(1 << 20))
+			session = h.FormValue("UPLOAD_SESSION")
+			files = h
+			n := jnode.NewObjectNode()
+			n.PutObject("assessment").Put("appUrl", "http://app.example.com/A1")
+			return httpmock.NewJsonResponse(http.StatusOK, n)
+		})
+	result := newLargeResult(t)
+	assert.NoError(result.Upload(opts.GetAPIClient(), "", "test", 0))
+	assert.Equal("u1", session)
+	assert.Equal(1, statuses)
+	// each part is sent once, apart from the one that failed
+	count := (len(result.getResultsJSON()) + partSize - 1) / partSize
+	assert.Equal(count+1, puts)
+	var uploaded []byte
+	for i := 0; i < count; i++ {
+		uploaded = append(uploaded, parts[fmt.Sprint(i)]...)
+	}
+	assert.Equal(string(result.getResultsJSON()), string(uploaded))
+	if assert.NotNil(files) {
+		assert.Empty(files.MultipartForm.File)
+	}
+	assert.NotNil(result.Assessment)
+}
+
+func TestUploadResumableNotSupported(t *testing.T) {
+	assert := assert.New(t)
+	opts := newUploadTestOpts()
+	httpmock.ActivateNonDefault(opts.GetAPIClient().GetClient().GetClient())
+	defer httpmock.DeactivateAndReset()
+	sessions := 0
+	httpmock.RegisterResponder("POST", "https://api.example.com/api/v1/xcp/test/uploads",
+		func(h *http.Request) (*http.Response, error) {
+			sessions++
+			return httpmock.NewStringResponse(http.StatusNotFound, "not found"), nil
+		})
+	var results int
+	httpmock.RegisterResponder("POST", "https://api.example.com/api/v1/xcp/test/data",
+		func(h *http.Request) (*http.Response, error) {
+			assert.NoError(h.ParseMultipartForm(1 << 20))
+			assert.Empty(h.FormValue("UPLOAD_SESSION"))
+			if f, _, err := h.FormFile("results_json"); assert.NoError(err) {
+				d, _ := io.ReadAll(f)
+				results = len(d)
+			}
+			return httpmock.NewJsonResponse(http.StatusOK, jnode.NewObjectNode())
+		})
+	result := newLargeResult(t)
+	assert.NoError(result.Upload(opts.GetAPIClient(), "", "test", 0))
+	assert.Equal(len(result.getResultsJSON()), results)
+	// sessions aren't tried again
+	results = 0
+	assert.NoError(result.Upload(opts.GetAPIClient(), "", "test", 0))
+	assert.Equal(len(result.getResultsJSON()), results)
+	assert.Equal(1, sessions)
+}
+
+func TestUploadResumableError(t *testing.T) {
+	assert := assert.New(t)
+	opts := newUploadTestOpts()
+	httpmock.ActivateNonDefault(opts.GetAPIClient().GetClient().GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", "https://api.example.com/api/v1/xcp/test/uploads",
+		httpmock.NewStringResponder(http.StatusUnauthorized, "unauthorized"))
+	results := 0
+	httpmock.RegisterResponder("POST", "https://api.example.com/api/v1/xcp/test/data",
+		func(h *http.Request) (*http.Response, error) {
+			results++
+			return httpmock.NewJsonResponse(http.StatusOK, jnode.NewObjectNode())
+		})
+	result := newLargeResult(t)
+	err := result.Upload(opts.GetAPIClient(), "", "test", 0)
+	// an auth failure isn't mistaken for sessions not being supported
+	assert.Equal(http.StatusUnauthorized, api.GetStatusCode(err))
+	assert.Zero(results)
+}
@@ -22,6 +22,14 @@ var _ tools.Single = (*Tool)(nil)
 
 func (t *Tool) Name() string { return "retirejs" }
 
+func (t *Tool) Preflight() error {
+	return t.PreflightDocker()
+}
+
+func (t *Tool) Requirements() []string {
+	return []string{tools.RequiresDocker}
+}
+
 func (t *Tool) Run() (*tools.Result, error) {
 	args := []string{
 		"retire", "--exitwith", "0", "--outputformat", "json", "--path", ".",
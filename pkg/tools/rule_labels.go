@@ -0,0 +1,94 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"path"
+	"sort"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+type ruleLabels struct {
+	pattern string
+	labels  map[string]string
+}
+
+// Returns the labels for a tool's rules from the config.  Rule ids are
+// matched with glob patterns, and the labels of every matching pattern
+// are applied.  The config looks like:
+//
+//	rule_labels:
+//	  terrascan:
+//	    "AWS.S3.*":
+//	      team: platform
+func (c *Config) getRuleLabels(toolName string) []*ruleLabels {
+	n := c.data.Path("rule_labels").Path(toolName)
+	if !n.IsObject() {
+		return nil
+	}
+	var result []*ruleLabels
+	for pattern, v := range n.Entries() {
+		if _, err := path.Match(pattern, ""); err != nil {
+			log.Warnf("Ignoring invalid rule pattern {warning:%s} for {info:%s} in {secondary:%s}",
+				pattern, toolName, c.path)
+			continue
+		}
+		if !v.IsObject() {
+			log.Warnf("Ignoring rule labels for {info:%s} pattern {warning:%s} in {secondary:%s} that are not a map",
+				toolName, pattern, c.path)
+			continue
+		}
+		rl := &ruleLabels{pattern: pattern, labels: map[string]string{}}
+		for k, lv := range v.Entries() {
+			rl.labels[k] = lv.AsText()
+		}
+		result = append(result, rl)
+	}
+	// apply in a consistent order so that overlapping patterns always
+	// resolve the same way
+	sort.Slice(result, func(i, j int) bool { return result[i].pattern < result[j].pattern })
+	return result
+}
+
+// Add the labels of matching rules to the findings.
+func applyRuleLabels(labels []*ruleLabels, findings assessments.Findings) {
+	for _, f := range findings {
+		id := f.GetRuleID()
+		if id == "" {
+			continue
+		}
+		for _, rl := range labels {
+			if ok, _ := path.Match(rl.pattern, id); !ok {
+				continue
+			}
+			if f.Labels == nil {
+				f.Labels = map[string]string{}
+			}
+			for k, v := range rl.labels {
+				f.Labels[k] = v
+			}
+		}
+	}
+}
+
+func (o *ToolOpts) applyRuleLabels(result *Result) {
+	labels := o.GetConfig().getRuleLabels(o.Tool.Name())
+	if len(labels) == 0 {
+		return
+	}
+	applyRuleLabels(labels, result.Findings)
+}
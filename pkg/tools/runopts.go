@@ -15,17 +15,24 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/soluble-ai/go-jnode"
 	"github.com/soluble-ai/soluble-cli/pkg/download"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/options"
+	"github.com/soluble-ai/soluble-cli/pkg/tracing"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type RunOpts struct {
@@ -35,7 +42,14 @@ type RunOpts struct {
 	SkipDockerPull  bool
 	ExtraDockerArgs []string
 	NoDocker        bool
+	DockerPlatform  string
+	ScanIdleTimeout time.Duration
+	UseSystemTool   bool
 	Internal        bool
+
+	toolVersions map[string]*jnode.Node
+	// The trace context of the tool's run
+	traceCtx context.Context
 }
 
 var _ options.Interface = &RunOpts{}
@@ -50,6 +64,9 @@ func (o *RunOpts) GetRunHiddenOptions() *options.HiddenOptionsGroup {
 			flags.StringVar(&o.ToolPath, "tool-path", "", "Run `tool` directly instead of using a CLI-managed version")
 			flags.StringVar(&o.ToolVersion, "tool-version", "", "Override version of the tool to run (the image or github release name.)")
 			flags.BoolVar(&o.NoDocker, "no-docker", false, "Always run tools locally instead of using Docker")
+			flags.StringVar(&o.DockerPlatform, "docker-platform", "", "Run docker images for `platform` e.g. linux/amd64")
+			flags.BoolVar(&o.UseSystemTool, "use-system-tool", false, "Run the tool found on $PATH instead of installing a CLI-managed version")
+			flags.DurationVar(&o.ScanIdleTimeout, "scan-timeout-per-file", 0, "Kill docker-based tools that produce no output for this `duration`, e.g. 5m")
 		},
 	}
 }
@@ -62,6 +79,7 @@ func (o *RunOpts) Register(cmd *cobra.Command) {
 }
 
 func (o *RunOpts) RunDocker(d *DockerTool) ([]byte, error) {
+	defer AcquireWorker()()
 	if o.ToolPath != "" || o.NoDocker {
 		path := o.ToolPath
 		if path == "" {
@@ -78,14 +96,24 @@ func (o *RunOpts) RunDocker(d *DockerTool) ([]byte, error) {
 		c := exec.Command(path, d.Args...)
 		c.Dir = d.Directory
 		c.Stderr = os.Stderr
+		if d.Stderr != nil {
+			c.Stderr = d.Stderr
+		}
 		o.LogCommand(c)
-		return c.Output()
+		span := o.StartCommandSpan(c)
+		out, err := c.Output()
+		tracing.EndSpan(span, err)
+		return out, err
+	}
+	d.Image = o.ResolveDockerImage(d.Name, d.Image)
+	d.extraDockerArgs = o.ExtraDockerArgs
+	if o.DockerPlatform != "" {
+		d.Platform = o.DockerPlatform
 	}
-	n := o.getToolVersion(d.Name)
-	if image := n.Path("image"); !image.IsMissing() {
-		d.Image = image.AsText()
+	if o.ScanIdleTimeout > 0 {
+		d.IdleTimeout = o.ScanIdleTimeout
 	}
-	d.DockerArgs = append(d.DockerArgs, o.ExtraDockerArgs...)
+	d.traceCtx = o.traceCtx
 	return d.run(o.SkipDockerPull)
 }
 
@@ -95,15 +123,100 @@ func (o *RunOpts) InstallTool(spec *download.Spec) (*download.Download, error) {
 			OverrideExe: o.ToolPath,
 		}, nil
 	}
+	name := getSpecToolName(spec)
+	if o.UseSystemTool {
+		d := findSystemTool(name)
+		if d == nil {
+			return nil, fmt.Errorf("%s is not on $PATH", name)
+		}
+		return d, nil
+	}
 	if strings.HasPrefix(spec.URL, "github.com/") {
-		slash := strings.LastIndex(spec.URL, "/")
-		n := o.getToolVersion(spec.URL[slash+1:])
+		n := o.getToolVersion(name)
 		if v := n.Path("version"); !v.IsMissing() {
 			spec.RequestedVersion = v.AsText()
 		}
 	}
+	_, span := tracing.StartSpan(o.traceCtx, "install", attribute.String("install.url", spec.URL))
 	m := download.NewManager()
-	return m.Install(spec)
+	d, err := m.Install(spec)
+	tracing.EndSpan(span, err)
+	if err != nil && download.IsUnavailable(err) {
+		// e.g. github is unreachable, but the tool may be installed already.
+		// Other errors (e.g. a checksum mismatch) could mean the download
+		// has been tampered with, so they're never ignored.
+		if sd := findSystemTool(name); sd != nil {
+			log.Warnf("Could not install {warning:%s} - {warning:%s}", name, err)
+			return sd, nil
+		}
+	}
+	return d, err
+}
+
+// Returns the name of the program that spec installs.
+func getSpecToolName(spec *download.Spec) string {
+	if strings.HasPrefix(spec.URL, "github.com/") {
+		return spec.URL[strings.LastIndex(spec.URL, "/")+1:]
+	}
+	return spec.Name
+}
+
+// Look for a tool on $PATH, returning nil if it's not there.
+func findSystemTool(name string) *download.Download {
+	if name == "" {
+		return nil
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return nil
+	}
+	version := getSystemToolVersion(path)
+	log.Infof("Using {primary:%s} {info:%s} from $PATH", path, version)
+	return &download.Download{
+		Name:        name,
+		Version:     version,
+		OverrideExe: path,
+	}
+}
+
+var versionRegexp = regexp.MustCompile(`v?[0-9]+\.[0-9]+(\.[0-9]+)?[^\s,]*`)
+
+// Returns the version that "tool --version" or "tool version" prints.
+// If the output doesn't look like a version number its first line is
+// returned.
+func getSystemToolVersion(path string) string {
+	for _, arg := range []string{"--version", "version"} {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		// #nosec G204
+		out, err := exec.CommandContext(ctx, path, arg).Output()
+		cancel()
+		if err != nil {
+			continue
+		}
+		line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(string(out)), "\n", 2)[0])
+		if v := versionRegexp.FindString(line); v != "" {
+			return v
+		}
+		if line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// Check that docker is available, unless the tool is going to be run locally.
+func (o *RunOpts) PreflightDocker() error {
+	if o.ToolPath != "" || o.NoDocker {
+		return nil
+	}
+	return hasDocker()
+}
+
+// Check that a tool can be installed.  This will install the tool if it
+// hasn't been already.
+func (o *RunOpts) PreflightInstall(spec *download.Spec) error {
+	_, err := o.InstallTool(spec)
+	return err
 }
 
 func (o *RunOpts) getToolVersion(name string) *jnode.Node {
@@ -112,19 +225,55 @@ func (o *RunOpts) getToolVersion(name string) *jnode.Node {
 			Put("image", o.ToolVersion).
 			Put("version", o.ToolVersion)
 	}
+	if n, ok := o.toolVersions[name]; ok {
+		return n
+	}
 	temp := log.SetTempLevel(log.Error - 1)
 	defer temp.Restore()
 	n, err := o.GetUnauthenticatedAPIClient().Get(fmt.Sprintf("cli/tools/%s/config", name))
 	if err != nil {
-		return jnode.MissingNode
+		n = jnode.MissingNode
+	}
+	if o.toolVersions == nil {
+		o.toolVersions = map[string]*jnode.Node{}
 	}
+	o.toolVersions[name] = n
 	return n
 }
 
+// Returns the image that RunDocker runs for the docker tool name, which
+// is image unless it's overridden, or "" if docker isn't used.
+func (o *RunOpts) ResolveDockerImage(name, image string) string {
+	if o.ToolPath != "" || o.NoDocker {
+		return ""
+	}
+	if n := o.getToolVersion(name).Path("image"); !n.IsMissing() {
+		return n.AsText()
+	}
+	return image
+}
+
 func (o *RunOpts) LogCommand(c *exec.Cmd) {
+	command := formatCommand(filepath.Base(c.Path), c.Args)
 	if c.Dir != "" {
-		log.Infof("Running {primary:%s} {secondary:(in %s)}", strings.Join(c.Args, " "), c.Dir)
+		log.Infof("Running {primary:%s} {secondary:(in %s)}", command, c.Dir)
 		return
 	}
-	log.Infof("Running {primary:%s}", strings.Join(c.Args, " "))
+	log.Infof("Running {primary:%s}", command)
+}
+
+// Start a span for running c, a tool's local program.  The caller ends
+// it with tracing.EndSpan once c has run.
+func (o *RunOpts) StartCommandSpan(c *exec.Cmd) trace.Span {
+	_, span := tracing.StartSpan(o.traceCtx, "exec", attribute.String("exec.program", filepath.Base(c.Path)))
+	return span
+}
+
+// The full command line is only logged with --verbose, otherwise just
+// the name of what's being run is.
+func formatCommand(name string, args []string) string {
+	if log.Level >= log.Debug {
+		return strings.Join(args, " ")
+	}
+	return name
 }
@@ -0,0 +1,46 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/download"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUseSystemTool(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a shell script")
+	}
+	assert := assert.New(t)
+	assert.Equal("terrascan", getSpecToolName(&download.Spec{URL: "github.com/accurics/terrascan"}))
+	assert.Equal("tfscore", getSpecToolName(&download.Spec{Name: "tfscore"}))
+	dir := t.TempDir()
+	assert.NoError(os.WriteFile(filepath.Join(dir, "faketool"),
+		[]byte("#!/bin/sh\necho \"version: v1.15.2, built today\"\n"), 0700))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	o := &RunOpts{UseSystemTool: true}
+	d, err := o.InstallTool(&download.Spec{URL: "github.com/example/faketool"})
+	if assert.NoError(err) {
+		assert.Equal(filepath.Join(dir, "faketool"), d.GetExePath("faketool"))
+		assert.Equal("v1.15.2", d.Version)
+	}
+	_, err = o.InstallTool(&download.Spec{URL: "github.com/example/missingtool"})
+	assert.Error(err)
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/download"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/tracing"
+	"github.com/spf13/cobra"
+)
+
+var formats = map[string]string{
+	"cyclonedx": "cyclonedx-json",
+	"spdx":      "spdx-json",
+}
+
+type Tool struct {
+	tools.DirectoryBasedToolOpts
+	Image      string
+	Format     string
+	OutputSBOM string
+}
+
+var _ tools.Single = &Tool{}
+
+func (*Tool) Name() string {
+	return "sbom"
+}
+
+func (*Tool) IsNonAssessment() bool {
+	return true
+}
+
+func (t *Tool) Preflight() error {
+	return t.PreflightInstall(getSyftSpec())
+}
+
+func (t *Tool) Requirements() []string {
+	return []string{tools.RequiresDownload}
+}
+
+func (t *Tool) Register(cmd *cobra.Command) {
+	t.DirectoryBasedToolOpts.Register(cmd)
+	flags := cmd.Flags()
+	flags.StringVar(&t.Image, "image", "", "Generate the SBOM of this container `image` instead of the directory")
+	flags.StringVar(&t.Format, "sbom-format", "cyclonedx", "The SBOM `format`, one of cyclonedx or spdx")
+	flags.StringVar(&t.OutputSBOM, "output-sbom", "", "Also write the SBOM to `file`")
+	t.Path = []string{"components"}
+	t.Columns = []string{"name", "version", "type", "purl"}
+}
+
+func (t *Tool) CommandTemplate() *cobra.Command {
+	return &cobra.Command{
+		Use:   "sbom",
+		Short: "Generate a software bill of materials with syft",
+		Long: `Generate a CycloneDX or SPDX software bill of materials (SBOM) for a
+directory or container image with syft.
+
+The SBOM is uploaded along with the list of components.`,
+		Example: `# Generate an SPDX SBOM of an image and save a copy locally
+... sbom --image alpine:3.16 --sbom-format spdx --output-sbom sbom.json`,
+	}
+}
+
+func (t *Tool) Validate() error {
+	if formats[t.Format] == "" {
+		return fmt.Errorf("--sbom-format must be one of cyclonedx or spdx")
+	}
+	return t.DirectoryBasedToolOpts.Validate()
+}
+
+func (t *Tool) Run() (*tools.Result, error) {
+	d, err := t.InstallTool(getSyftSpec())
+	if err != nil {
+		return nil, err
+	}
+	source := fmt.Sprintf("dir:%s", t.GetDirectory())
+	if t.Image != "" {
+		source = t.Image
+	}
+	// #nosec G204
+	c := exec.Command(d.GetExePath("syft"), source, "-q", "-o", formats[t.Format])
+	c.Stderr = os.Stderr
+	defer tools.AcquireWorker()()
+	t.LogCommand(c)
+	span := t.StartCommandSpan(c)
+	dat, err := c.Output()
+	tracing.EndSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	n, err := jnode.FromJSON(dat)
+	if err != nil {
+		return nil, err
+	}
+	if t.OutputSBOM != "" {
+		if err := os.WriteFile(t.OutputSBOM, dat, 0600); err != nil {
+			return nil, err
+		}
+	}
+	result := &tools.Result{
+		Data: jnode.NewObjectNode().
+			Put("format", t.Format).
+			Put("source", source).
+			Put("components", getComponents(t.Format, n)),
+	}
+	result.AddArtifact("sbom.json", dat).
+		AddValue("SYFT_VERSION", d.Version).
+		AddValue("SBOM_FORMAT", t.Format)
+	if t.Image != "" {
+		result.AddValue("IMAGE", t.Image)
+	}
+	return result, nil
+}
+
+func getSyftSpec() *download.Spec {
+	return &download.Spec{
+		URL: "github.com/anchore/syft",
+	}
+}
+
+func getComponents(format string, n *jnode.Node) *jnode.Node {
+	components := jnode.NewArrayNode()
+	if format == "spdx" {
+		for _, p := range n.Path("packages").Elements() {
+			c := components.AppendObject().
+				Put("name", p.Path("name").AsText()).
+				Put("version", p.Path("versionInfo").AsText())
+			for _, ref := range p.Path("externalRefs").Elements() {
+				if ref.Path("referenceType").AsText() == "purl" {
+					c.Put("purl", ref.Path("referenceLocator").AsText())
+				}
+			}
+		}
+		return components
+	}
+	for _, p := range n.Path("components").Elements() {
+		components.AppendObject().
+			Put("name", p.Path("name").AsText()).
+			Put("version", p.Path("version").AsText()).
+			Put("type", p.Path("type").AsText()).
+			Put("purl", p.Path("purl").AsText())
+	}
+	return components
+}
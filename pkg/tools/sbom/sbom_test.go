@@ -0,0 +1,43 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetComponents(t *testing.T) {
+	assert := assert.New(t)
+	n, err := util.ReadJSONFile("testdata/cyclonedx.json")
+	assert.NoError(err)
+	components := getComponents("cyclonedx", n)
+	assert.Equal(2, components.Size())
+	assert.Equal("lodash", components.Get(0).Path("name").AsText())
+	assert.Equal("pkg:golang/github.com/spf13/cobra@v1.5.0", components.Get(1).Path("purl").AsText())
+	n, err = util.ReadJSONFile("testdata/spdx.json")
+	assert.NoError(err)
+	components = getComponents("spdx", n)
+	assert.Equal(1, components.Size())
+	assert.Equal("4.17.21", components.Get(0).Path("version").AsText())
+	assert.Equal("pkg:npm/lodash@4.17.21", components.Get(0).Path("purl").AsText())
+}
+
+func TestValidate(t *testing.T) {
+	tool := &Tool{Format: "swid"}
+	assert.Error(t, tool.Validate())
+}
@@ -0,0 +1,115 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"strconv"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+// A scan score is a single number that summarizes the risk of the
+// failed findings of a scan so that it can be trended over time.  Each
+// failed finding is weighted by its severity, and the weights are
+// combined with a formula:
+//
+//   - sum (the default) adds up the weights of all the failed findings
+//   - max is the weight of the most severe failed finding
+//
+// Passed findings and findings without a recognized severity count
+// for nothing.
+type scoreModel struct {
+	weights map[string]float64
+	formula string
+}
+
+var defaultScoreWeights = map[string]float64{
+	"critical": 10,
+	"high":     5,
+	"medium":   2,
+	"low":      1,
+	"info":     0,
+}
+
+var scoreFormulas = map[string]func(total, weight float64) float64{
+	"sum": func(total, weight float64) float64 { return total + weight },
+	"max": func(total, weight float64) float64 {
+		if weight > total {
+			return weight
+		}
+		return total
+	},
+}
+
+// Returns the scan score model from the config.  Weights that aren't
+// given keep their default.  The config looks like:
+//
+//	scan_score:
+//	  formula: sum
+//	  weights:
+//	    critical: 25
+//	    info: 0.1
+func (c *Config) getScoreModel() *scoreModel {
+	m := &scoreModel{
+		weights: map[string]float64{},
+		formula: "sum",
+	}
+	for k, v := range defaultScoreWeights {
+		m.weights[k] = v
+	}
+	n := c.data.Path("scan_score")
+	if formula := n.Path("formula").AsText(); formula != "" {
+		if scoreFormulas[formula] == nil {
+			log.Warnf("Ignoring invalid scan score formula {warning:%s} in {secondary:%s}", formula, c.path)
+		} else {
+			m.formula = formula
+		}
+	}
+	for k, v := range n.Path("weights").Entries() {
+		severity := assessments.NormalizeSeverity(k)
+		weight, err := strconv.ParseFloat(v.AsText(), 64)
+		if severity == "" || err != nil || weight < 0 {
+			log.Warnf("Ignoring invalid scan score weight {warning:%s: %s} in {secondary:%s}", k, v.AsText(), c.path)
+			continue
+		}
+		m.weights[severity] = weight
+	}
+	return m
+}
+
+func (m *scoreModel) score(findings assessments.Findings) float64 {
+	combine := scoreFormulas[m.formula]
+	var total float64
+	for _, f := range findings {
+		if f.Pass {
+			continue
+		}
+		total = combine(total, m.weights[f.GetSeverity()])
+	}
+	return total
+}
+
+func (results Results) getScanScore(m *scoreModel) float64 {
+	var findings assessments.Findings
+	for _, result := range results {
+		findings = append(findings, result.Findings...)
+	}
+	return m.score(findings)
+}
+
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
@@ -24,6 +24,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const secretsImage = "gcr.io/soluble-repo/soluble-secrets:latest"
+
 type Tool struct {
 	tools.DirectoryBasedToolOpts
 
@@ -36,6 +38,18 @@ func (t *Tool) Name() string {
 	return "secrets"
 }
 
+func (t *Tool) GetDockerImage() string {
+	return t.ResolveDockerImage("soluble-secrets", secretsImage)
+}
+
+func (t *Tool) Preflight() error {
+	return t.PreflightDocker()
+}
+
+func (t *Tool) Requirements() []string {
+	return []string{tools.RequiresDocker}
+}
+
 func (t *Tool) CommandTemplate() *cobra.Command {
 	return &cobra.Command{
 		Args: func(cmd *cobra.Command, args []string) error {
@@ -65,12 +79,12 @@ func (t *Tool) Run() (*tools.Result, error) {
 	d, err := t.RunDocker(&tools.DockerTool{
 		Name:                "soluble-secrets",
 		DefaultNoDockerName: "detect-secrets",
-		Image:               "gcr.io/soluble-repo/soluble-secrets:latest",
+		Image:               secretsImage,
 		Directory:           t.GetDirectory(),
 		PolicyDirectory:     customPoliciesDir,
 		Args:                args,
 	})
-	if err != nil && tools.IsDockerError(err) {
+	if err != nil && tools.IsContainerError(err) {
 		return nil, err
 	}
 	results, err := jnode.FromJSON(d)
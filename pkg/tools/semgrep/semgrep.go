@@ -24,6 +24,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const semgrepImage = "returntocorp/semgrep:latest"
+
 type Tool struct {
 	tools.DirectoryBasedToolOpts
 	Pattern string
@@ -39,6 +41,18 @@ func (*Tool) Name() string {
 	return "semgrep"
 }
 
+func (t *Tool) GetDockerImage() string {
+	return t.ResolveDockerImage("semgrep", semgrepImage)
+}
+
+func (t *Tool) Preflight() error {
+	return t.PreflightDocker()
+}
+
+func (t *Tool) Requirements() []string {
+	return []string{tools.RequiresDocker}
+}
+
 func (t *Tool) Register(cmd *cobra.Command) {
 	t.DirectoryBasedToolOpts.Register(cmd)
 	flags := cmd.Flags()
@@ -86,12 +100,12 @@ func (t *Tool) Run() (*tools.Result, error) {
 	args = append(args, ".")
 	d, err := t.RunDocker(&tools.DockerTool{
 		Name:            "semgrep",
-		Image:           "returntocorp/semgrep:latest",
+		Image:           semgrepImage,
 		Directory:       t.GetDirectory(),
 		PolicyDirectory: customPoliciesDir,
 		Args:            args,
 	})
-	if err != nil && (tools.IsDockerError(err) || util.ExitCode(err) != 1) {
+	if err != nil && (tools.IsContainerError(err) || util.ExitCode(err) != 1) {
 		// semgrep exits 1 if it finds issues
 		return nil, err
 	}
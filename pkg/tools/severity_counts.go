@@ -0,0 +1,50 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"fmt"
+	"io"
+)
+
+var severityCountNames = []string{"critical", "high", "medium", "low"}
+
+// Write a single line with the count of failed findings by severity,
+// e.g. "critical=0 high=3 medium=5 low=2 total=10".  The total includes
+// findings of any severity.
+func (results Results) writeSeverityCounts(w io.Writer) {
+	counts, total := results.getSeverityCounts()
+	for _, name := range severityCountNames {
+		fmt.Fprintf(w, "%s=%d ", name, counts[name])
+	}
+	fmt.Fprintf(w, "total=%d\n", total)
+}
+
+// Returns the count of failed findings by severity, and the total count
+// of failed findings.
+func (results Results) getSeverityCounts() (map[string]int, int) {
+	counts := map[string]int{}
+	total := 0
+	for _, result := range results {
+		for _, f := range result.Findings {
+			if f.Pass {
+				continue
+			}
+			counts[f.GetSeverity()]++
+			total++
+		}
+	}
+	return counts, total
+}
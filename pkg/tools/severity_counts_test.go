@@ -0,0 +1,44 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteSeverityCounts(t *testing.T) {
+	assert := assert.New(t)
+	buf := &bytes.Buffer{}
+	Results{}.writeSeverityCounts(buf)
+	assert.Equal("critical=0 high=0 medium=0 low=0 total=0\n", buf.String())
+	results := Results{
+		{Findings: assessments.Findings{
+			{Severity: "High"},
+			{Tool: map[string]string{"severity": "warning"}},
+			{Severity: "high", Pass: true},
+		}},
+		{Findings: assessments.Findings{
+			{Severity: "low"},
+			{Severity: "info"},
+		}},
+	}
+	buf.Reset()
+	results.writeSeverityCounts(buf)
+	assert.Equal("critical=0 high=1 medium=1 low=1 total=4\n", buf.String())
+}
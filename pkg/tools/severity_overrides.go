@@ -0,0 +1,77 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"sort"
+
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+// Returns the severity overrides for a tool from the config, keyed by
+// rule id.  The config looks like:
+//
+//	severity_overrides:
+//	  hadolint:
+//	    DL3008: low
+func (c *Config) GetSeverityOverrides(toolName string) map[string]string {
+	n := c.data.Path("severity_overrides").Path(toolName)
+	if !n.IsObject() {
+		return nil
+	}
+	overrides := map[string]string{}
+	for k, v := range n.Entries() {
+		severity := assessments.NormalizeSeverity(v.AsText())
+		if severity == "" {
+			log.Warnf("Ignoring invalid severity {warning:%s} for {info:%s} rule {info:%s} in {secondary:%s}",
+				v.AsText(), toolName, k, c.path)
+			continue
+		}
+		overrides[k] = severity
+	}
+	return overrides
+}
+
+// Set the severity of findings whose rule has an override.  Returns the
+// rule ids of overrides that didn't match any finding.
+func applySeverityOverrides(overrides map[string]string, findings assessments.Findings) []string {
+	used := map[string]bool{}
+	for _, f := range findings {
+		id := f.GetRuleID()
+		if severity, ok := overrides[id]; ok {
+			f.Severity = severity
+			used[id] = true
+		}
+	}
+	var unused []string
+	for id := range overrides {
+		if !used[id] {
+			unused = append(unused, id)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
+
+func (o *ToolOpts) applySeverityOverrides(result *Result) {
+	overrides := o.GetConfig().GetSeverityOverrides(o.Tool.Name())
+	if len(overrides) == 0 {
+		return
+	}
+	for _, id := range applySeverityOverrides(overrides, result.Findings) {
+		log.Warnf("The severity override for {info:%s} rule {warning:%s} did not match any findings", o.Tool.Name(), id)
+	}
+}
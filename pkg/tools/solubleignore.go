@@ -0,0 +1,95 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strings"
+
+	ignore "github.com/sabhiram/go-gitignore"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+const solubleIgnoreFile = ".solubleignore"
+
+// A .solubleignore file has gitignore-style patterns.  Patterns before
+// any section apply to all tools, and patterns following a "[tool]" line
+// only apply to that tool.  For example:
+//
+//	test/**
+//	[hadolint]
+//	legacy/Dockerfile
+type SolubleIgnore struct {
+	global *ignore.GitIgnore
+	tools  map[string]*ignore.GitIgnore
+}
+
+// Read a .solubleignore file.  A missing file ignores nothing, and
+// problems with the file are warnings rather than errors.
+func ReadSolubleIgnore(path string) *SolubleIgnore {
+	si := &SolubleIgnore{tools: map[string]*ignore.GitIgnore{}}
+	f, err := os.Open(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Warnf("Could not read {warning:%s} - {warning:%s}", path, err)
+		}
+		return si
+	}
+	defer f.Close()
+	var (
+		section string
+		global  []string
+	)
+	sections := map[string][]string{}
+	s := bufio.NewScanner(f)
+	for lineNumber := 1; s.Scan(); lineNumber++ {
+		line := strings.TrimSpace(s.Text())
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || len(line) == 2 {
+				log.Warnf("Ignoring invalid section {warning:%s} {secondary:at %s:%d}", line, path, lineNumber)
+				continue
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if section == "" {
+			global = append(global, line)
+		} else {
+			sections[section] = append(sections[section], line)
+		}
+	}
+	if err := s.Err(); err != nil {
+		log.Warnf("Could not read {warning:%s} - {warning:%s}", path, err)
+	}
+	si.global = ignore.CompileIgnoreLines(global...)
+	for tool, lines := range sections {
+		si.tools[tool] = ignore.CompileIgnoreLines(lines...)
+	}
+	return si
+}
+
+// Returns true if path (relative to the repo root) should be ignored
+// by the tool.
+func (si *SolubleIgnore) IsIgnored(toolName, path string) bool {
+	if si.global != nil && si.global.MatchesPath(path) {
+		return true
+	}
+	if ti := si.tools[toolName]; ti != nil {
+		return ti.MatchesPath(path)
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolubleIgnore(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), ".solubleignore")
+	assert.NoError(os.WriteFile(path, []byte(`# comment
+test/**
+[hadolint]
+legacy/Dockerfile
+[broken
+[terrascan]
+*.json
+`), 0600))
+	si := ReadSolubleIgnore(path)
+	assert.True(si.IsIgnored("hadolint", "test/Dockerfile"))
+	assert.True(si.IsIgnored("terrascan", "test/main.tf"))
+	assert.True(si.IsIgnored("hadolint", "legacy/Dockerfile"))
+	assert.False(si.IsIgnored("terrascan", "legacy/Dockerfile"))
+	assert.True(si.IsIgnored("terrascan", "plan.json"))
+	assert.False(si.IsIgnored("", "plan.json"))
+	missing := ReadSolubleIgnore(filepath.Join(t.TempDir(), ".solubleignore"))
+	assert.False(missing.IsIgnored("hadolint", "Dockerfile"))
+}
@@ -0,0 +1,139 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/print"
+)
+
+// The file that --split-output writes failed findings with an
+// unrecognized severity to
+const unknownSeverityName = "unknown"
+
+// Write the failed findings of each severity to a separate file in dir,
+// e.g. critical.json and high.json.  The files are written in the --format
+// if it's yaml, ndjson, or csv, and otherwise as JSON.  With --fields
+// only those fields of the findings are written.  There's a file for every
+// severity even if it has no findings, and an unknown file if some
+// findings have a severity that isn't recognized.
+func saveSplitOutput(dir, format string, fields []string, results Results) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	switch format {
+	case "yaml", "ndjson", "csv":
+	default:
+		format = "json"
+	}
+	if format == "csv" && len(fields) == 0 {
+		fields = defaultCSVFields
+	}
+	split := results.splitBySeverity()
+	names := assessments.SeverityNames.Values()
+	if _, ok := split[unknownSeverityName]; ok {
+		names = append(names, unknownSeverityName)
+	}
+	for _, name := range names {
+		path := filepath.Join(dir, name+"."+format)
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		err = writeSplitOutput(f, format, fields, split[name])
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	log.Infof("Wrote failed findings by severity to {info:%s}", dir)
+	return nil
+}
+
+func writeSplitOutput(w io.Writer, format string, fields []string, results Results) error {
+	if format == "csv" {
+		return results.WriteCSVFields(w, fields)
+	}
+	var n *jnode.Node
+	if len(fields) > 0 {
+		n = results.getFindingFieldsJNode(fields)
+	} else {
+		var err error
+		if n, err = results.getFindingsJNode(); err != nil {
+			return err
+		}
+		if n.IsNull() {
+			n = jnode.NewArrayNode()
+		}
+	}
+	switch format {
+	case "ndjson":
+		for _, e := range n.Elements() {
+			d, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(append(d, '\n')); err != nil {
+				return err
+			}
+		}
+	case "yaml":
+		(&print.YAMLPrinter{}).PrintResult(w, n)
+	default:
+		(&print.JSONPrinter{}).PrintResult(w, n)
+	}
+	return nil
+}
+
+// Returns copies of the results with only the failed findings, keyed by
+// the findings' canonical severity.  Findings with a severity that isn't
+// recognized are in "unknown".
+func (results Results) splitBySeverity() map[string]Results {
+	split := map[string]Results{}
+	for _, result := range results {
+		findings := result.Findings
+		if result.Assessment != nil {
+			findings = result.Assessment.Findings
+		}
+		bySeverity := map[string]assessments.Findings{}
+		for _, f := range findings {
+			if f.Pass {
+				continue
+			}
+			severity := f.GetSeverity()
+			if severity == "" {
+				severity = unknownSeverityName
+			}
+			bySeverity[severity] = append(bySeverity[severity], f)
+		}
+		for severity, findings := range bySeverity {
+			r := *result
+			r.Findings = findings
+			r.Assessment = nil
+			r.AssessmentRaw = nil
+			split[severity] = append(split[severity], &r)
+		}
+	}
+	return split
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveSplitOutput(t *testing.T) {
+	assert := assert.New(t)
+	results := Results{
+		{
+			Values: map[string]string{"TOOL_NAME": "checkov"},
+			Findings: assessments.Findings{
+				{FilePath: "main.tf", Line: 1, Tool: map[string]string{"severity": "CRITICAL"}},
+				{FilePath: "main.tf", Line: 2, Tool: map[string]string{"severity": "warning"}},
+				{FilePath: "main.tf", Line: 3, Tool: map[string]string{"severity": "critical"}, Pass: true},
+			},
+		},
+		{
+			Findings: assessments.Findings{
+				{FilePath: "vars.tf", Line: 4, Severity: "critical"},
+				{FilePath: "vars.tf", Line: 5, Tool: map[string]string{"severity": "bogus"}},
+			},
+		},
+	}
+	dir := filepath.Join(t.TempDir(), "split")
+	assert.NoError(saveSplitOutput(dir, "table", nil, results))
+	counts := map[string]int{}
+	for _, name := range []string{"info", "low", "medium", "high", "critical", "unknown"} {
+		d, err := os.ReadFile(filepath.Join(dir, name+".json"))
+		if assert.NoError(err, name) {
+			n, err := jnode.FromJSON(d)
+			if assert.NoError(err, name) && assert.True(n.IsArray(), name) {
+				counts[name] = n.Size()
+			}
+		}
+	}
+	assert.Equal(map[string]int{"info": 0, "low": 0, "medium": 1, "high": 0, "critical": 2, "unknown": 1}, counts)
+
+	dir = filepath.Join(t.TempDir(), "split")
+	assert.NoError(saveSplitOutput(dir, "csv", []string{"file", "line"}, results[:1]))
+	d, err := os.ReadFile(filepath.Join(dir, "critical.csv"))
+	assert.NoError(err)
+	assert.Equal("file,line\nmain.tf,1\n", string(d))
+	d, err = os.ReadFile(filepath.Join(dir, "low.csv"))
+	assert.NoError(err)
+	assert.Equal("file,line\n", string(d))
+	_, err = os.Stat(filepath.Join(dir, "unknown.csv"))
+	assert.True(os.IsNotExist(err))
+
+	dir = filepath.Join(t.TempDir(), "split")
+	assert.NoError(saveSplitOutput(dir, "ndjson", nil, results))
+	d, err = os.ReadFile(filepath.Join(dir, "critical.ndjson"))
+	assert.NoError(err)
+	assert.Len(strings.Split(strings.TrimSpace(string(d)), "\n"), 2)
+	d, err = os.ReadFile(filepath.Join(dir, "high.ndjson"))
+	assert.NoError(err)
+	assert.Empty(d)
+}
@@ -0,0 +1,134 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"encoding/json"
+	"sync"
+
+	ignore "github.com/sabhiram/go-gitignore"
+	"github.com/soluble-ai/soluble-cli/pkg/api"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+// A suppression of findings that's managed in the Soluble backend.  A
+// suppression applies to a finding if every field that's set matches.
+// The path is a gitignore-style pattern that's matched against the
+// finding's path in the repository.
+type suppression struct {
+	Tool        string `json:"tool"`
+	RuleID      string `json:"ruleId"`
+	Path        string `json:"path"`
+	Fingerprint string `json:"fingerprint"`
+
+	matcher *ignore.GitIgnore
+}
+
+// The suppressions of each organization are only fetched once per run,
+// even when several tools are run.
+var suppressionsCache = struct {
+	sync.Mutex
+	byOrg map[string][]*suppression
+}{byOrg: map[string][]*suppression{}}
+
+// Returns the organization's suppressions.  If they can't be fetched
+// then a warning is logged and nothing is suppressed.
+func getSuppressions(client *api.Client) []*suppression {
+	suppressionsCache.Lock()
+	defer suppressionsCache.Unlock()
+	org := client.GetOrganization()
+	if s, ok := suppressionsCache.byOrg[org]; ok {
+		return s
+	}
+	var result []*suppression
+	n, err := client.Get("/api/v1/org/{org}/suppressions")
+	if err != nil {
+		log.Warnf("Could not get suppressed findings - {warning:%s}.  No findings will be suppressed.", err)
+	} else {
+		for _, e := range n.Path("data").Elements() {
+			s := &suppression{}
+			if err := json.Unmarshal([]byte(e.String()), s); err != nil {
+				log.Warnf("Ignoring invalid suppression {warning:%s}", e)
+				continue
+			}
+			if s.RuleID == "" && s.Path == "" && s.Fingerprint == "" {
+				// this would suppress everything
+				continue
+			}
+			if s.Path != "" {
+				s.matcher = ignore.CompileIgnoreLines(s.Path)
+			}
+			result = append(result, s)
+		}
+	}
+	suppressionsCache.byOrg[org] = result
+	return result
+}
+
+func (s *suppression) matches(toolName, repoPath string, f *assessments.Finding) bool {
+	switch {
+	case s.Tool != "" && s.Tool != toolName:
+		return false
+	case s.RuleID != "" && s.RuleID != f.GetRuleID():
+		return false
+	case s.Path != "" && (repoPath == "" || !s.matcher.MatchesPath(repoPath)):
+		return false
+	case s.Fingerprint != "" && s.Fingerprint != f.PartialFingerprint:
+		return false
+	}
+	return true
+}
+
+// Remove the findings that match a suppression, returning the remaining
+// findings and the number removed.
+func removeSuppressedFindings(suppressions []*suppression, toolName, repoRoot, dir string, findings assessments.Findings) (assessments.Findings, int) {
+	result := make(assessments.Findings, 0, len(findings))
+	for _, f := range findings {
+		repoPath := getFindingRepoPath(repoRoot, dir, f)
+		suppressed := false
+		for _, s := range suppressions {
+			if s.matches(toolName, repoPath, f) {
+				suppressed = true
+				break
+			}
+		}
+		if !suppressed {
+			result = append(result, f)
+		}
+	}
+	return result, len(findings) - len(result)
+}
+
+// Remove the suppressed findings from the result after it's been
+// uploaded, so they're not printed or counted as failures locally.  The
+// uploaded findings are left alone.
+func (o *ToolOpts) applySuppressions(result *Result) {
+	if !o.ApplySuppressions || o.Tool.IsNonAssessment() || o.GetAPIClientConfig().APIToken == "" {
+		return
+	}
+	suppressions := getSuppressions(o.GetAPIClient())
+	if len(suppressions) == 0 {
+		return
+	}
+	var removed int
+	result.Findings, removed = removeSuppressedFindings(suppressions, o.Tool.Name(), o.RepoRoot, result.Directory, result.Findings)
+	if result.Assessment != nil {
+		result.Assessment.Findings, removed = removeSuppressedFindings(suppressions, o.Tool.Name(), o.RepoRoot, result.Directory, result.Assessment.Findings)
+	}
+	if removed > 0 {
+		log.Infof("Suppressed {info:%d} findings of {primary:%s}", removed, o.Tool.Name())
+	}
+}
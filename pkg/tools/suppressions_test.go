@@ -0,0 +1,102 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuppressions(t *testing.T) {
+	assert := assert.New(t)
+	tool := &testTool{}
+	tool.Tool = tool
+	tool.APIServer = "https://api.example.com"
+	tool.APIToken = "xxx"
+	tool.Organization = "suppressions-test"
+	tool.RepoRoot = t.TempDir()
+	tool.ApplySuppressions = true
+	httpmock.ActivateNonDefault(tool.GetAPIClient().GetClient().GetClient())
+	defer httpmock.DeactivateAndReset()
+	requests := 0
+	httpmock.RegisterResponder("GET", "https://api.example.com/api/v1/org/suppressions-test/suppressions",
+		func(r *http.Request) (*http.Response, error) {
+			requests++
+			n := jnode.NewObjectNode()
+			data := n.PutArray("data")
+			data.AppendObject().Put("ruleId", "R1")
+			data.AppendObject().Put("tool", "test").Put("path", "legacy/**")
+			data.AppendObject().Put("tool", "other").Put("ruleId", "R2")
+			data.AppendObject().Put("tool", "test")
+			return httpmock.NewJsonResponse(http.StatusOK, n)
+		})
+	for i := 0; i < 2; i++ {
+		result := &Result{
+			Directory: tool.RepoRoot,
+			Findings: assessments.Findings{
+				{FilePath: "main.tf", Tool: map[string]string{"rule_id": "R1"}},
+				{FilePath: "legacy/old.tf", Tool: map[string]string{"rule_id": "R3"}},
+				{FilePath: "main.tf", Tool: map[string]string{"rule_id": "R2"}},
+			},
+		}
+		if i == 1 {
+			// an uploaded result
+			result.Assessment = &assessments.Assessment{Findings: result.Findings}
+		}
+		tool.applySuppressions(result)
+		if assert.Len(result.Findings, 1) {
+			assert.Equal("R2", result.Findings[0].Tool["rule_id"])
+		}
+		if result.Assessment != nil {
+			assert.Len(result.Assessment.Findings, 1)
+		}
+	}
+	assert.Equal(1, requests)
+}
+
+func TestSuppressionsNotApplied(t *testing.T) {
+	tool := &testTool{}
+	tool.Tool = tool
+	tool.APIServer = "https://api.example.com"
+	tool.APIToken = "xxx"
+	tool.Organization = "suppressions-not-applied"
+	httpmock.ActivateNonDefault(tool.GetAPIClient().GetClient().GetClient())
+	defer httpmock.DeactivateAndReset()
+	result := &Result{Findings: assessments.Findings{{FilePath: "main.tf"}}}
+	tool.applySuppressions(result)
+	assert.Len(t, result.Findings, 1)
+	assert.Equal(t, 0, httpmock.GetTotalCallCount())
+}
+
+func TestSuppressionsUnavailable(t *testing.T) {
+	tool := &testTool{}
+	tool.Tool = tool
+	tool.APIServer = "https://api.example.com"
+	tool.APIToken = "xxx"
+	tool.Organization = "suppressions-unavailable"
+	tool.ApplySuppressions = true
+	httpmock.ActivateNonDefault(tool.GetAPIClient().GetClient().GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "https://api.example.com/api/v1/org/suppressions-unavailable/suppressions",
+		httpmock.NewStringResponder(http.StatusServiceUnavailable, "down"))
+	result := &Result{Findings: assessments.Findings{{FilePath: "main.tf"}}}
+	tool.applySuppressions(result)
+	assert.Len(t, result.Findings, 1)
+}
@@ -14,18 +14,8 @@
 
 package tools
 
-import (
-	"io/ioutil"
-	"os"
-)
+import "github.com/soluble-ai/soluble-cli/pkg/util"
 
 func TempFile(pattern string) (name string, err error) {
-	var f *os.File
-	f, err = ioutil.TempFile("", pattern)
-	if err != nil {
-		return
-	}
-	name = f.Name()
-	f.Close()
-	return
+	return util.TempFile(pattern)
 }
@@ -0,0 +1,97 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terrascan
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/download"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+)
+
+var errFound = errors.New("found")
+
+// Find the metadata of a rule in the installed custom policies or in
+// terrascan's own policies.  Returns nil if the rule can't be found.
+//
+// Terrascan's policy metadata doesn't have separate rationale and
+// remediation fields, so unless a custom policy supplies them the
+// rationale is the policy's description of the risk, and the remediation
+// points to terrascan's documentation of the policies of the provider.
+func FindRule(ruleID string) *jnode.Node {
+	var dirs []string
+	if meta := download.NewManager().GetMeta("terrascan-policies"); meta != nil {
+		if d := meta.FindLatestOrLastInstalledVersion(); d != nil {
+			dirs = append(dirs, d.Dir)
+		}
+	}
+	if home, err := homedir.Dir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".terrascan", "pkg", "policies", "opa", "rego"))
+	}
+	return findRule(dirs, ruleID)
+}
+
+func findRule(dirs []string, ruleID string) *jnode.Node {
+	var rule *jnode.Node
+	for _, dir := range dirs {
+		_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || !strings.HasSuffix(path, ".json") {
+				return nil
+			}
+			n, err := util.ReadJSONFile(path)
+			if err != nil {
+				// not all json files are rule metadata
+				return nil
+			}
+			if n.Path("reference_id").AsText() == ruleID || n.Path("id").AsText() == ruleID {
+				rule = getRule(ruleID, n, path)
+				return errFound
+			}
+			return nil
+		})
+		if rule != nil {
+			return rule
+		}
+	}
+	return nil
+}
+
+func getRule(ruleID string, n *jnode.Node, path string) *jnode.Node {
+	rationale := n.Path("rationale").AsText()
+	if rationale == "" {
+		rationale = n.Path("description").AsText()
+	}
+	helpURL := getHelpURL(ruleID)
+	remediation := n.Path("remediation").AsText()
+	if remediation == "" && helpURL != "" {
+		remediation = fmt.Sprintf("See the documentation of %s at %s", ruleID, helpURL)
+	}
+	return jnode.NewObjectNode().
+		Put("rule_id", ruleID).
+		Put("title", n.Path("name").AsText()).
+		Put("severity", strings.ToLower(n.Path("severity").AsText())).
+		Put("category", n.Path("category").AsText()).
+		Put("resource_type", n.Path("resource_type").AsText()).
+		Put("rationale", rationale).
+		Put("remediation", remediation).
+		Put("help_url", helpURL).
+		Put("source", path)
+}
@@ -15,15 +15,19 @@
 package terrascan
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"github.com/soluble-ai/go-jnode"
 	"github.com/soluble-ai/soluble-cli/pkg/assessments"
 	"github.com/soluble-ai/soluble-cli/pkg/download"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/tracing"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/spf13/cobra"
 )
@@ -34,16 +38,106 @@ var (
 
 type Tool struct {
 	tools.DirectoryBasedToolOpts
-	PolicyType string
+	PolicyTypes   []string
+	ConfigPath    string
+	Module        string
+	ModuleVersion string
+	VarFiles      []string
+
+	extraArgs tools.ExtraArgs
 }
 
 func (t *Tool) Name() string {
 	return "terrascan"
 }
 
+func (t *Tool) CommandTemplate() *cobra.Command {
+	return &cobra.Command{
+		Use:   "terrascan",
+		Short: "Run terrascan",
+		Long: `Run terrascan
+
+Extra arguments after -- are passed to terrascan scan.`,
+		Args: t.extraArgs.ArgsValue(),
+	}
+}
+
+func (t *Tool) Preflight() error {
+	return t.PreflightInstall(&download.Spec{
+		URL: "github.com/accurics/terrascan",
+	})
+}
+
+func (t *Tool) Requirements() []string {
+	return []string{tools.RequiresDownload}
+}
+
 func (t *Tool) Register(cmd *cobra.Command) {
 	t.DirectoryBasedToolOpts.Register(cmd)
-	cmd.Flags().StringVarP(&t.PolicyType, "policy-type", "t", "", "The `policy-type` (aws, azure, gcp, k8s).  Required unless using custom policies.")
+	cmd.Flags().StringSliceVarP(&t.PolicyTypes, "policy-type", "t", nil, "The `policy-type` (e.g. all, aws, azure, gcp, github, k8s) to scan.  May be repeated to scan several types in one pass.  Required unless using custom policies.")
+	cmd.Flags().StringVar(&t.ConfigPath, "terrascan-config", "", "Pass the terrascan config `file` to terrascan (for severity overrides, skipped rules, etc.)")
+	cmd.Flags().StringVar(&t.Module, "module", "", "Download and scan the terraform registry module `source` e.g. hashicorp/consul/aws.  Use the TF_TOKEN_<host> environment variable to authenticate to a private registry.")
+	cmd.Flags().StringVar(&t.ModuleVersion, "module-version", "", "The `version` of the --module to scan")
+	cmd.Flags().StringArrayVar(&t.VarFiles, "var-file", nil, "Pass the terraform variables `file` (e.g. prod.tfvars) to terrascan.  May be repeated.")
+}
+
+func (t *Tool) Validate() error {
+	if t.ConfigPath != "" {
+		if _, err := os.Stat(t.ConfigPath); err != nil {
+			return fmt.Errorf("invalid --terrascan-config: %w", err)
+		}
+	}
+	if err := t.validateVarFiles(); err != nil {
+		return err
+	}
+	if t.Module != "" {
+		if err := t.installModule(); err != nil {
+			return err
+		}
+	}
+	return t.DirectoryBasedToolOpts.Validate()
+}
+
+// Check that each --var-file can be read, and make their paths
+// absolute since terrascan is run with -d.  Terrascan's --var-files is a
+// comma separated list, so paths with commas can't be passed to it.
+func (t *Tool) validateVarFiles() error {
+	for i, file := range t.VarFiles {
+		if strings.Contains(file, ",") {
+			return fmt.Errorf("invalid --var-file %s: terrascan does not support commas in var file paths", file)
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return fmt.Errorf("invalid --var-file: %w", err)
+		}
+		_ = f.Close()
+		if t.VarFiles[i], err = filepath.Abs(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Tool) installModule() error {
+	if t.ModuleVersion == "" {
+		return fmt.Errorf("--module-version must be given with --module")
+	}
+	// the module is only scanned once, so don't keep it in the download cache
+	dir, err := util.MkdirTemp("soluble-module*")
+	if err != nil {
+		return err
+	}
+	m := download.NewManagerInDir(dir)
+	d, err := m.Install(&download.Spec{
+		TerraformModule:  t.Module,
+		RequestedVersion: t.ModuleVersion,
+	})
+	if err != nil {
+		return err
+	}
+	t.Directory = d.GetModuleDir()
+	t.RepoRoot = t.Directory
+	return nil
 }
 
 func (t *Tool) Run() (*tools.Result, error) {
@@ -55,37 +149,96 @@ func (t *Tool) Run() (*tools.Result, error) {
 	if customPoliciesDir != "" {
 		args = append(args, "-p", customPoliciesDir)
 	} else {
-		if t.PolicyType == "" {
+		if len(t.PolicyTypes) == 0 {
 			return nil, fmt.Errorf("--policy-type must be given unless using custom policies")
 		}
-		args = append(args, "-t", t.PolicyType)
+		for _, pt := range t.PolicyTypes {
+			args = append(args, "-t", pt)
+		}
+	}
+	if t.ConfigPath != "" {
+		args = append(args, "--config-path", t.ConfigPath)
 	}
+	for _, file := range t.VarFiles {
+		args = append(args, "--var-files", file)
+	}
+	t.extraArgs.WarnOutputFlags(t.Name(), "-o", "--output")
+	args = append(args, t.extraArgs...)
 	d, err := t.InstallTool(&download.Spec{
 		URL: "github.com/accurics/terrascan",
 	})
 	if err != nil {
-		return nil, err
+		return nil, &tools.ToolNotInstalledError{Tool: t.Name(), Err: err}
 	}
-	program := filepath.Join(d.Dir, "terrascan")
+	program := d.GetExePath("terrascan")
 	scan := exec.Command(program, args...)
+	defer tools.AcquireWorker()()
 	t.LogCommand(scan)
-	scan.Stderr = os.Stderr
+	stderr := &bytes.Buffer{}
+	scan.Stderr = io.MultiWriter(os.Stderr, stderr)
+	span := t.StartCommandSpan(scan)
 	output, err := scan.Output()
-	if err != nil && util.ExitCode(err) != 3 {
+	if util.ExitCode(err) == 3 {
 		// terrascan exits with exit code 3 if violations were found
-		return nil, err
+		err = nil
+	}
+	tracing.EndSpan(span, err)
+	if err != nil {
+		return nil, tools.NewScanExecError(t.Name(), err, stderr.Bytes())
 	}
 	n, err := jnode.FromJSON(output)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkSchema(n, d.Version); err != nil {
+		return nil, err
+	}
 	result := t.parseResults(n)
 	if d.Version != "" {
 		result.AddValue("TERRASCAN_VERSION", d.Version)
 	}
+	if t.Module != "" {
+		t.prefixModuleSource(result)
+	}
 	return result, nil
 }
 
+// The keys of each violation that parseResults depends on
+var violationKeys = []string{"file", "line", "rule_id", "severity"}
+
+// Check that terrascan's output looks like what parseResults expects,
+// so that a change in its output format is an error rather than a scan
+// that silently finds nothing.
+func checkSchema(n *jnode.Node, version string) error {
+	if version == "" {
+		version = "(unknown version)"
+	}
+	results := n.Path("results")
+	if !results.IsObject() {
+		return schemaError(version, "there are no results")
+	}
+	violations := results.Path("violations")
+	switch {
+	case violations.IsArray():
+	case violations.IsMissing() && results.Path("scan_summary").IsMissing():
+		return schemaError(version, "the results have no violations or scan_summary")
+	case !violations.IsMissing() && !violations.IsNull():
+		return schemaError(version, "the violations are not an array")
+	}
+	for i, v := range violations.Elements() {
+		for _, key := range violationKeys {
+			if v.Path(key).IsMissing() {
+				return schemaError(version, fmt.Sprintf("violation %d has no %s", i, key))
+			}
+		}
+	}
+	return nil
+}
+
+func schemaError(version, reason string) error {
+	return fmt.Errorf("cannot parse the output of terrascan %s, its output format may have changed: %s", version, reason)
+}
+
 func (t *Tool) parseResults(n *jnode.Node) *tools.Result {
 	findings := assessments.Findings{}
 	violations := n.Path("results").Path("violations")
@@ -95,14 +248,16 @@ func (t *Tool) parseResults(n *jnode.Node) *tools.Result {
 		})
 		n.Path("results").Put("violations", violations)
 		for _, v := range violations.Elements() {
+			ruleID := v.Path("rule_id").AsText()
 			findings = append(findings, &assessments.Finding{
 				FilePath:    v.Path("file").AsText(),
 				Line:        v.Path("line").AsInt(),
 				Description: v.Path("description").AsText(),
 				Tool: map[string]string{
 					"category": v.Path("category").AsText(),
-					"rule_id":  v.Path("rule_id").AsText(),
+					"rule_id":  ruleID,
 					"severity": v.Path("severity").AsText(),
+					"help_url": getHelpURL(ruleID),
 				},
 			})
 		}
@@ -114,3 +269,37 @@ func (t *Tool) parseResults(n *jnode.Node) *tools.Result {
 	}
 	return result
 }
+
+// terrascan doesn't include links in its output, but its policies are
+// documented per provider.  The rule ids look like AC_AWS_0214,
+// AWS.VPC.Logging.Medium.0470 or accurics.kubernetes.OPS.460.
+func getHelpURL(ruleID string) string {
+	id := strings.ToLower(ruleID)
+	id = strings.TrimPrefix(id, "ac_")
+	id = strings.TrimPrefix(id, "accurics.")
+	var provider string
+	if i := strings.IndexAny(id, "._"); i > 0 {
+		provider = id[:i]
+	}
+	switch provider {
+	case "kubernetes":
+		provider = "k8s"
+	case "aws", "azure", "gcp", "k8s", "github", "docker":
+	default:
+		return ""
+	}
+	return fmt.Sprintf("https://runterrascan.io/docs/policies/%s/", provider)
+}
+
+// Fingerprint the findings in the downloaded module, and then prefix
+// their paths with the module source.  The result's directory stays the
+// module, and the fingerprints are kept when the result is processed
+// because the prefixed paths don't exist.
+func (t *Tool) prefixModuleSource(result *tools.Result) {
+	result.Findings.ComputePartialFingerprints(result.Directory)
+	for _, f := range result.Findings {
+		f.FilePath = fmt.Sprintf("%s/%s", t.Module, f.FilePath)
+		f.RepoPath = f.FilePath
+	}
+	result.AddValue("MODULE_SOURCE", t.Module).AddValue("MODULE_VERSION", t.ModuleVersion)
+}
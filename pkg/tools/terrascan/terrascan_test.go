@@ -15,8 +15,12 @@
 package terrascan
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/soluble-ai/go-jnode"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/stretchr/testify/assert"
@@ -38,5 +42,117 @@ func TestParseResults(t *testing.T) {
 	assert.Equal("infrastructure.tf", f.FilePath)
 	assert.Equal(1, f.Line)
 	assert.Equal("MEDIUM", f.Tool["severity"])
+	assert.Equal("https://runterrascan.io/docs/policies/aws/", f.Tool["help_url"])
 	assert.Equal(results.Unwrap(), result.Data.Unwrap())
 }
+
+func TestGetHelpURL(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("https://runterrascan.io/docs/policies/aws/", getHelpURL("AC_AWS_0214"))
+	assert.Equal("https://runterrascan.io/docs/policies/k8s/", getHelpURL("accurics.kubernetes.OPS.460"))
+	assert.Equal("https://runterrascan.io/docs/policies/azure/", getHelpURL("AC_AZURE_0185"))
+	assert.Equal("", getHelpURL("custom.rule"))
+}
+
+func TestValidateConfigPath(t *testing.T) {
+	tool := &Tool{ConfigPath: "testdata/does-not-exist.toml"}
+	assert.Error(t, tool.Validate())
+}
+
+func TestPrefixModuleSource(t *testing.T) {
+	assert := assert.New(t)
+	results, err := util.ReadJSONFile("testdata/results.json")
+	assert.Nil(err)
+	tool := &Tool{Module: "acme/network/aws", ModuleVersion: "1.0.0"}
+	tool.Directory = t.TempDir()
+	assert.NoError(os.WriteFile(filepath.Join(tool.Directory, "nat-server.tf"),
+		[]byte("resource \"aws_instance\" \"nat\" {\n  ami = \"ami-1234\"\n}\n"), 0600))
+	result := tool.parseResults(results)
+	tool.prefixModuleSource(result)
+	assert.Equal(tool.GetDirectory(), result.Directory)
+	assert.Equal("acme/network/aws/nat-server.tf", result.Findings[0].FilePath)
+	assert.Equal("acme/network/aws/nat-server.tf", result.Findings[0].RepoPath)
+	assert.NotEmpty(result.Findings[0].PartialFingerprint)
+	// fingerprinting again keeps the fingerprints
+	fingerprint := result.Findings[0].PartialFingerprint
+	result.UpdateFileFingerprints()
+	assert.Equal(fingerprint, result.Findings[0].PartialFingerprint)
+	assert.Equal("acme/network/aws", result.Values["MODULE_SOURCE"])
+}
+
+func TestValidateModule(t *testing.T) {
+	tool := &Tool{Module: "acme/network/aws"}
+	assert.Error(t, tool.Validate())
+}
+
+func TestValidateVarFiles(t *testing.T) {
+	assert := assert.New(t)
+	tool := &Tool{VarFiles: []string{"testdata/does-not-exist.tfvars"}}
+	assert.Error(tool.Validate())
+	tool = &Tool{VarFiles: []string{"testdata/prod.tfvars,testdata/dev.tfvars"}}
+	assert.Error(tool.Validate())
+	tool = &Tool{VarFiles: []string{"testdata/prod.tfvars"}}
+	assert.NoError(tool.Validate())
+	if assert.Len(tool.VarFiles, 1) {
+		assert.True(filepath.IsAbs(tool.VarFiles[0]))
+	}
+}
+
+func TestFindRule(t *testing.T) {
+	assert := assert.New(t)
+	dirs := []string{"testdata/no-such-dir", "testdata/policies"}
+	for _, id := range []string{"AWS.S3Bucket.DS.High.1043", "AC_AWS_0214"} {
+		rule := findRule(dirs, id)
+		if assert.NotNil(rule, id) {
+			assert.Equal("s3EnforceUserACL", rule.Path("title").AsText())
+			assert.Equal("high", rule.Path("severity").AsText())
+			assert.Equal(id, rule.Path("rule_id").AsText())
+			assert.Equal("S3 bucket Access is allowed to all AWS Account Users.", rule.Path("rationale").AsText())
+			assert.Equal(fmt.Sprintf("See the documentation of %s at https://runterrascan.io/docs/policies/aws/", id),
+				rule.Path("remediation").AsText())
+		}
+	}
+	rule := findRule(dirs, "ACME_AWS_0001")
+	if assert.NotNil(rule) {
+		assert.Equal("Untagged buckets can't be attributed to a team.", rule.Path("rationale").AsText())
+		assert.Equal("Add an owner tag to the bucket.", rule.Path("remediation").AsText())
+	}
+	assert.Nil(findRule(dirs, "AWS.S3Bucket.DS.High.9999"))
+}
+
+func TestValidatePolicyTypes(t *testing.T) {
+	assert := assert.New(t)
+	// terrascan checks the policy types itself
+	for _, pt := range []string{"all", "github", "aws"} {
+		tool := &Tool{PolicyTypes: []string{pt}}
+		assert.NoError(tool.Validate(), pt)
+	}
+}
+
+func TestCheckSchema(t *testing.T) {
+	assert := assert.New(t)
+	results, err := util.ReadJSONFile("testdata/results.json")
+	assert.Nil(err)
+	assert.NoError(checkSchema(results, "v1.13.0"))
+	for _, good := range []string{
+		`{"results":{"violations":null,"scan_summary":{}}}`,
+		`{"results":{"violations":[]}}`,
+		`{"results":{"scan_summary":{}}}`,
+	} {
+		n, _ := jnode.FromJSON([]byte(good))
+		assert.NoError(checkSchema(n, "v1.13.0"), good)
+	}
+	for _, bad := range []string{
+		`{"runs":[]}`,
+		`{"results":[]}`,
+		`{"results":{}}`,
+		`{"results":{"violations":{}}}`,
+		`{"results":{"violations":[{"rule_id":"AC_AWS_0214","line":1,"severity":"HIGH"}]}}`,
+	} {
+		n, _ := jnode.FromJSON([]byte(bad))
+		err := checkSchema(n, "v2.0.0")
+		if assert.Error(err, bad) {
+			assert.Contains(err.Error(), "terrascan v2.0.0", bad)
+		}
+	}
+}
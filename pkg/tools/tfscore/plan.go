@@ -6,6 +6,7 @@ import (
 
 	"github.com/soluble-ai/soluble-cli/pkg/download"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/tracing"
 	"github.com/spf13/cobra"
 )
 
@@ -23,6 +24,14 @@ func (t *PlanTool) Name() string {
 	return "tfscore-plan"
 }
 
+func (t *PlanTool) Preflight() error {
+	return t.PreflightInstall(&download.Spec{Name: "tfscore"})
+}
+
+func (t *PlanTool) Requirements() []string {
+	return []string{tools.RequiresDownload}
+}
+
 func (t *PlanTool) Register(cmd *cobra.Command) {
 	t.DirectoryBasedToolOpts.Register(cmd)
 	flags := cmd.Flags()
@@ -59,6 +68,10 @@ func (t *PlanTool) Run() (*tools.Result, error) {
 	c := exec.Command(d.GetExePath("tfscore"), args...)
 	c.Stderr = os.Stderr
 	c.Stdout = os.Stderr
+	defer tools.AcquireWorker()()
 	t.LogCommand(c)
-	return nil, c.Run()
+	span := t.StartCommandSpan(c)
+	err = c.Run()
+	tracing.EndSpan(span, err)
+	return nil, err
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/soluble-ai/soluble-cli/pkg/assessments"
 	"github.com/soluble-ai/soluble-cli/pkg/download"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/tracing"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/spf13/cobra"
 )
@@ -28,6 +29,14 @@ func (t *Tool) Name() string {
 	return "tfscore"
 }
 
+func (t *Tool) Preflight() error {
+	return t.PreflightInstall(&download.Spec{Name: "tfscore"})
+}
+
+func (t *Tool) Requirements() []string {
+	return []string{tools.RequiresDownload}
+}
+
 func (t *Tool) Register(cmd *cobra.Command) {
 	t.DirectoryBasedToolOpts.Register(cmd)
 	flags := cmd.Flags()
@@ -79,8 +88,12 @@ func (t *Tool) Run() (*tools.Result, error) {
 	c := exec.Command(d.GetExePath("tfscore"), args...)
 	c.Stderr = os.Stderr
 	c.Stdout = os.Stderr
+	defer tools.AcquireWorker()()
 	t.LogCommand(c)
-	if err := c.Run(); err != nil {
+	span := t.StartCommandSpan(c)
+	err = c.Run()
+	tracing.EndSpan(span, err)
+	if err != nil {
 		return nil, err
 	}
 	var result *tools.Result
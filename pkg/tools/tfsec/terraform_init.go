@@ -11,6 +11,7 @@ import (
 	"github.com/soluble-ai/soluble-cli/pkg/inventory/terraformsettings"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/tracing"
 )
 
 type deletedFile struct {
@@ -76,7 +77,9 @@ func (t *Tool) runTerraformInit() (*terraformInit, error) {
 		cmd.Stdout = os.Stdout
 		cmd.Dir = dir
 		t.LogCommand(cmd)
+		span := t.StartCommandSpan(cmd)
 		err := cmd.Run()
+		tracing.EndSpan(span, err)
 		if err != nil {
 			tfi.restore()
 			return nil, err
@@ -26,6 +26,7 @@ import (
 	"github.com/soluble-ai/soluble-cli/pkg/download"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/tracing"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/spf13/cobra"
 )
@@ -35,6 +36,8 @@ type Tool struct {
 	NoInit           bool
 	TerraformVersion string
 	TerraformCommand string
+
+	extraArgs tools.ExtraArgs
 }
 
 var _ tools.Single = &Tool{}
@@ -43,6 +46,27 @@ func (t *Tool) Name() string {
 	return "tfsec"
 }
 
+func (t *Tool) CommandTemplate() *cobra.Command {
+	return &cobra.Command{
+		Use:   "tfsec",
+		Short: "Run tfsec",
+		Long: `Run tfsec
+
+Extra arguments after -- are passed to tfsec.`,
+		Args: t.extraArgs.ArgsValue(),
+	}
+}
+
+func (t *Tool) Preflight() error {
+	return t.PreflightInstall(&download.Spec{
+		URL: "github.com/tfsec/tfsec",
+	})
+}
+
+func (t *Tool) Requirements() []string {
+	return []string{tools.RequiresDownload}
+}
+
 func (t *Tool) Register(cmd *cobra.Command) {
 	t.DirectoryBasedToolOpts.Register(cmd)
 	cmd.Flags().BoolVar(&t.NoInit, "no-init", false, "Don't try and run terraform init on every detected root module first")
@@ -76,16 +100,21 @@ func (t *Tool) Run() (*tools.Result, error) {
 	args = t.addTfVarsFileArg(args, "terraform.tfvars")
 	args = t.addTfVarsFileArg(args, "terraform.tfvars.json")
 	args = t.addAutoTfVarsFiles(args)
+	t.extraArgs.WarnOutputFlags(t.Name(), "-f", "--format")
+	args = append(args, t.extraArgs...)
 	args = append(args, ".")
 	// #nosec G204
 	c := exec.Command(d.GetExePath("tfsec-tfsec"), args...)
 	c.Dir = t.GetDirectory()
 	c.Stderr = os.Stderr
+	defer tools.AcquireWorker()()
 	t.LogCommand(c)
+	span := t.StartCommandSpan(c)
 	output, err := c.Output()
 	if util.ExitCode(err) == 1 {
 		err = nil
 	}
+	tracing.EndSpan(span, err)
 	if err != nil {
 		return nil, err
 	}
@@ -159,6 +188,11 @@ func (t *Tool) parseResults(n *jnode.Node) *tools.Result {
 			if t.IsExcluded(filename) {
 				continue
 			}
+			link := r.Path("link").AsText()
+			if link == "" {
+				// newer versions of tfsec return a list of links
+				link = r.Path("links").Get(0).AsText()
+			}
 			findings = append(findings, &assessments.Finding{
 				FilePath:      filename,
 				Line:          r.Path("location").Path("start_line").AsInt(),
@@ -167,6 +201,7 @@ func (t *Tool) parseResults(n *jnode.Node) *tools.Result {
 				Tool: map[string]string{
 					"severity": r.Path("severity").AsText(),
 					"rule_id":  r.Path("rule_id").AsText(),
+					"help_url": link,
 				},
 			})
 		}
@@ -39,6 +39,7 @@ func TestParseResults(t *testing.T) {
 	f := result.Findings[8]
 	assert.Equal(16, f.Line)
 	assert.Equal("variables.tf", f.FilePath)
+	assert.Equal("https://github.com/tfsec/tfsec/wiki/"+f.Tool["rule_id"], f.Tool["help_url"])
 	// verify filepath was rewritten within results.Data
 	assert.Equal("variables.tf", result.Data.Path("results").Get(8).Path("location").Path("filename").AsText())
 }
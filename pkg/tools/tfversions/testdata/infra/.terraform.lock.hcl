@@ -0,0 +1,10 @@
+# This file is maintained automatically by "terraform init".
+# Manual edits may be lost in future updates.
+
+provider "registry.terraform.io/hashicorp/aws" {
+  version     = "4.22.0"
+  constraints = "~> 4.0"
+  hashes = [
+    "h1:abc=",
+  ]
+}
@@ -0,0 +1,13 @@
+provider "aws" {
+  region = var.region
+}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "3.14.0"
+  name    = var.name
+}
+
+module "local" {
+  source = "./modules/local"
+}
@@ -0,0 +1,242 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfversions
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/print"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/spf13/cobra"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const lockFileName = ".terraform.lock.hcl"
+
+type Tool struct {
+	tools.DirectoryBasedToolOpts
+}
+
+// A provider or module that terraform code depends on.  Kind is one of
+// provider (from required_providers), module, or locked-provider (from
+// the dependency lock file.)
+type Dependency struct {
+	Kind        string `json:"kind"`
+	Name        string `json:"name,omitempty"`
+	Source      string `json:"source,omitempty"`
+	Version     string `json:"version,omitempty"`
+	Constraints string `json:"constraints,omitempty"`
+	FilePath    string `json:"filePath"`
+	Line        int    `json:"line"`
+}
+
+var _ tools.Single = &Tool{}
+
+func (*Tool) Name() string {
+	return "terraform-versions"
+}
+
+func (*Tool) IsNonAssessment() bool {
+	return true
+}
+
+func (t *Tool) Register(cmd *cobra.Command) {
+	t.DirectoryBasedToolOpts.Register(cmd)
+	t.Path = []string{"dependencies"}
+	t.Columns = []string{"kind", "name", "source", "version", "filePath", "line"}
+	t.WideColumns = []string{"constraints"}
+}
+
+func (t *Tool) CommandTemplate() *cobra.Command {
+	return &cobra.Command{
+		Use:   "versions",
+		Short: "List the terraform provider and module versions",
+		Long: `List the versions of the providers and modules that terraform code
+requires, and the provider versions pinned in .terraform.lock.hcl files.
+
+This reports dependencies rather than findings, and the report is uploaded
+as terraform_versions.json.`,
+	}
+}
+
+func (t *Tool) Run() (*tools.Result, error) {
+	dir := t.GetDirectory()
+	var deps []*Dependency
+	for _, moduleDir := range t.GetInventory().TerraformModules.Values() {
+		d, err := readModule(dir, moduleDir)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, d...)
+	}
+	sort.SliceStable(deps, func(i, j int) bool {
+		if deps[i].FilePath != deps[j].FilePath {
+			return deps[i].FilePath < deps[j].FilePath
+		}
+		return deps[i].Line < deps[j].Line
+	})
+	if deps == nil {
+		deps = []*Dependency{}
+	}
+	n, err := print.ToResult(map[string]interface{}{"dependencies": deps})
+	if err != nil {
+		return nil, err
+	}
+	result := &tools.Result{
+		Data:      n,
+		Directory: dir,
+	}
+	result.AddArtifact("terraform_versions.json", []byte(n.String()))
+	return result, nil
+}
+
+// Read the dependencies of the terraform module in moduleDir, which is
+// relative to dir.
+func readModule(dir, moduleDir string) ([]*Dependency, error) {
+	var deps []*Dependency
+	entries, err := os.ReadDir(filepath.Join(dir, moduleDir))
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !(strings.HasSuffix(name, ".tf") || name == lockFileName) {
+			continue
+		}
+		path := filepath.Join(moduleDir, name)
+		d, err := readFile(dir, path)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, d...)
+	}
+	return deps, nil
+}
+
+func readFile(dir, path string) ([]*Dependency, error) {
+	src, err := os.ReadFile(filepath.Join(dir, path))
+	if err != nil {
+		return nil, err
+	}
+	path = filepath.ToSlash(path)
+	f, diags := hclsyntax.ParseConfig(src, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		log.Warnf("Could not parse {warning:%s} - {warning:%s}", path, diags.Error())
+		return nil, nil
+	}
+	body, ok := f.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, nil
+	}
+	if filepath.Base(path) == lockFileName {
+		return getLockedProviders(path, body), nil
+	}
+	return getDependencies(path, body), nil
+}
+
+func getDependencies(path string, body *hclsyntax.Body) []*Dependency {
+	var deps []*Dependency
+	for _, block := range body.Blocks {
+		switch {
+		case block.Type == "terraform":
+			for _, rp := range block.Body.Blocks {
+				if rp.Type == "required_providers" {
+					deps = append(deps, getRequiredProviders(path, rp.Body)...)
+				}
+			}
+		case block.Type == "module" && len(block.Labels) == 1:
+			deps = append(deps, &Dependency{
+				Kind:     "module",
+				Name:     block.Labels[0],
+				Source:   getString(block.Body.Attributes["source"]),
+				Version:  getString(block.Body.Attributes["version"]),
+				FilePath: path,
+				Line:     block.DefRange().Start.Line,
+			})
+		}
+	}
+	return deps
+}
+
+func getRequiredProviders(path string, body *hclsyntax.Body) []*Dependency {
+	var deps []*Dependency
+	for name, attr := range body.Attributes {
+		dep := &Dependency{
+			Kind:     "provider",
+			Name:     name,
+			FilePath: path,
+			Line:     attr.SrcRange.Start.Line,
+		}
+		v, diags := attr.Expr.Value(nil)
+		switch {
+		case diags.HasErrors() || !v.IsWhollyKnown() || v.IsNull():
+		case v.Type() == cty.String:
+			// the pre-0.13 form is just a version constraint
+			dep.Version = v.AsString()
+		case v.Type().IsObjectType():
+			dep.Source = getObjectString(v, "source")
+			dep.Version = getObjectString(v, "version")
+		}
+		deps = append(deps, dep)
+	}
+	return deps
+}
+
+func getLockedProviders(path string, body *hclsyntax.Body) []*Dependency {
+	var deps []*Dependency
+	for _, block := range body.Blocks {
+		if block.Type != "provider" || len(block.Labels) != 1 {
+			continue
+		}
+		deps = append(deps, &Dependency{
+			Kind:        "locked-provider",
+			Source:      block.Labels[0],
+			Version:     getString(block.Body.Attributes["version"]),
+			Constraints: getString(block.Body.Attributes["constraints"]),
+			FilePath:    path,
+			Line:        block.DefRange().Start.Line,
+		})
+	}
+	return deps
+}
+
+// Returns the value of an attribute if it's a literal string.
+func getString(attr *hclsyntax.Attribute) string {
+	if attr == nil {
+		return ""
+	}
+	v, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || v.IsNull() || !v.IsKnown() || v.Type() != cty.String {
+		return ""
+	}
+	return v.AsString()
+}
+
+func getObjectString(v cty.Value, name string) string {
+	if !v.Type().HasAttribute(name) {
+		return ""
+	}
+	a := v.GetAttr(name)
+	if a.IsNull() || a.Type() != cty.String {
+		return ""
+	}
+	return a.AsString()
+}
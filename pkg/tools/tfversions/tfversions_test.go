@@ -0,0 +1,61 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfversions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	assert := assert.New(t)
+	tool := &Tool{}
+	tool.Directory = "testdata"
+	result, err := tool.Run()
+	if !assert.NoError(err) {
+		return
+	}
+	deps := result.Data.Path("dependencies")
+	if assert.Equal(5, deps.Size(), deps) {
+		lock := deps.Get(0)
+		assert.Equal("locked-provider", lock.Path("kind").AsText())
+		assert.Equal("registry.terraform.io/hashicorp/aws", lock.Path("source").AsText())
+		assert.Equal("4.22.0", lock.Path("version").AsText())
+		assert.Equal("~> 4.0", lock.Path("constraints").AsText())
+		assert.Equal("infra/.terraform.lock.hcl", lock.Path("filePath").AsText())
+		vpc := deps.Get(1)
+		assert.Equal("module", vpc.Path("kind").AsText())
+		assert.Equal("vpc", vpc.Path("name").AsText())
+		assert.Equal("terraform-aws-modules/vpc/aws", vpc.Path("source").AsText())
+		assert.Equal("3.14.0", vpc.Path("version").AsText())
+		assert.Equal(5, vpc.Path("line").AsInt())
+		local := deps.Get(2)
+		assert.Equal("./modules/local", local.Path("source").AsText())
+		assert.Equal("", local.Path("version").AsText())
+		aws := deps.Get(3)
+		assert.Equal("provider", aws.Path("kind").AsText())
+		assert.Equal("hashicorp/aws", aws.Path("source").AsText())
+		assert.Equal("~> 4.0", aws.Path("version").AsText())
+		assert.Equal("infra/versions.tf", aws.Path("filePath").AsText())
+		assert.Equal(4, aws.Path("line").AsInt())
+		random := deps.Get(4)
+		assert.Equal("random", random.Path("name").AsText())
+		assert.Equal("3.1.0", random.Path("version").AsText())
+	}
+	if assert.Len(result.Artifacts, 1) {
+		assert.Equal("terraform_versions.json", result.Artifacts[0].Name)
+	}
+}
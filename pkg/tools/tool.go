@@ -23,6 +23,26 @@ type Interface interface {
 	Validate() error
 	Name() string
 	IsNonAssessment() bool
+	// Check that the prerequisites for running the tool (e.g. docker) are
+	// available without running it.
+	Preflight() error
+	// Returns what the tool needs to run, RequiresDocker and/or
+	// RequiresDownload, and the names of any programs that must already
+	// be installed e.g. helm.  This doesn't check that they're available.
+	Requirements() []string
+}
+
+const (
+	// The tool runs a docker image
+	RequiresDocker = "docker"
+	// The tool is downloaded and run locally
+	RequiresDownload = "download"
+)
+
+// A tool that runs a docker image, so that the image can be pulled
+// ahead of time.  Returns "" if the tool won't run docker.
+type HasDockerImage interface {
+	GetDockerImage() string
 }
 
 // A Single tool runs and returns a single result
@@ -15,45 +15,102 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/api"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
 	"github.com/soluble-ai/soluble-cli/pkg/blurb"
 	"github.com/soluble-ai/soluble-cli/pkg/download"
 	"github.com/soluble-ai/soluble-cli/pkg/inventory"
 	"github.com/soluble-ai/soluble-cli/pkg/log"
 	"github.com/soluble-ai/soluble-cli/pkg/options"
 	"github.com/soluble-ai/soluble-cli/pkg/print"
+	"github.com/soluble-ai/soluble-cli/pkg/s3"
+	"github.com/soluble-ai/soluble-cli/pkg/tracing"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/soluble-ai/soluble-cli/pkg/version"
+	"github.com/soluble-ai/soluble-cli/pkg/xcp"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// Findings larger than this are uploaded in chunks by default
+const defaultUploadSizeLimit = 32 << 20
+
 type ToolOpts struct {
 	RunOpts
 	Tool                  Interface
 	UploadEnabled         bool
 	PrintResultOpt        bool
 	SaveResult            string
+	SaveResults           string
 	PrintResultValues     bool
 	SaveResultValues      string
 	DisableCustomPolicies bool
+	ApplySuppressions     bool
 	RepoRoot              string
 	PrintFingerprints     bool
 	SaveFingerprints      string
+	FingerprintsOnly      bool
 	ConfigFile            string
+	MaxFindings           int
+	Check                 bool
+	Output                string
+	UploadSizeLimit       int
+	RequireFindings       bool
+	WebhookURL            string
+	WebhookHeaders        []string
+	GroupBy               string
+	Fields                []string
+	SeverityCountOnly     bool
+	Parallelism           int
+	SaveHTMLReport        string
+	SaveGitLabReport      string
+	SaveCSV               string
+	SplitOutput           string
+	OutputFile            string
+	EnvAuditLog           string
+	NewSince              string
+	Author                string
+	Mine                  bool
+	PostScanHook          string
+	PostScanHookFail      bool
+	EmitMetadata          string
+	AttachLog             string
+	UploadBatchSize       int
+	UploadBatchInterval   time.Duration
 
 	customPoliciesDir *string
 	config            *Config
 	repoRootSet       bool
+	s3Client          *s3.Client
+	s3Bucket          string
+	s3Prefix          string
+	webhookHeaders    map[string]string
+	cleanups          []func()
+	parallelismFlag   bool
+	newSince          time.Time
+	authors           []string
+	gitUser           xcp.BlameLine
+	ownerRules        []*ownerRule
+	codeOwners        []*ownerRule
+	ownerRulesRead    bool
+	batches           *batchWriter
 }
 
 var _ options.Interface = &ToolOpts{}
@@ -62,6 +119,14 @@ func (o *ToolOpts) IsNonAssessment() bool {
 	return false
 }
 
+func (o *ToolOpts) Preflight() error {
+	return nil
+}
+
+func (o *ToolOpts) Requirements() []string {
+	return nil
+}
+
 func (o *ToolOpts) GetToolOptions() *ToolOpts {
 	return o
 }
@@ -79,33 +144,60 @@ func (o *ToolOpts) getConfig(repoRoot string) *Config {
 		if o.ConfigFile != "" {
 			o.config = ReadConfigFile(o.ConfigFile)
 		} else {
-			oldConfig := filepath.Join(repoRoot, ".soluble", "config.yml")
-			newConfig := filepath.Join(repoRoot, ".lacework", "config.yml")
-			if util.FileExists(oldConfig) && !util.FileExists(newConfig) {
-				log.Warnf("{info:%s} is {warning:deprecated}.  Use {info:%s} instead.",
-					oldConfig, newConfig)
-				o.config = ReadConfigFile(oldConfig)
-			} else {
-				o.config = ReadConfigFile(newConfig)
-			}
+			o.config = ReadRepoConfigFile(repoRoot)
 		}
 	}
 	return o.config
 }
 
+// Read the config file of the repository at repoRoot, returning an
+// empty config if there isn't one.
+func ReadRepoConfigFile(repoRoot string) *Config {
+	oldConfig := filepath.Join(repoRoot, ".soluble", "config.yml")
+	newConfig := filepath.Join(repoRoot, ".lacework", "config.yml")
+	if util.FileExists(oldConfig) && !util.FileExists(newConfig) {
+		log.Warnf("{info:%s} is {warning:deprecated}.  Use {info:%s} instead.",
+			oldConfig, newConfig)
+		return ReadConfigFile(oldConfig)
+	}
+	return ReadConfigFile(newConfig)
+}
+
 func (o *ToolOpts) GetToolHiddenOptions() *options.HiddenOptionsGroup {
 	return &options.HiddenOptionsGroup{
 		Name: "tool-options",
 		Long: "Options for running tools",
 		CreateFlagsFunc: func(flags *pflag.FlagSet) {
 			flags.BoolVar(&o.DisableCustomPolicies, "disable-custom-policies", false, "Don't use custom policies")
+			flags.BoolVar(&o.ApplySuppressions, "apply-suppressions", false, "Don't print or fail on the findings that are suppressed in Soluble.  The uploaded results still include them.")
 			flags.BoolVar(&o.PrintResultOpt, "print-result", false, "Print the JSON result from the tool on stderr")
 			flags.StringVar(&o.SaveResult, "save-result", "", "Save the JSON reesult from the tool to `file`")
+			flags.StringVar(&o.SaveResults, "save-results", "", "Save results.json to `file` exactly as it was uploaded.  If more than one result is uploaded (e.g. by auto-scan) the others are saved next to it with the tool and directory in the name, e.g. results-checkov-infra.json.")
 			flags.BoolVar(&o.PrintResultValues, "print-result-values", false, "Print the result values from the tool on stderr")
 			flags.StringVar(&o.SaveResultValues, "save-result-values", "", "Save the result values from the tool to `file`")
+			flags.StringVar(&o.EmitMetadata, "emit-metadata", "", "Save the scan metadata (tool, version, CI and git information) of each result as a JSON array to `file`.  This is independent of --upload.")
 			flags.BoolVar(&o.PrintFingerprints, "print-fingerprints", false, "Print fingerprints on stderr before uploading results")
 			flags.StringVar(&o.SaveFingerprints, "save-fingerprints", "", "Save finding fingerprints to `file`")
+			flags.BoolVar(&o.FingerprintsOnly, "fingerprints-only", false, "Only print the fingerprints of the findings as JSON, sorted by file and line, and don't upload the results.  Use this to check that fingerprints are stable across commits.")
 			flags.StringVar(&o.ConfigFile, "config-file", "", "Read tool configuration from `file`, overriding the default config file search.")
+			flags.IntVar(&o.MaxFindings, "max-findings", 0, "Keep at most this `number` of the most severe findings.  The result is marked as truncated if findings are dropped.")
+			flags.StringVar(&o.Output, "output", "", "Also write results, findings, and fingerprints to `s3://bucket/prefix`.  AWS credentials are found in the standard way, and the region of the bucket is looked up.")
+			flags.StringVar(&o.WebhookURL, "webhook-url", "", "Also POST the findings and a summary as JSON to `url`.  This is independent of --upload.")
+			flags.StringVar(&o.AttachLog, "attach-log", "", "Upload the build log in `file` with the results to help debug the scan.  At most the last 1MB of the log is uploaded, and values that look like secrets are redacted.")
+			flags.StringArrayVar(&o.WebhookHeaders, "webhook-header", nil, "Add the `header` e.g. \"Authorization: Bearer xxx\" to --webhook-url requests.  May be repeated.")
+			flags.StringVar(&o.PostScanHook, "post-scan-hook", "", "After the scan, run `command` with sh, passing the findings as JSON on stdin and the counts of failed findings in SOLUBLE_FINDINGS_{CRITICAL,HIGH,MEDIUM,LOW,TOTAL}.  Environment variables that look like secrets are not passed to the command.")
+			flags.BoolVar(&o.PostScanHookFail, "post-scan-hook-fail", false, "Fail the run if the --post-scan-hook command fails, instead of only warning")
+			flags.BoolVar(&o.RequireFindings, "require-findings", false, "Exit with code 4 if the tool produces no findings, e.g. to catch a scan that silently scanned nothing")
+			flags.IntVar(&o.UploadSizeLimit, "upload-size-limit", defaultUploadSizeLimit, "Upload findings in chunks if the findings and fingerprints are larger than this many `bytes`.  If 0 then the findings are always uploaded at once.")
+			flags.IntVar(&o.UploadBatchSize, "upload-batch-size", 0, "While the tool runs, upload the findings it has found in batches of `N`, so that they're not lost if the scan fails.  Only tools that find findings incrementally (e.g. kubernetes-scan --scan-images) upload findings while they run.  All of the findings are uploaded with the results when the scan finishes.")
+			flags.DurationVar(&o.UploadBatchInterval, "upload-batch-interval", 0, "While the tool runs, upload the findings it has found every `duration` e.g. 30s.  See --upload-batch-size.")
+			flags.StringVar(&o.SaveHTMLReport, "save-html-report", "", "Save an HTML report of the findings to `file`")
+			flags.StringVar(&o.SaveCSV, "save-csv", "", "Save the findings as CSV to `file`.  The columns are chosen with --fields, by default they are "+strings.Join(defaultCSVFields, ", "))
+			flags.StringVar(&o.SplitOutput, "split-output", "", "Save the failed findings of each severity to a separate file in `dir`, e.g. critical.json and high.json.  The files are in the --format if it's yaml, ndjson, or csv, and JSON otherwise.  Every severity has a file even if there are no findings.")
+			flags.StringVar(&o.SaveGitLabReport, "save-gitlab-code-quality", "", "Save the failed findings as a GitLab code quality report to `file`")
+			flags.IntVar(&o.Parallelism, "parallelism", runtime.GOMAXPROCS(0), "Run at most `N` tool processes at the same time.  If N <= 0 then the number is unbounded.")
+			flags.StringVar(&o.EnvAuditLog, "env-audit-log", "", "Append the names of the environment variables included in uploads, and of the ones redacted, to `file`.  Values are never logged.")
+			o.parallelismFlag = true
 		},
 	}
 }
@@ -119,14 +211,64 @@ func (o *ToolOpts) Register(c *cobra.Command) {
 	o.RunOpts.Register(c)
 	flags := c.Flags()
 	flags.BoolVar(&o.UploadEnabled, "upload", true, "Upload report to Soluble.  Use --upload=false to disable.")
+	flags.StringVar(&o.GroupBy, "group-by", "", "Print failed findings grouped by `kind` (rule or file.)  Only applies to table output.  Use --format files to just list the files with failed findings.")
+	flags.StringSliceVar(&o.Fields, "fields", nil,
+		fmt.Sprintf("Only print these `fields` of the findings e.g. file,line,severity,rule_id.  The fields are %s.  By default the sid, severity, pass, title, file, and line are printed.",
+			strings.Join(getFindingFieldNames(), ", ")))
+	flags.StringVar(&o.OutputFile, "output-file", "", "Write the results in the chosen --format to `file` instead of stdout, and print a summary of the findings")
+	flags.BoolVar(&o.SeverityCountOnly, "severity-count-only", false,
+		"Only print the number of failed findings by severity, e.g. critical=0 high=3 medium=5 low=2 total=10")
+	flags.StringVar(&o.NewSince, "new-since", "",
+		"Ignore findings on lines that git blame says were last changed before this `date` e.g. 2022-01-31.  Findings in untracked files or on uncommitted lines are always reported.")
+	flags.StringVar(&o.Author, "author", "",
+		"Only report findings on lines that git blame says were last changed by `author` (an email or name.)  Uncommitted lines are attributed to the current git user.  All findings are still uploaded.")
+	flags.BoolVar(&o.Mine, "mine", false,
+		"Only report findings on lines last changed by the current git user, including uncommitted changes")
+	flags.BoolVar(&o.Check, "check", false, "Check that the tool can run (e.g. that docker is available) without running it.")
 	o.GetToolHiddenOptions().Register(c)
 }
 
 func (o *ToolOpts) Validate() error {
+	if err := validateGroupBy(o.GroupBy); err != nil {
+		return err
+	}
+	if err := validateFields(o.Fields); err != nil {
+		return err
+	}
+	if o.parallelismFlag {
+		// tools run by other tools (e.g. auto-scan) share the same limit
+		SetParallelism(o.Parallelism)
+	}
+	if o.NewSince != "" && o.newSince.IsZero() {
+		t, err := parseNewSince(o.NewSince)
+		if err != nil {
+			return err
+		}
+		o.newSince = t
+	}
+	if o.EnvAuditLog != "" {
+		xcp.EnvAuditLog = o.EnvAuditLog
+	}
+	if o.FingerprintsOnly {
+		o.UploadEnabled = false
+	}
 	if o.UploadEnabled && o.GetAPIClientConfig().APIToken == "" {
 		blurb.SignupBlurb(o, "This command requires signing up with {primary:Soluble} (unless --upload=false).", "")
 		return fmt.Errorf("not authenticated with Soluble")
 	}
+	if o.UploadEnabled {
+		if err := o.ValidateOrganization(); err != nil {
+			return err
+		}
+	}
+	if o.SaveResults != "" && !o.UploadEnabled {
+		log.Warnf("Results are only saved with {warning:--save-results} when they're uploaded")
+	}
+	if o.AttachLog != "" {
+		if fi, err := os.Stat(o.AttachLog); err != nil || fi.IsDir() {
+			return fmt.Errorf("--attach-log %s is not a file", o.AttachLog)
+		}
+	}
 	if o.RepoRoot == "" && !o.repoRootSet {
 		r, err := inventory.FindRepoRoot(".")
 		if err != nil {
@@ -134,6 +276,53 @@ func (o *ToolOpts) Validate() error {
 		}
 		o.RepoRoot = r
 	}
+	if (o.Author != "" || o.Mine) && o.authors == nil {
+		if err := o.validateAuthor(); err != nil {
+			return err
+		}
+	}
+	if o.ConfigFile != "" && o.config == nil {
+		c, err := LoadConfigFile(o.ConfigFile)
+		if err != nil {
+			return err
+		}
+		o.config = c
+	}
+	if allowlist := o.GetConfig().GetEnvAllowlist(); allowlist != nil {
+		xcp.EnvAllowlist = allowlist
+	}
+	if o.Output != "" && o.s3Client == nil {
+		var err error
+		o.s3Bucket, o.s3Prefix, err = s3.ParseURL(o.Output)
+		if err != nil {
+			return err
+		}
+		o.s3Client, err = s3.NewClient(context.Background())
+		if err != nil {
+			return fmt.Errorf("cannot write to %s: %w", o.Output, err)
+		}
+	}
+	if o.WebhookURL != "" && o.webhookHeaders == nil {
+		if err := o.validateWebhook(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *ToolOpts) validateWebhook() error {
+	u, err := url.Parse(o.WebhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("--webhook-url must be an http or https URL")
+	}
+	o.webhookHeaders = map[string]string{}
+	for _, h := range o.WebhookHeaders {
+		colon := strings.IndexByte(h, ':')
+		if colon <= 0 {
+			return fmt.Errorf("invalid --webhook-header %s, must be in the form name: value", h)
+		}
+		o.webhookHeaders[strings.TrimSpace(h[:colon])] = strings.TrimSpace(h[colon+1:])
+	}
 	return nil
 }
 
@@ -148,40 +337,120 @@ func (o *ToolOpts) InstallAPIServerArtifact(name, urlPath string) (*download.Dow
 	})
 }
 
-func (o *ToolOpts) RunTool() (Results, error) {
+// Install the organization's custom policy bundle for a tool.  The
+// bundle is cached and only downloaded again when its ETag changes.
+func InstallCustomPolicies(apiServer download.APIServer, toolName string) (*download.Download, error) {
+	m := download.NewManager()
+	return m.Install(&download.Spec{
+		Name:                       fmt.Sprintf("%s-policies", toolName),
+		APIServerArtifact:          fmt.Sprintf("/api/v1/org/{org}/rules/%s/rules.tgz", toolName),
+		APIServer:                  apiServer,
+		LatestReleaseCacheDuration: 1 * time.Minute,
+	})
+}
+
+// Arrange for f to be called after the tool has run and its results
+// have been processed.
+func (o *ToolOpts) AddCleanup(f func()) {
+	o.cleanups = append(o.cleanups, f)
+}
+
+func (o *ToolOpts) cleanup() {
+	for i := len(o.cleanups) - 1; i >= 0; i-- {
+		o.cleanups[i]()
+	}
+	o.cleanups = nil
+}
+
+func (o *ToolOpts) RunTool() (results Results, err error) {
+	defer o.cleanup()
+	var span trace.Span
+	o.traceCtx, span = tracing.StartSpan(context.Background(), "tool", attribute.String("tool.name", o.Tool.Name()))
+	defer func() {
+		count := 0
+		for _, result := range results {
+			count += len(result.Findings)
+		}
+		span.SetAttributes(attribute.Int("tool.findings", count))
+		tracing.EndSpan(span, err)
+	}()
 	if err := o.Tool.Validate(); err != nil {
 		return nil, err
 	}
-	var (
-		results Results
-		err     error
-	)
 	if s, ok := o.Tool.(Single); ok {
+		o.startBatches()
 		var r *Result
 		r, err = s.Run()
+		if o.batches != nil {
+			o.batches.Stop()
+		}
 		if r != nil {
+			r.batches = o.batches
 			results = Results{r}
 		}
 	} else if c, ok := o.Tool.(Consolidated); ok {
 		results, err = c.RunAll()
 	}
 	for _, result := range results {
+		if result.processed {
+			continue
+		}
 		rerr := o.processResult(result)
 		if rerr != nil {
-			// processResult only fails if the upload failed, and if that
-			// fais then it's likely that nothing is going to work
+			// processResult only fails if the upload or the webhook
+			// failed, and if that fails then it's likely that nothing
+			// is going to work
 			return nil, rerr
 		}
 	}
 	return results, err
 }
 
+// With --upload-batch-size or --upload-batch-interval, start uploading
+// the findings that the tool reports while it runs.
+func (o *ToolOpts) startBatches() {
+	o.batches = nil
+	if !o.UploadEnabled || (o.UploadBatchSize <= 0 && o.UploadBatchInterval <= 0) {
+		return
+	}
+	w := newBatchWriter(o.GetAPIClient(), o.GetOrganization(), o.Tool.Name(), map[string]string{
+		"TOOL_NAME":   o.Tool.Name(),
+		"CLI_VERSION": version.Version,
+	})
+	w.Phase = "scan"
+	w.FlushCount = o.UploadBatchSize
+	w.FlushInterval = o.UploadBatchInterval
+	w.Options = []api.Option{xcp.WithCIEnv(o.RepoRoot)}
+	log.Infof("Uploading the findings of {primary:%s} while it runs as scan {info:%s}", o.Tool.Name(), w.ScanID)
+	w.Start()
+	o.batches = w
+}
+
+// Report findings as the tool finds them, so that with --upload-batch-size
+// or --upload-batch-interval they're uploaded while the tool is still
+// running.  The findings must also be in the tool's result.
+func (o *ToolOpts) ReportFindings(findings ...*assessments.Finding) {
+	if o.batches != nil {
+		o.batches.Report(findings...)
+	}
+}
+
 func (o *ToolOpts) processResult(result *Result) error {
 	result.AddValue("TOOL_NAME", o.Tool.Name()).
 		AddValue("CLI_VERSION", version.Version).
 		AddValue("SOLUBLE_COMMAND_LINE", strings.Join(os.Args, " "))
+	o.applySeverityOverrides(result)
+	o.applyRuleLabels(result)
+	o.applyOwners(result)
+	o.removeOldFindings(result)
+	if !o.Tool.IsNonAssessment() {
+		result.AddValue("SCAN_SCORE", formatScore(o.GetConfig().getScoreModel().score(result.Findings)))
+	}
+	result.TruncateFindings(o.MaxFindings)
 	if result.Directory != "" {
+		_, span := tracing.StartSpan(o.traceCtx, "fingerprint", attribute.String("tool.name", o.Tool.Name()))
 		result.UpdateFileFingerprints()
+		span.End()
 		if o.RepoRoot != "" {
 			reldir, err := filepath.Rel(o.RepoRoot, result.Directory)
 			if err == nil && !strings.HasPrefix(reldir, "..") {
@@ -189,6 +458,10 @@ func (o *ToolOpts) processResult(result *Result) error {
 			}
 		}
 	}
+	if o.UploadEnabled || o.EmitMetadata != "" || o.SaveHTMLReport != "" {
+		// collect the git metadata now, while a --git-ref export still exists
+		result.getCIEnv()
+	}
 	if o.PrintFingerprints || o.SaveFingerprints != "" {
 		d, err := json.Marshal(result.FileFingerprints)
 		util.Must(err)
@@ -233,12 +506,75 @@ func (o *ToolOpts) processResult(result *Result) error {
 		writeResultValues(f, result)
 		_ = f.Close()
 	}
+	if o.s3Client != nil {
+		prefix := path.Join(o.s3Prefix, o.getResultPrefix(result))
+		if err := result.WriteS3(o.s3Client, o.s3Bucket, prefix); err != nil {
+			return err
+		}
+	}
+	var webhookErr error
+	if o.WebhookURL != "" {
+		log.Infof("Posting results of {primary:%s} to {info:%s}", o.Tool.Name(), o.WebhookURL)
+		if err := result.PostWebhook(getWebhookClient(), o.WebhookURL, o.webhookHeaders); err != nil {
+			// the webhook is independent of the upload, so don't let it
+			// stop the results from being uploaded
+			log.Errorf("Could not post the results of {primary:%s} to {info:%s}: {danger:%s}", o.Tool.Name(), o.WebhookURL, err)
+			webhookErr = err
+		}
+	}
 	if o.UploadEnabled {
-		if err := result.Upload(o.GetAPIClient(), o.GetOrganization(), o.Tool.Name()); err != nil {
+		ctx, span := tracing.StartSpan(o.traceCtx, "upload", attribute.String("tool.name", o.Tool.Name()),
+			attribute.Int("tool.findings", len(result.Findings)))
+		options := []api.Option{tracing.WithTraceContext(ctx)}
+		if o.AttachLog != "" {
+			if d, err := readBuildLog(o.AttachLog, buildLogLimit); err != nil {
+				log.Warnf("Could not attach the build log {warning:%s}: {warning:%s}", o.AttachLog, err)
+			} else {
+				result.AddArtifact("build.log", d)
+			}
+		}
+		err := result.Upload(o.GetAPIClient(), o.GetOrganization(), o.Tool.Name(), o.UploadSizeLimit, options...)
+		tracing.EndSpan(span, err)
+		if err != nil {
 			return err
 		}
+		if o.SaveResults != "" {
+			if err := o.saveResults(result); err != nil {
+				return err
+			}
+		}
 	}
-	return nil
+	o.applySuppressions(result)
+	o.removeOtherAuthorsFindings(result)
+	result.processed = true
+	return webhookErr
+}
+
+// Returns the path that the files of result are saved under, e.g.
+// checkov/infra for a scan of the infra directory.
+func (o *ToolOpts) getResultPrefix(result *Result) string {
+	return path.Join(o.Tool.Name(), result.Values["ASSESSMENT_DIRECTORY"])
+}
+
+// The --save-results files written by this run
+var savedResults = struct {
+	sync.Mutex
+	paths map[string]bool
+}{paths: map[string]bool{}}
+
+// Save the results.json that was uploaded for result
+func (o *ToolOpts) saveResults(result *Result) error {
+	path := o.SaveResults
+	savedResults.Lock()
+	if savedResults.paths[path] {
+		ext := filepath.Ext(path)
+		name := strings.ReplaceAll(o.getResultPrefix(result), "/", "-")
+		path = fmt.Sprintf("%s-%s%s", strings.TrimSuffix(path, ext), name, ext)
+	}
+	savedResults.paths[path] = true
+	savedResults.Unlock()
+	log.Infof("Saving the uploaded results of {primary:%s} to {info:%s}", o.Tool.Name(), path)
+	return os.WriteFile(path, result.uploadedResultsJSON, 0600)
 }
 
 func writeResultValues(w io.Writer, result *Result) {
@@ -257,8 +593,7 @@ func (o *ToolOpts) GetCustomPoliciesDir() (string, error) {
 	if o.GetAPIClientConfig().APIToken == "" {
 		return "", nil
 	}
-	d, err := o.InstallAPIServerArtifact(fmt.Sprintf("%s-policies", o.Tool.Name()),
-		fmt.Sprintf("/api/v1/org/{org}/rules/%s/rules.tgz", o.Tool.Name()))
+	d, err := InstallCustomPolicies(o.GetAPIClient(), o.Tool.Name())
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,194 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trivy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/soluble-ai/soluble-cli/pkg/download"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"gopkg.in/yaml.v3"
+)
+
+// Scans the container images that kubernetes manifests reference.  This
+// is run by kubernetes-scan --scan-images.
+type KubernetesImages struct {
+	tools.DirectoryBasedToolOpts
+}
+
+// Where an image is first referenced
+type imageReference struct {
+	image string
+	file  string
+	line  int
+}
+
+var _ tools.Single = &KubernetesImages{}
+
+func (t *KubernetesImages) Name() string {
+	return "trivy-k8s-images"
+}
+
+func (t *KubernetesImages) Preflight() error {
+	return t.PreflightInstall(&download.Spec{
+		URL: "github.com/aquasecurity/trivy",
+	})
+}
+
+func (t *KubernetesImages) Requirements() []string {
+	return []string{tools.RequiresDownload}
+}
+
+func (t *KubernetesImages) Run() (*tools.Result, error) {
+	result := &tools.Result{
+		Directory: t.GetDirectory(),
+		Data:      jnode.NewObjectNode(),
+		Findings:  assessments.Findings{},
+	}
+	m := t.GetInventory()
+	refs := t.findImages(m.KubernetesManifestDirectories.Values())
+	if len(refs) == 0 {
+		log.Infof("No images found in kubernetes manifests in {info:%s}", t.GetDirectory())
+		return result, nil
+	}
+	d, err := t.InstallTool(&download.Spec{
+		URL: "github.com/aquasecurity/trivy",
+	})
+	if err != nil {
+		return nil, err
+	}
+	program := d.GetExePath("trivy")
+	images := result.Data.PutObject("images")
+	failed := 0
+	for _, ref := range refs {
+		log.Infof("Scanning {primary:%s} from {info:%s}", ref.image, ref.file)
+		n, err := scanImage(&t.ToolOpts, program, ref.image)
+		if err != nil {
+			log.Warnf("Could not scan {warning:%s} - {warning:%s}", ref.image, err)
+			failed++
+			continue
+		}
+		data := getData(d.Version, n)
+		images.Put(ref.image, data)
+		findings := getImageFindings(ref, data)
+		result.Findings = append(result.Findings, findings...)
+		t.ReportFindings(findings...)
+	}
+	if failed == len(refs) {
+		return nil, fmt.Errorf("none of the %d images could be scanned", failed)
+	}
+	result.AddValue("TRIVY_VERSION", d.Version)
+	return result, nil
+}
+
+func getImageFindings(ref *imageReference, data *jnode.Node) assessments.Findings {
+	findings := assessments.Findings{}
+	for _, v := range data.Path("Vulnerabilities").Elements() {
+		findings = append(findings, &assessments.Finding{
+			FilePath: ref.file,
+			Line:     ref.line,
+			Title:    v.Path("Title").AsText(),
+			Tool: map[string]string{
+				"image":            ref.image,
+				"vulnerability_id": v.Path("VulnerabilityID").AsText(),
+				"package":          v.Path("PkgName").AsText(),
+				"severity":         strings.ToLower(v.Path("Severity").AsText()),
+			},
+		})
+	}
+	return findings
+}
+
+// Returns the images referenced by the manifests in dirs, sorted by image
+func (t *KubernetesImages) findImages(dirs []string) []*imageReference {
+	refs := map[string]*imageReference{}
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(filepath.Join(t.GetDirectory(), dir))
+		if err != nil {
+			log.Warnf("Could not read {warning:%s}", err)
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || !(strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml")) {
+				continue
+			}
+			file := filepath.Join(dir, name)
+			if t.IsExcluded(file) {
+				continue
+			}
+			if err := findManifestImages(refs, t.GetDirectory(), file); err != nil {
+				log.Warnf("Could not read {warning:%s} - {warning:%s}", file, err)
+			}
+		}
+	}
+	result := make([]*imageReference, 0, len(refs))
+	for _, ref := range refs {
+		result = append(result, ref)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].image < result[j].image
+	})
+	return result
+}
+
+func findManifestImages(refs map[string]*imageReference, dir, file string) error {
+	f, err := os.Open(filepath.Join(dir, file))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	dec := yaml.NewDecoder(f)
+	for {
+		var node yaml.Node
+		err := dec.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		walkImages(&node, func(image string, line int) {
+			// the first reference wins
+			if refs[image] == nil {
+				refs[image] = &imageReference{image: image, file: file, line: line}
+			}
+		})
+	}
+}
+
+// Call f for each "image: value" in the document
+func walkImages(node *yaml.Node, f func(image string, line int)) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, v := node.Content[i], node.Content[i+1]
+			if k.Value == "image" && v.Kind == yaml.ScalarNode && v.Value != "" {
+				f(v.Value, v.Line)
+			}
+		}
+	}
+	for _, child := range node.Content {
+		walkImages(child, f)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trivy
+
+import (
+	"testing"
+
+	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindImages(t *testing.T) {
+	assert := assert.New(t)
+	tool := &KubernetesImages{
+		DirectoryBasedToolOpts: tools.DirectoryBasedToolOpts{
+			Directory: "testdata/k8s",
+			Exclude:   []string{"excluded/"},
+		},
+	}
+	assert.NoError(tool.Validate())
+	refs := tool.findImages([]string{".", "excluded"})
+	if assert.Len(refs, 3) {
+		assert.Equal(&imageReference{image: "busybox:1.35", file: "deployment.yaml", line: 10}, refs[0])
+		assert.Equal(&imageReference{image: "nginx:1.21", file: "deployment.yaml", line: 13}, refs[1])
+		assert.Equal(&imageReference{image: "python:3.10-slim", file: "deployment.yaml", line: 24}, refs[2])
+	}
+}
+
+func TestGetImageFindings(t *testing.T) {
+	assert := assert.New(t)
+	n := util.MustReadJSONFile("testdata/v0.20.2.json.gz")
+	data := getData("v0.20.2", n)
+	ref := &imageReference{image: "nginx:1.21", file: "deployment.yaml", line: 13}
+	findings := getImageFindings(ref, data)
+	if assert.Equal(data.Path("Vulnerabilities").Size(), len(findings)) && assert.NotEmpty(findings) {
+		f := findings[0]
+		assert.Equal("deployment.yaml", f.FilePath)
+		assert.Equal(13, f.Line)
+		assert.Equal("nginx:1.21", f.Tool["image"])
+		assert.NotEmpty(f.Tool["vulnerability_id"])
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/soluble-ai/soluble-cli/pkg/assessments"
 	"github.com/soluble-ai/soluble-cli/pkg/download"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/tracing"
 	"github.com/spf13/cobra"
 )
 
@@ -39,6 +40,16 @@ func (t *Tool) Name() string {
 	return "trivy"
 }
 
+func (t *Tool) Preflight() error {
+	return t.PreflightInstall(&download.Spec{
+		URL: "github.com/aquasecurity/trivy",
+	})
+}
+
+func (t *Tool) Requirements() []string {
+	return []string{tools.RequiresDownload}
+}
+
 func (t *Tool) Register(cmd *cobra.Command) {
 	t.ToolOpts.Register(cmd)
 	flags := cmd.Flags()
@@ -62,34 +73,14 @@ func (t *Tool) Run() (*tools.Result, error) {
 	if err != nil {
 		return nil, err
 	}
-	outfile, err := tools.TempFile("trivy*")
-	if err != nil {
-		return nil, err
-	}
-	defer os.Remove(outfile)
 	program := d.GetExePath("trivy")
 	if t.ClearCache {
-		err := t.runCommand(program, "image", "--clear-cache")
+		err := runCommand(&t.ToolOpts, program, "image", "--clear-cache")
 		if err != nil {
 			return nil, err
 		}
 	}
-
-	// Generate params for the scanner
-	args := []string{"image", "--format", "json", "--output", outfile}
-	// specify the image to scan at the end of params
-	args = append(args, t.Image)
-
-	err = t.runCommand(program, args...)
-	if err != nil {
-		return nil, err
-	}
-
-	dat, err := ioutil.ReadFile(outfile)
-	if err != nil {
-		return nil, err
-	}
-	n, err := jnode.FromJSON(dat)
+	n, err := scanImage(&t.ToolOpts, program, t.Image)
 	if err != nil {
 		return nil, err
 	}
@@ -120,12 +111,36 @@ func getData(ver string, n *jnode.Node) *jnode.Node {
 	return n.Get(0)
 }
 
-func (t *Tool) runCommand(program string, args ...string) error {
+// Scan image and return trivy's JSON output
+func scanImage(o *tools.ToolOpts, program, image string) (*jnode.Node, error) {
+	outfile, err := tools.TempFile("trivy*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(outfile)
+	// Generate params for the scanner
+	args := []string{"image", "--format", "json", "--output", outfile}
+	// specify the image to scan at the end of params
+	args = append(args, image)
+	if err := runCommand(o, program, args...); err != nil {
+		return nil, err
+	}
+	dat, err := ioutil.ReadFile(outfile)
+	if err != nil {
+		return nil, err
+	}
+	return jnode.FromJSON(dat)
+}
+
+func runCommand(o *tools.ToolOpts, program string, args ...string) error {
 	scan := exec.Command(program, args...)
-	t.LogCommand(scan)
+	defer tools.AcquireWorker()()
+	o.LogCommand(scan)
 	scan.Stderr = os.Stderr
 	scan.Stdout = os.Stdout
+	span := o.StartCommandSpan(scan)
 	err := scan.Run()
+	tracing.EndSpan(span, err)
 	if err != nil {
 		return err
 	}
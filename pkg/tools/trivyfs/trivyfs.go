@@ -23,6 +23,7 @@ import (
 	"github.com/soluble-ai/soluble-cli/pkg/assessments"
 	"github.com/soluble-ai/soluble-cli/pkg/download"
 	"github.com/soluble-ai/soluble-cli/pkg/tools"
+	"github.com/soluble-ai/soluble-cli/pkg/tracing"
 	"github.com/soluble-ai/soluble-cli/pkg/util"
 	"github.com/spf13/cobra"
 )
@@ -37,6 +38,16 @@ func (t *Tool) Name() string {
 	return "trivy-fs"
 }
 
+func (t *Tool) Preflight() error {
+	return t.PreflightInstall(&download.Spec{
+		URL: "github.com/aquasecurity/trivy",
+	})
+}
+
+func (t *Tool) Requirements() []string {
+	return []string{tools.RequiresDownload}
+}
+
 func (t *Tool) Register(c *cobra.Command) {
 	t.DirectoryBasedToolOpts.Register(c)
 }
@@ -68,8 +79,12 @@ func (t *Tool) Run() (*tools.Result, error) {
 	c := exec.Command(program, args...)
 	c.Stderr = os.Stderr
 	c.Stdout = os.Stderr
+	defer tools.AcquireWorker()()
 	t.LogCommand(c)
-	if err := c.Run(); err != nil {
+	span := t.StartCommandSpan(c)
+	err = c.Run()
+	tracing.EndSpan(span, err)
+	if err != nil {
 		return nil, err
 	}
 
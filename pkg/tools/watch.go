@@ -0,0 +1,176 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	ignore "github.com/sabhiram/go-gitignore"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+// How often --watch looks for changes
+const watchInterval = time.Second
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watches the files that a directory based tool would scan by polling
+// their modification times and sizes, which is cheap enough for a
+// source tree and works the same everywhere.  Files ignored by the
+// .gitignore at the root of the repository, or excluded from the scan,
+// are not watched.
+type watcher struct {
+	opts         *DirectoryBasedToolOpts
+	interval     time.Duration
+	gitignore    *ignore.GitIgnore
+	gitignoreDir string
+	files        map[string]fileState
+}
+
+func newWatcher(opts *DirectoryBasedToolOpts, interval time.Duration) *watcher {
+	w := &watcher{opts: opts, interval: interval}
+	root := opts.RepoRoot
+	if root == "" {
+		root = opts.GetDirectory()
+	}
+	if gi, err := ignore.CompileIgnoreFile(filepath.Join(root, ".gitignore")); err == nil {
+		w.gitignore = gi
+		w.gitignoreDir = root
+	}
+	w.files = w.snapshot()
+	return w
+}
+
+func (w *watcher) isIgnored(path string, isDir bool) bool {
+	if isDir && filepath.Base(path) == ".git" {
+		return true
+	}
+	if w.gitignore != nil {
+		rel := filepath.ToSlash(MustRel(w.gitignoreDir, path))
+		if isDir {
+			rel += "/"
+		}
+		if w.gitignore.MatchesPath(rel) {
+			return true
+		}
+	}
+	return w.opts.IsExcluded(path)
+}
+
+func (w *watcher) snapshot() map[string]fileState {
+	files := map[string]fileState{}
+	dir := w.opts.GetDirectory()
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		// files may be removed while we're looking
+		if err != nil || path == dir {
+			return nil
+		}
+		if w.isIgnored(path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if fi, err := d.Info(); err == nil {
+			files[MustRel(dir, path)] = fileState{modTime: fi.ModTime(), size: fi.Size()}
+		}
+		return nil
+	})
+	return files
+}
+
+// Wait for files to change, and then for them to stop changing for an
+// interval so that e.g. saving several files from an editor causes only
+// one rescan.  Returns the files that were added, changed, or removed, or
+// nil if stop is closed first.
+func (w *watcher) wait(stop <-chan struct{}) []string {
+	last := w.files
+	pending := false
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(w.interval):
+		}
+		files := w.snapshot()
+		if len(getChangedFiles(last, files)) > 0 {
+			pending = true
+		} else if pending {
+			pending = false
+			if changed := getChangedFiles(w.files, files); len(changed) > 0 {
+				w.files = files
+				return changed
+			}
+		}
+		last = files
+	}
+}
+
+func getChangedFiles(before, after map[string]fileState) []string {
+	var changed []string
+	for name, s := range after {
+		if b, ok := before[name]; !ok || b != s {
+			changed = append(changed, name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Run the tool, and then run it again whenever the files it scans change.
+// Each run reuses the setup of the first, e.g. the installed tool and its
+// docker image, and the fingerprints of files that haven't changed.
+func runWatch(tool Interface) error {
+	opts := tool.GetDirectoryBasedToolOptions()
+	if err := tool.Validate(); err != nil {
+		return err
+	}
+	w := newWatcher(opts, watchInterval)
+	for {
+		results, toolErr := opts.RunTool()
+		if err := printAllResults(tool, results, toolErr); err != nil {
+			return err
+		}
+		if toolErr != nil {
+			log.Errorf("{primary:%s} failed: {danger:%s}", tool.Name(), toolErr)
+		}
+		log.Infof("Watching {info:%s} for changes, press Ctrl-C to stop", opts.GetDirectory())
+		changed := w.wait(nil)
+		log.Infof("{info:%s} changed, running {primary:%s} again", summarizeFiles(changed), tool.Name())
+	}
+}
+
+func summarizeFiles(files []string) string {
+	const max = 3
+	if len(files) <= max {
+		return strings.Join(files, ", ")
+	}
+	return strings.Join(files[:max], ", ") + ", ..."
+}
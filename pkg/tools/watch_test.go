@@ -0,0 +1,73 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatcher(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(os.MkdirAll(filepath.Dir(path), 0700))
+		assert.NoError(os.WriteFile(path, []byte(content), 0600))
+	}
+	write(".git/config", "[core]\n")
+	write(".gitignore", "build/\n*.log\n")
+	write("main.tf", "resource {}\n")
+	write("modules/vpc/vpc.tf", "module {}\n")
+	write("build/out.tf", "resource {}\n")
+	write("debug.log", "hello\n")
+	write("skip.tf", "resource {}\n")
+	o := &DirectoryBasedToolOpts{Directory: dir, Exclude: []string{"skip.tf"}}
+	o.Watch = true
+	assert.NoError(o.Validate())
+	assert.Equal(dir, o.RepoRoot)
+	w := newWatcher(o, 10*time.Millisecond)
+	var names []string
+	for name := range w.files {
+		names = append(names, filepath.ToSlash(name))
+	}
+	assert.ElementsMatch([]string{".gitignore", "main.tf", "modules/vpc/vpc.tf"}, names)
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		write("debug.log", "ignored\n")
+		write("main.tf", "resource { changed }\n")
+		write("modules/vpc/subnet.tf", "resource {}\n")
+	}()
+	assert.Equal([]string{"main.tf", filepath.Join("modules", "vpc", "subnet.tf")}, w.wait(nil))
+	stop := make(chan struct{})
+	close(stop)
+	assert.Nil(w.wait(stop))
+}
+
+func TestWatchValidate(t *testing.T) {
+	o := &DirectoryBasedToolOpts{GitRef: "main"}
+	o.Watch = true
+	assert.Error(t, o.Validate())
+}
+
+func TestSummarizeFiles(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("a.tf, b.tf", summarizeFiles([]string{"a.tf", "b.tf"}))
+	assert.Equal("a.tf, b.tf, c.tf, ...", summarizeFiles([]string{"a.tf", "b.tf", "c.tf", "d.tf"}))
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/soluble-ai/soluble-cli/pkg/download"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+const webhookAttempts = 3
+
+var (
+	webhookRetryWait = 2 * time.Second
+	webhookTimeout   = 30 * time.Second
+)
+
+type webhookPayload struct {
+	Tool      string          `json:"tool"`
+	Directory string          `json:"directory,omitempty"`
+	Summary   map[string]int  `json:"summary"`
+	Findings  json.RawMessage `json:"findings"`
+}
+
+// A non-retryable webhook error e.g. a 400
+type webhookStatusError struct {
+	url        string
+	statusCode int
+	body       string
+}
+
+func (e *webhookStatusError) Error() string {
+	return fmt.Sprintf("webhook %s returned %d: %s", e.url, e.statusCode, e.body)
+}
+
+// POST the findings, along with a summary of their severities, to a
+// webhook.  The findings are serialized the same way as for Upload.
+// Server errors are retried with backoff.
+func (r *Result) PostWebhook(client *http.Client, url string, headers map[string]string) error {
+	body, err := r.getWebhookPayload()
+	if err != nil {
+		return err
+	}
+	wait := webhookRetryWait
+	for attempt := 1; ; attempt++ {
+		err = postWebhookOnce(client, url, headers, body)
+		var se *webhookStatusError
+		if err == nil || errors.As(err, &se) || attempt == webhookAttempts {
+			return err
+		}
+		log.Warnf("Webhook {info:%s} failed: {warning:%s}, retrying", url, err)
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// Returns the client for webhook requests, which has the same TLS
+// configuration as the download client but gives up on a request after
+// webhookTimeout.
+func getWebhookClient() *http.Client {
+	client := *download.GetHTTPClient()
+	client.Timeout = webhookTimeout
+	return &client
+}
+
+func (r *Result) getWebhookPayload() ([]byte, error) {
+	findings := []byte("[]")
+	if r.Findings != nil {
+		if rf := r.attachFindings(); rf != nil {
+			var err error
+			if findings, err = io.ReadAll(rf); err != nil {
+				return nil, err
+			}
+		}
+	}
+	counts, total := Results{r}.getSeverityCounts()
+	summary := map[string]int{"total": total}
+	for _, name := range severityCountNames {
+		summary[name] = counts[name]
+	}
+	return json.Marshal(&webhookPayload{
+		Tool:      r.Values["TOOL_NAME"],
+		Directory: r.Values["ASSESSMENT_DIRECTORY"],
+		Summary:   summary,
+		Findings:  findings,
+	})
+}
+
+func postWebhookOnce(client *http.Client, url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		// retryable
+		return fmt.Errorf("webhook %s returned %d: %s", url, resp.StatusCode, msg)
+	}
+	return &webhookStatusError{url: url, statusCode: resp.StatusCode, body: string(msg)}
+}
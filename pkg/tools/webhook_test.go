@@ -0,0 +1,132 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/soluble-ai/go-jnode"
+	"github.com/soluble-ai/soluble-cli/pkg/assessments"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostWebhook(t *testing.T) {
+	assert := assert.New(t)
+	defer func(w time.Duration) { webhookRetryWait = w }(webhookRetryWait)
+	webhookRetryWait = time.Millisecond
+	requests := 0
+	var payload webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("X-Token") != "xyzzy" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("denied"))
+			return
+		}
+		assert.Equal("application/json", r.Header.Get("Content-Type"))
+		d, _ := io.ReadAll(r.Body)
+		assert.NoError(json.Unmarshal(d, &payload))
+	}))
+	defer server.Close()
+	result := &Result{
+		Findings: assessments.Findings{
+			{FilePath: "b.tf", Severity: "high"},
+			{FilePath: "a.tf", Severity: "low"},
+			{FilePath: "c.tf", Severity: "low", Pass: true},
+		},
+	}
+	result.AddValue("TOOL_NAME", "checkov")
+	assert.NoError(result.PostWebhook(server.Client(), server.URL, map[string]string{"X-Token": "xyzzy"}))
+	assert.Equal(2, requests)
+	assert.Equal("checkov", payload.Tool)
+	assert.Equal(map[string]int{"critical": 0, "high": 1, "medium": 0, "low": 1, "total": 2}, payload.Summary)
+	var findings assessments.Findings
+	assert.NoError(json.Unmarshal(payload.Findings, &findings))
+	if assert.Len(findings, 3) {
+		assert.Equal("a.tf", findings[0].FilePath)
+	}
+	// client errors aren't retried
+	requests = 1
+	err := result.PostWebhook(server.Client(), server.URL, nil)
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "403: denied")
+	}
+	assert.Equal(2, requests)
+}
+
+func TestValidateWebhook(t *testing.T) {
+	assert := assert.New(t)
+	o := &ToolOpts{WebhookURL: "ftp://example.com"}
+	assert.Error(o.validateWebhook())
+	o = &ToolOpts{WebhookURL: "https://example.com/hook", WebhookHeaders: []string{"bogus"}}
+	assert.Error(o.validateWebhook())
+	o = &ToolOpts{WebhookURL: "https://example.com/hook", WebhookHeaders: []string{"Authorization: Bearer a:b"}}
+	assert.NoError(o.validateWebhook())
+	assert.Equal(map[string]string{"Authorization": "Bearer a:b"}, o.webhookHeaders)
+}
+
+func TestWebhookFailure(t *testing.T) {
+	assert := assert.New(t)
+	defer func(d time.Duration) { webhookTimeout = d }(webhookTimeout)
+	webhookTimeout = 50 * time.Millisecond
+	defer func(w time.Duration) { webhookRetryWait = w }(webhookRetryWait)
+	webhookRetryWait = time.Millisecond
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+	result := &Result{}
+	err := result.PostWebhook(getWebhookClient(), server.URL+"/slow", nil)
+	var se *webhookStatusError
+	if assert.Error(err) {
+		assert.False(errors.As(err, &se))
+	}
+	tool := &testTool{}
+	tool.Tool = tool
+	tool.APIServer = "https://api.example.com"
+	tool.APIToken = "xxx"
+	tool.Organization = "9999"
+	tool.UploadEnabled = true
+	tool.WebhookURL = server.URL
+	httpmock.ActivateNonDefault(tool.GetAPIClient().GetClient().GetClient())
+	defer httpmock.DeactivateAndReset()
+	uploads := 0
+	httpmock.RegisterResponder("POST", "https://api.example.com/api/v1/xcp/test/data",
+		func(h *http.Request) (*http.Response, error) {
+			uploads++
+			return httpmock.NewJsonResponse(http.StatusOK, jnode.NewObjectNode())
+		})
+	result = &Result{Data: jnode.NewObjectNode()}
+	err = tool.processResult(result)
+	if assert.Error(err) {
+		assert.True(errors.As(err, &se))
+	}
+	// the results are still uploaded
+	assert.Equal(1, uploads)
+}
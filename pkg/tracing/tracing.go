@@ -0,0 +1,126 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing records OpenTelemetry spans of a CLI run so that it can
+// be correlated with the processing of its uploads.  Tracing is only
+// enabled when an OTLP endpoint is configured with the standard
+// OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT
+// environment variables.  Otherwise the no-op tracer is used.
+package tracing
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/soluble-ai/soluble-cli/pkg/api"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/version"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/soluble-ai/soluble-cli"
+
+var (
+	provider *sdktrace.TracerProvider
+	rootCtx  = context.Background()
+	rootSpan trace.Span
+)
+
+// Returns true if an OTLP endpoint is configured
+func Enabled() bool {
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" ||
+		os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Start tracing if it's enabled, with a root span for the command.
+func Start(command string) {
+	if !Enabled() || provider != nil {
+		return
+	}
+	exporter, err := otlptracehttp.New(context.Background())
+	if err != nil {
+		log.Warnf("Could not create the trace exporter: {warning:%s}", err)
+		return
+	}
+	res, _ := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceNameKey.String("soluble-cli"),
+		semconv.ServiceVersionKey.String(version.Version),
+	))
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	rootCtx, rootSpan = otel.Tracer(tracerName).Start(context.Background(), command)
+	log.Debugf("Tracing to OTLP trace id {info:%s}", rootSpan.SpanContext().TraceID())
+}
+
+// End the root span and flush any pending spans.
+func Shutdown() {
+	if provider == nil {
+		return
+	}
+	rootSpan.End()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := provider.Shutdown(ctx); err != nil {
+		log.Warnf("Could not send traces: {warning:%s}", err)
+	}
+	provider = nil
+	rootCtx = context.Background()
+}
+
+// Start a span as a child of ctx, or of the root span if ctx doesn't have
+// a span (e.g. it's context.Background() or nil.)  When tracing isn't
+// enabled the span is a no-op.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		ctx = trace.ContextWithSpan(ctx, trace.SpanFromContext(rootCtx))
+	}
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End span, recording err if it's not nil.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Returns an option that propagates the trace of ctx in the headers of
+// an api request so that the server can join the trace.
+func WithTraceContext(ctx context.Context) api.Option {
+	return func(req *resty.Request) {
+		if ctx == nil {
+			ctx = rootCtx
+		}
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestDisabled(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	assert.False(Enabled())
+	Start("soluble test")
+	defer Shutdown()
+	ctx, span := StartSpan(context.Background(), "test")
+	assert.False(span.IsRecording())
+	req := resty.New().R()
+	WithTraceContext(ctx)(req)
+	assert.Empty(req.Header.Get("traceparent"))
+}
+
+func TestWithTraceContext(t *testing.T) {
+	assert := assert.New(t)
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	}()
+	ctx, span := StartSpan(context.Background(), "upload", attribute.Int("tool.findings", 3))
+	req := resty.New().R()
+	WithTraceContext(ctx)(req)
+	EndSpan(span, nil)
+	assert.Contains(req.Header.Get("traceparent"), span.SpanContext().TraceID().String())
+	if spans := recorder.Ended(); assert.Len(spans, 1) {
+		assert.Equal("upload", spans[0].Name())
+		assert.Contains(spans[0].Attributes(), attribute.Int("tool.findings", 3))
+	}
+}
+
+func TestStartSpanParent(t *testing.T) {
+	assert := assert.New(t)
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	var root trace.Span
+	rootCtx, root = otel.Tracer(tracerName).Start(context.Background(), "soluble test")
+	defer func() { rootCtx = context.Background() }()
+	ctx, tool := StartSpan(context.Background(), "tool")
+	_, exec := StartSpan(ctx, "exec")
+	exec.End()
+	tool.End()
+	root.End()
+	if spans := recorder.Ended(); assert.Len(spans, 3) {
+		assert.Equal(tool.SpanContext().SpanID(), spans[0].Parent().SpanID())
+		assert.Equal(root.SpanContext().SpanID(), spans[1].Parent().SpanID())
+	}
+}
@@ -1,12 +1,68 @@
 package util
 
-import "os"
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// The directory to create temporary files and directories in, set by
+// --temp-dir.  If empty, $TMPDIR (or the system default) is used.
+var TempDir string
+
+var (
+	scratchDirMu sync.Mutex
+	scratchDir   string
+)
+
+// Returns the directory this process creates its temporary files in,
+// creating a unique subdirectory of TempDir the first time it's called.
+func getScratchDir() (string, error) {
+	scratchDirMu.Lock()
+	defer scratchDirMu.Unlock()
+	if scratchDir == "" {
+		parent := TempDir
+		if parent == "" {
+			parent = os.TempDir()
+		}
+		dir, err := os.MkdirTemp(parent, "soluble*")
+		if err != nil {
+			return "", fmt.Errorf("cannot create a temporary directory in %s (use --temp-dir or TMPDIR to choose a writable directory): %w",
+				parent, err)
+		}
+		scratchDir = dir
+	}
+	return scratchDir, nil
+}
 
 func TempFile(pattern string) (string, error) {
-	f, err := os.CreateTemp("", pattern)
+	dir, err := getScratchDir()
+	if err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp(dir, pattern)
 	if err != nil {
 		return "", err
 	}
 	defer f.Close()
 	return f.Name(), nil
 }
+
+func MkdirTemp(pattern string) (string, error) {
+	dir, err := getScratchDir()
+	if err != nil {
+		return "", err
+	}
+	return os.MkdirTemp(dir, pattern)
+}
+
+// Remove all the temporary files and directories that were created
+// by TempFile or MkdirTemp.
+func RemoveTempFiles() {
+	scratchDirMu.Lock()
+	defer scratchDirMu.Unlock()
+	if scratchDir != "" {
+		_ = os.RemoveAll(scratchDir)
+		scratchDir = ""
+	}
+}
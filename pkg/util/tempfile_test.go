@@ -2,6 +2,7 @@ package util
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -15,3 +16,23 @@ func TestTempFile(t *testing.T) {
 	}
 	assert.NoError(os.Remove(path))
 }
+
+func TestTempDir(t *testing.T) {
+	assert := assert.New(t)
+	RemoveTempFiles()
+	TempDir = t.TempDir()
+	defer func() { TempDir = "" }()
+	defer RemoveTempFiles()
+	dir, err := MkdirTemp("testing")
+	assert.NoError(err)
+	path, err := TempFile("testing")
+	assert.NoError(err)
+	scratch := filepath.Dir(dir)
+	assert.Equal(TempDir, filepath.Dir(scratch))
+	assert.Equal(scratch, filepath.Dir(path))
+	RemoveTempFiles()
+	assert.NoDirExists(scratch)
+	TempDir = filepath.Join(TempDir, "no-such-dir")
+	_, err = TempFile("testing")
+	assert.ErrorContains(err, "--temp-dir")
+}
@@ -0,0 +1,115 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xcp
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// When and by whom each line of a file was last changed according to
+// git blame, indexed by line number starting from 1.  Lines that haven't
+// been committed yet are zero.
+type Blame []BlameLine
+
+type BlameLine struct {
+	Time        time.Time
+	AuthorName  string
+	AuthorEmail string
+}
+
+var (
+	blamesMu sync.Mutex
+	blames   = map[string]Blame{}
+)
+
+const notCommittedSHA = "0000000000000000000000000000000000000000"
+
+// Returns when and by whom each line of file was last changed.  The file is relative
+// to repoRoot, and its blame is computed once and cached.  Returns an
+// error if git can't blame the file, e.g. because it's untracked.
+func GetBlame(repoRoot, file string) (Blame, error) {
+	key := filepath.Join(repoRoot, file)
+	blamesMu.Lock()
+	defer blamesMu.Unlock()
+	if b, ok := blames[key]; ok {
+		return b, nil
+	}
+	out, err := runGit(repoRoot, nil, "blame", "--line-porcelain", "--", filepath.ToSlash(file))
+	if err != nil {
+		return nil, err
+	}
+	b := parseBlame(out)
+	blames[key] = b
+	return b, nil
+}
+
+// Returns who last changed line (starting at 1) and when, or a zero
+// BlameLine if it's new.
+func (b Blame) Get(line int) BlameLine {
+	if line < 1 || line >= len(b) {
+		return BlameLine{}
+	}
+	return b[line]
+}
+
+func parseBlame(out string) Blame {
+	b := Blame{BlameLine{}}
+	var (
+		line      int
+		committed bool
+	)
+	for _, s := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(s, "\t"):
+			// the content of the line ends each entry
+			line = 0
+		case line == 0:
+			// "<sha> <original-line> <final-line> [<count>]" starts an entry
+			fields := strings.Fields(s)
+			if len(fields) < 3 {
+				continue
+			}
+			line, _ = strconv.Atoi(fields[2])
+			committed = fields[0] != notCommittedSHA
+			for len(b) <= line {
+				b = append(b, BlameLine{})
+			}
+		case !committed:
+		case strings.HasPrefix(s, "author-time "):
+			if t, err := strconv.ParseInt(strings.TrimPrefix(s, "author-time "), 10, 64); err == nil {
+				b[line].Time = time.Unix(t, 0)
+			}
+		case strings.HasPrefix(s, "author-mail "):
+			b[line].AuthorEmail = strings.Trim(strings.TrimPrefix(s, "author-mail "), "<>")
+		case strings.HasPrefix(s, "author "):
+			b[line].AuthorName = strings.TrimPrefix(s, "author ")
+		}
+	}
+	return b
+}
+
+// Returns the user.name and user.email of the git user of the repository,
+// either of which may be "" if it isn't configured.
+func GetGitUser(repoRoot string) (name, email string) {
+	get := func(key string) string {
+		out, _ := runGit(repoRoot, nil, "config", key)
+		return strings.TrimSpace(out)
+	}
+	return get("user.name"), get("user.email")
+}
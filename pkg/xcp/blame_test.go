@@ -0,0 +1,58 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xcp
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBlame(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	assert := assert.New(t)
+	repo := t.TempDir()
+	git(t, repo, "init", "-q")
+	assert.NoError(os.MkdirAll(filepath.Join(repo, "infra"), 0755))
+	file := filepath.Join("infra", "main.tf")
+	assert.NoError(os.WriteFile(filepath.Join(repo, file), []byte("one\ntwo\n"), 0600))
+	git(t, repo, "add", file)
+	t.Setenv("GIT_AUTHOR_DATE", "2020-01-01T00:00:00Z")
+	git(t, repo, "commit", "-q", "-m", "one")
+	assert.NoError(os.WriteFile(filepath.Join(repo, file), []byte("one\nTWO\nthree\n"), 0600))
+	git(t, repo, "add", file)
+	t.Setenv("GIT_AUTHOR_DATE", "2022-06-01T00:00:00Z")
+	git(t, repo, "commit", "-q", "-m", "two")
+	assert.NoError(os.WriteFile(filepath.Join(repo, file), []byte("one\nTWO\nthree\nfour\n"), 0600))
+	b, err := GetBlame(repo, file)
+	assert.NoError(err)
+	assert.Equal(2020, b.Get(1).Time.UTC().Year())
+	assert.Equal(2022, b.Get(2).Time.UTC().Year())
+	assert.Equal(2022, b.Get(3).Time.UTC().Year())
+	assert.Equal(BlameLine{Time: b.Get(1).Time, AuthorName: "test", AuthorEmail: "test@example.com"}, b.Get(1))
+	// uncommitted lines and lines out of range are new
+	assert.Equal(BlameLine{}, b.Get(4))
+	assert.Equal(BlameLine{}, b.Get(0))
+	assert.Equal(BlameLine{}, b.Get(100))
+	// untracked
+	assert.NoError(os.WriteFile(filepath.Join(repo, "new.tf"), []byte("new\n"), 0600))
+	_, err = GetBlame(repo, "new.tf")
+	assert.Error(err)
+}
@@ -0,0 +1,163 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+)
+
+// Shallow clone the repository at repoURL to a new temporary directory,
+// checking out ref (or the default branch if ref is empty.)  Like
+// ExportGitRef, while the clone exists GetCIEnv reports ref as the branch.
+//
+// Credentials can be included in the URL, or for github.com the
+// GITHUB_TOKEN environment variable is used.  Otherwise git's own
+// credential helpers apply.
+func CloneGitRepo(repoURL, ref string) (*GitExport, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git is required to scan a git repository: %w", err)
+	}
+	dir, err := util.MkdirTemp("soluble-git-clone*")
+	if err != nil {
+		return nil, err
+	}
+	e := &GitExport{
+		Dir:      dir,
+		Ref:      ref,
+		RepoRoot: dir,
+	}
+	if err := e.clone(repoURL); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	gitExportsMu.Lock()
+	gitExports[e.Dir] = e
+	gitExportsMu.Unlock()
+	return e, nil
+}
+
+func (e *GitExport) clone(repoURL string) error {
+	env := getGitAuthEnv(repoURL)
+	if e.Ref == "" {
+		e.Ref = getDefaultBranch(context.Background(), repoURL, env)
+	}
+	fetchRef := e.Ref
+	if fetchRef == "" {
+		fetchRef = "HEAD"
+	}
+	log.Infof("Cloning {info:%s} at {info:%s} to {info:%s}", normalizeGitRemote(repoURL), fetchRef, e.Dir)
+	for _, argv := range [][]string{
+		{"init", "--quiet"},
+		{"remote", "add", "origin", repoURL},
+		{"fetch", "--quiet", "--depth", "1", "origin", fetchRef},
+		{"checkout", "--quiet", "FETCH_HEAD"},
+	} {
+		if _, err := runGit(e.Dir, env, argv...); err != nil {
+			return fmt.Errorf("could not clone %s: %s", normalizeGitRemote(repoURL), err)
+		}
+	}
+	commit, err := runGit(e.Dir, env, "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	e.Commit = commit
+	if e.Ref != "" {
+		// the ref could also be a tag or a commit
+		branch := strings.TrimPrefix(e.Ref, "refs/heads/")
+		if out, err := runGit(e.Dir, env, "ls-remote", "--heads", "origin", "refs/heads/"+branch); err == nil && out != "" {
+			e.Branch = branch
+		}
+	}
+	return nil
+}
+
+// Returns the name of the default branch of the remote repository,
+// or "" if it can't be determined.
+func getDefaultBranch(ctx context.Context, repoURL string, env []string) string {
+	out, err := runGitContext(ctx, "", env, "ls-remote", "--symref", repoURL, "HEAD")
+	if err != nil {
+		return ""
+	}
+	// ref: refs/heads/main	HEAD
+	for _, line := range strings.Split(out, "\n") {
+		if fields := strings.Fields(line); len(fields) == 3 && fields[0] == "ref:" {
+			return strings.TrimPrefix(fields[1], "refs/heads/")
+		}
+	}
+	return ""
+}
+
+// Pass GITHUB_TOKEN for github.com https URLs without credentials.  The
+// header is passed in the environment so it doesn't show up in ps.
+func getGitAuthEnv(repoURL string) []string {
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	token := os.Getenv("GITHUB_TOKEN")
+	u, err := url.Parse(repoURL)
+	if token == "" || err != nil || u.Scheme != "https" || u.User != nil || u.Host != "github.com" {
+		return env
+	}
+	auth := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + token))
+	return append(env,
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=http.https://github.com/.extraheader",
+		"GIT_CONFIG_VALUE_0=Authorization: Basic "+auth,
+	)
+}
+
+func runGit(dir string, env []string, args ...string) (string, error) {
+	return runGitContext(context.Background(), dir, env, args...)
+}
+
+func runGitContext(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	// #nosec G204
+	c := exec.CommandContext(ctx, "git", args...)
+	c.Dir = dir
+	c.Env = env
+	stderr := &bytes.Buffer{}
+	c.Stderr = stderr
+	out, err := c.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s failed - %s", args[0], maskURLCredentials(msg))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// git sometimes echos the URL in error messages, so remove any
+// credentials from them.
+func maskURLCredentials(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		trimmed := strings.Trim(w, "'\"")
+		if u, err := url.Parse(trimmed); err == nil && u.Scheme != "" && u.User != nil {
+			u.User = nil
+			words[i] = strings.Replace(w, trimmed, u.String(), 1)
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xcp
+
+import (
+	"strings"
+	"sync"
+)
+
+// Overrides the detected default branch, set by --default-branch
+var DefaultBranch string
+
+var (
+	defaultBranchesMu sync.Mutex
+	defaultBranches   = map[string]string{}
+)
+
+// Returns the default branch (e.g. main) of the git repository of dir,
+// or "" if it can't be determined.  The branch is looked up once per
+// directory, and only locally because this is part of the metadata of
+// every upload.
+func GetDefaultBranch(dir string) string {
+	if DefaultBranch != "" {
+		return DefaultBranch
+	}
+	defaultBranchesMu.Lock()
+	defer defaultBranchesMu.Unlock()
+	branch, ok := defaultBranches[dir]
+	if !ok {
+		if root, err := runGit(dir, nil, "rev-parse", "--show-toplevel"); err == nil {
+			branch = findDefaultBranch(root)
+		}
+		defaultBranches[dir] = branch
+	}
+	return branch
+}
+
+func findDefaultBranch(dir string) string {
+	if ref, err := runGit(dir, nil, "symbolic-ref", "--quiet", "refs/remotes/origin/HEAD"); err == nil {
+		return strings.TrimPrefix(ref, "refs/remotes/origin/")
+	}
+	// origin/HEAD is set by git clone, but not when the repository is
+	// checked out with init and fetch like many CI systems do
+	branches := []string{"main", "master"}
+	if branch, err := runGit(dir, nil, "config", "init.defaultBranch"); err == nil && strings.TrimSpace(branch) != "" {
+		branches = append([]string{strings.TrimSpace(branch)}, branches...)
+	}
+	for _, branch := range branches {
+		for _, ref := range []string{"refs/remotes/origin/" + branch, "refs/heads/" + branch} {
+			if _, err := runGit(dir, nil, "rev-parse", "--verify", "--quiet", ref); err == nil {
+				return branch
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xcp
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDefaultBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	assert := assert.New(t)
+	repo := t.TempDir()
+	git(t, repo, "init", "-q", "-b", "trunk")
+	assert.NoError(os.WriteFile(filepath.Join(repo, "main.tf"), []byte("one\n"), 0600))
+	git(t, repo, "add", "main.tf")
+	git(t, repo, "commit", "-q", "-m", "one")
+	git(t, repo, "checkout", "-q", "-b", "feature")
+	// no remote, and neither main nor master
+	assert.Equal("", GetDefaultBranch(repo))
+
+	// origin/HEAD is set by clone
+	cloned := filepath.Join(t.TempDir(), "cloned")
+	git(t, "", "clone", "-q", repo, cloned)
+	assert.Equal("feature", GetDefaultBranch(cloned))
+
+	// no origin/HEAD, and the remote isn't asked
+	fetched := t.TempDir()
+	git(t, fetched, "init", "-q", "-b", "master")
+	git(t, fetched, "remote", "add", "origin", "file://"+filepath.ToSlash(repo))
+	git(t, fetched, "fetch", "-q", "origin")
+	assert.Equal("", findDefaultBranch(fetched))
+	git(t, fetched, "checkout", "-q", "-b", "master", "origin/trunk")
+	assert.Equal("master", findDefaultBranch(fetched))
+	git(t, fetched, "config", "init.defaultBranch", "trunk")
+	assert.Equal("trunk", findDefaultBranch(fetched))
+
+	DefaultBranch = "develop"
+	defer func() { DefaultBranch = "" }()
+	assert.Equal("develop", GetDefaultBranch(fetched))
+}
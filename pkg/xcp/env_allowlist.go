@@ -0,0 +1,66 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xcp
+
+import (
+	"os"
+	"path"
+	"strings"
+)
+
+// If set, GetCIEnv only includes the environment variables whose names
+// match one of these names or glob patterns (e.g. GITHUB_*) instead of
+// the variables of known CI systems.  Names that look sensitive and
+// values that look like secrets are still dropped.  The allowlist can
+// also be given as a comma-separated list in $SOLUBLE_ENV_ALLOWLIST.
+var EnvAllowlist []string
+
+var ciEnvPrefixes = []string{
+	"GITHUB_", "CIRCLE_", "GITLAB_", "CI_", "BUILDKITE_", "ZODIAC_",
+}
+
+func getEnvAllowlist() []string {
+	allowlist := EnvAllowlist
+	for _, s := range strings.Split(os.Getenv("SOLUBLE_ENV_ALLOWLIST"), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			allowlist = append(allowlist, s)
+		}
+	}
+	return allowlist
+}
+
+// Returns true if the environment variable k should be included.  With
+// an allowlist only the variables it matches are included, otherwise
+// the variables of known CI systems are.
+func isCIEnvIncluded(allowlist []string, k string) bool {
+	if len(allowlist) > 0 {
+		for _, pattern := range allowlist {
+			if ok, _ := path.Match(strings.ToUpper(pattern), k); ok {
+				return true
+			}
+		}
+		return false
+	}
+	return getCIEnvPrefix(k) != ""
+}
+
+func getCIEnvPrefix(k string) string {
+	for _, prefix := range ciEnvPrefixes {
+		if strings.HasPrefix(k, prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xcp
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+)
+
+// If set, GetCIEnv appends a record of the environment variables that it
+// included and the ones that it redacted to this file.  Only the names of
+// the variables are recorded, never their values.  The file is never
+// uploaded.
+var EnvAuditLog string
+
+type envAudit struct {
+	Time      time.Time    `json:"time"`
+	Directory string       `json:"directory"`
+	Included  []string     `json:"included"`
+	Omitted   []omittedEnv `json:"omitted"`
+}
+
+type omittedEnv struct {
+	Name string `json:"name"`
+	// "substring" for substringOmitEnv, "explicit" for explicitOmitEnv,
+	// or "value" if the value looks like a secret
+	Rule    string `json:"rule"`
+	Pattern string `json:"pattern"`
+}
+
+// Returns why the environment variable k must not be recorded, or nil
+// if it may be.
+func getOmitRule(k string) *omittedEnv {
+	for _, s := range substringOmitEnv {
+		if strings.Contains(k, s) {
+			return &omittedEnv{Name: k, Rule: "substring", Pattern: s}
+		}
+	}
+	for _, s := range explicitOmitEnv {
+		if k == s {
+			return &omittedEnv{Name: k, Rule: "explicit", Pattern: s}
+		}
+	}
+	return nil
+}
+
+func writeEnvAudit(path, dir string, values map[string]string, omitted []omittedEnv) {
+	audit := &envAudit{
+		Time:      time.Now(),
+		Directory: dir,
+		Included:  make([]string, 0, len(values)),
+		Omitted:   omitted,
+	}
+	for k := range values {
+		audit.Included = append(audit.Included, k)
+	}
+	sort.Strings(audit.Included)
+	if audit.Omitted == nil {
+		audit.Omitted = []omittedEnv{}
+	}
+	sort.Slice(audit.Omitted, func(i, j int) bool {
+		return audit.Omitted[i].Name < audit.Omitted[j].Name
+	})
+	dat, err := json.Marshal(audit)
+	if err == nil {
+		var f *os.File
+		f, err = os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err == nil {
+			_, err = f.Write(append(dat, '\n'))
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}
+	}
+	if err != nil {
+		log.Warnf("Could not write environment audit log {warning:%s} - {warning:%s}", path, err)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xcp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvAuditLog(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("GITHUB_TOKEN", "xxx")
+	t.Setenv("BUILDKITE_COMMAND", "xxx")
+	t.Setenv("GITHUB_BRANCH", "yyy")
+	EnvAuditLog = filepath.Join(t.TempDir(), "audit.log")
+	defer func() { EnvAuditLog = "" }()
+	GetCIEnv(".")
+	GetCIEnv(".")
+	dat, err := os.ReadFile(EnvAuditLog)
+	assert.NoError(err)
+	assert.NotContains(string(dat), "xxx")
+	assert.NotContains(string(dat), "yyy")
+	lines := strings.Split(strings.TrimSpace(string(dat)), "\n")
+	if !assert.Len(lines, 2) {
+		return
+	}
+	var audit envAudit
+	assert.NoError(json.Unmarshal([]byte(lines[0]), &audit))
+	assert.Contains(audit.Included, "GITHUB_BRANCH")
+	assert.Contains(audit.Omitted, omittedEnv{Name: "GITHUB_TOKEN", Rule: "substring", Pattern: "TOKEN"})
+	assert.Contains(audit.Omitted, omittedEnv{Name: "BUILDKITE_COMMAND", Rule: "explicit", Pattern: "BUILDKITE_COMMAND"})
+}
@@ -0,0 +1,137 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xcp
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/soluble-ai/soluble-cli/pkg/archive"
+	"github.com/soluble-ai/soluble-cli/pkg/log"
+	"github.com/soluble-ai/soluble-cli/pkg/util"
+	"github.com/spf13/afero"
+)
+
+// A GitExport is the tree of a git ref exported to a temporary directory,
+// either with "git archive" or by cloning a remote repository.
+type GitExport struct {
+	// The directory the tree was exported to
+	Dir string
+	// The ref that was exported, as given
+	Ref string
+	// The commit the ref resolved to
+	Commit string
+	// The branch that was exported, or "" if the ref isn't a branch
+	// (e.g. it's a tag or a commit)
+	Branch string
+	// The root of the repository the tree was exported from
+	RepoRoot string
+}
+
+var (
+	gitExports   = map[string]*GitExport{}
+	gitExportsMu sync.Mutex
+)
+
+// Export the tree at ref from the repository at repoRoot to a new temporary
+// directory.  While the export exists, GetCIEnv reports the git metadata
+// of ref rather than HEAD for directories within it.
+func ExportGitRef(repoRoot, ref string) (*GitExport, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git is required to scan a git ref: %w", err)
+	}
+	// #nosec G204
+	revParse := exec.Command("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	revParse.Dir = repoRoot
+	out, err := revParse.Output()
+	if err != nil {
+		return nil, fmt.Errorf("unknown git ref %s", ref)
+	}
+	e := &GitExport{
+		Ref:      ref,
+		Commit:   strings.TrimSpace(string(out)),
+		Branch:   getLocalBranch(repoRoot, ref),
+		RepoRoot: repoRoot,
+	}
+	e.Dir, err = util.MkdirTemp("soluble-git-ref*")
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Exporting {info:%s} ({info:%s}) to {info:%s}", ref, e.Commit, e.Dir)
+	// #nosec G204
+	gitArchive := exec.Command("git", "archive", "--format=tar", e.Commit)
+	gitArchive.Dir = repoRoot
+	gitArchive.Stderr = os.Stderr
+	tar, err := gitArchive.StdoutPipe()
+	if err == nil {
+		err = gitArchive.Start()
+	}
+	if err == nil {
+		err = archive.UntarReader(tar, false, afero.NewBasePathFs(afero.NewOsFs(), e.Dir), nil)
+		if werr := gitArchive.Wait(); err == nil {
+			err = werr
+		}
+	}
+	if err == nil {
+		// make the export look like the root of a repository so that
+		// repo-relative paths are computed the same way
+		err = os.Mkdir(filepath.Join(e.Dir, ".git"), 0700)
+		if err == nil {
+			err = os.WriteFile(filepath.Join(e.Dir, ".git", "config"), nil, 0600)
+		}
+	}
+	if err != nil {
+		_ = os.RemoveAll(e.Dir)
+		return nil, fmt.Errorf("could not export %s: %w", ref, err)
+	}
+	gitExportsMu.Lock()
+	gitExports[e.Dir] = e
+	gitExportsMu.Unlock()
+	return e, nil
+}
+
+// Returns the name of the branch that ref names, or "" if ref isn't a
+// branch of the repository at repoRoot.
+func getLocalBranch(repoRoot, ref string) string {
+	branch := strings.TrimPrefix(ref, "refs/heads/")
+	if _, err := runGit(repoRoot, nil, "show-ref", "--verify", "--quiet", "refs/heads/"+branch); err != nil {
+		return ""
+	}
+	return branch
+}
+
+// Remove the exported tree
+func (e *GitExport) Remove() error {
+	gitExportsMu.Lock()
+	delete(gitExports, e.Dir)
+	gitExportsMu.Unlock()
+	return os.RemoveAll(e.Dir)
+}
+
+// Find the export that contains dir, if any
+func findGitExport(dir string) *GitExport {
+	gitExportsMu.Lock()
+	defer gitExportsMu.Unlock()
+	for exportDir, e := range gitExports {
+		if dir == exportDir || strings.HasPrefix(dir, exportDir+string(filepath.Separator)) {
+			return e
+		}
+	}
+	return nil
+}
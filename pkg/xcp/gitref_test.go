@@ -0,0 +1,82 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xcp
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func git(t *testing.T, dir string, args ...string) {
+	c := exec.Command("git", args...)
+	c.Dir = dir
+	c.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+	if out, err := c.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %s %s", args, err, out)
+	}
+}
+
+func TestExportGitRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	assert := assert.New(t)
+	repo := t.TempDir()
+	git(t, repo, "init", "-q")
+	assert.NoError(os.WriteFile(filepath.Join(repo, "main.tf"), []byte("one\n"), 0600))
+	git(t, repo, "add", "main.tf")
+	git(t, repo, "commit", "-q", "-m", "one")
+	git(t, repo, "tag", "first")
+	assert.NoError(os.WriteFile(filepath.Join(repo, "main.tf"), []byte("two\n"), 0600))
+	git(t, repo, "commit", "-q", "-a", "-m", "two")
+
+	_, err := ExportGitRef(repo, "no-such-ref")
+	assert.Error(err)
+
+	e, err := ExportGitRef(repo, "first")
+	if !assert.NoError(err) {
+		return
+	}
+	d, err := os.ReadFile(filepath.Join(e.Dir, "main.tf"))
+	assert.NoError(err)
+	assert.Equal("one\n", string(d))
+	env := GetCIEnv(e.Dir)
+	assert.Equal(e.Commit, env["SOLUBLE_METADATA_GIT_COMMIT"])
+	// a tag isn't a branch
+	assert.Empty(env["SOLUBLE_METADATA_GIT_BRANCH"])
+	assert.Equal("first", env["SOLUBLE_METADATA_GIT_DESCRIBE"])
+	assert.NoError(e.Remove())
+	assert.NoDirExists(e.Dir)
+	assert.Nil(findGitExport(e.Dir))
+
+	git(t, repo, "branch", "feature", "first")
+	for ref, branch := range map[string]string{
+		"feature":            "feature",
+		"refs/heads/feature": "feature",
+		e.Commit:             "",
+	} {
+		e, err := ExportGitRef(repo, ref)
+		if !assert.NoError(err) {
+			return
+		}
+		assert.Equal(branch, GetCIEnv(e.Dir)["SOLUBLE_METADATA_GIT_BRANCH"], ref)
+		assert.NoError(e.Remove())
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2021 Soluble Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xcp
+
+import (
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Patterns of values that are secrets no matter what the variable is
+// called.
+var secretValuePatterns = []struct {
+	name string
+	re   *regexp.Regexp
+}{
+	{"aws-access-key", regexp.MustCompile(`\b(A3T[A-Z0-9]|AKIA|ASIA|AGPA|AIDA|AROA|AIPA|ANPA|ANVA)[A-Z0-9]{16}\b`)},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.`)},
+	{"github-token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`)},
+	{"slack-token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"private-key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY`)},
+}
+
+var (
+	hexRe    = regexp.MustCompile(`^[0-9a-fA-F]{32,}$`)
+	base64Re = regexp.MustCompile(`^[A-Za-z0-9+/_-]{32,}={0,2}$`)
+)
+
+// Returns the name of the check that says the value of k looks like a
+// secret, or "" if it doesn't.
+func getSecretValueRule(k, v string) string {
+	for _, p := range secretValuePatterns {
+		if p.re.MatchString(v) {
+			return p.name
+		}
+	}
+	if u, err := url.Parse(v); err == nil && u.Scheme != "" && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			return "url-credentials"
+		}
+	}
+	// long runs of digits are ids, not keys
+	if hexRe.MatchString(v) && strings.ContainsAny(v, "abcdefABCDEF") && !isGitObjectID(k, v) {
+		return "hex"
+	}
+	if base64Re.MatchString(v) && hasMixedCharacters(v) && getEntropy(v) >= 4.5 {
+		return "high-entropy"
+	}
+	return ""
+}
+
+// Commit shas are long hex strings too, but they're only in variables
+// that say they're commits.
+func isGitObjectID(k, v string) bool {
+	if len(v) != 40 && len(v) != 64 {
+		return false
+	}
+	return strings.Contains(k, "SHA") || strings.Contains(k, "COMMIT")
+}
+
+func hasMixedCharacters(v string) bool {
+	var upper, lower, digit bool
+	for _, c := range v {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c >= 'a' && c <= 'z':
+			lower = true
+		case c >= '0' && c <= '9':
+			digit = true
+		}
+	}
+	return upper && lower && digit
+}
+
+// Returns the Shannon entropy of s in bits per character
+func getEntropy(s string) float64 {
+	counts := map[rune]int{}
+	n := 0
+	for _, c := range s {
+		counts[c]++
+		n++
+	}
+	var e float64
+	for _, count := range counts {
+		p := float64(count) / float64(n)
+		e -= p * math.Log2(p)
+	}
+	return e
+}
+
+// Returns env (in the form of os.Environ) without the variables that
+// would not be uploaded because their name or value looks sensitive.
+func RedactEnv(env []string) []string {
+	var redacted []string
+	for _, e := range env {
+		k, v := e, ""
+		if eq := strings.IndexByte(e, '='); eq >= 0 {
+			k, v = e[:eq], e[eq+1:]
+		}
+		if getOmitRule(strings.ToUpper(k)) != nil || getSecretValueRule(k, v) != "" {
+			continue
+		}
+		redacted = append(redacted, e)
+	}
+	return redacted
+}
+
+const redacted = "*****"
+
+var (
+	privateKeyRe     = regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?(-----END [A-Z ]*PRIVATE KEY-----|\z)`)
+	urlCredentialsRe = regexp.MustCompile(`(://[^:/@\s]+:)[^@/\s]+@`)
+	assignmentRe     = regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_.-]*)(["']?\s*[:=]\s*)("[^"\n]*"|'[^'\n]*'|(?:(?:Bearer|Basic|Token|token)\s+)?[^\s"',;]+)`)
+	tokenRe          = regexp.MustCompile(`[A-Za-z0-9+/_.-]{20,}={0,2}`)
+)
+
+// Returns text (e.g. a build log) with the values that look like secrets
+// replaced by *****.  A value is a secret if it's assigned to a name that
+// would not be uploaded as an environment variable, or if it looks like
+// a secret on its own.
+func RedactText(text string) string {
+	text = privateKeyRe.ReplaceAllString(text, redacted)
+	text = urlCredentialsRe.ReplaceAllString(text, "${1}"+redacted+"@")
+	text = assignmentRe.ReplaceAllStringFunc(text, func(s string) string {
+		m := assignmentRe.FindStringSubmatch(s)
+		if getOmitRule(strings.ToUpper(m[1])) == nil {
+			return s
+		}
+		if q := m[3][0]; q == '"' || q == '\'' {
+			return m[1] + m[2] + string(q) + redacted + string(q)
+		}
+		return m[1] + m[2] + redacted
+	})
+	return tokenRe.ReplaceAllStringFunc(text, func(s string) string {
+		rule := getSecretValueRule("", s)
+		// commit shas are all over build logs
+		if rule == "" || (rule == "hex" && isGitObjectID("SHA", s)) {
+			return s
+		}
+		return redacted
+	})
+}